@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dshills/mcp-pr/internal/config"
+	"github.com/dshills/mcp-pr/internal/history"
+	"github.com/dshills/mcp-pr/internal/logging"
+	"github.com/dshills/mcp-pr/internal/mcp"
+)
+
+// runHistory lists past reviews, or prints one review with --id
+func runHistory(args []string) int {
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	configOpts := config.BindFlags(fs)
+	repo := fs.String("repo", "", "only reviews of this repository")
+	branch := fs.String("branch", "", "only reviews made on this branch")
+	since := fs.String("since", "", "only reviews at or after this time: RFC 3339, YYYY-MM-DD, or an age such as 36h or 7d")
+	until := fs.String("until", "", "only reviews before this time")
+	limit := fs.Int("limit", history.DefaultLimit, "maximum number of reviews")
+	id := fs.String("id", "", "print the review with this ID")
+	format := fs.String("format", "text", "output format: text or json")
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitPass
+		}
+		return exitError
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "Error: --format must be text or json, got %q\n", *format)
+		return exitError
+	}
+
+	// Reading history needs no provider, so API keys are optional
+	configOpts.AllowMissingKeys = true
+	cfg, err := config.LoadWithOptions(*configOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		return exitError
+	}
+
+	level := cfg.LogLevel
+	if cfg.Sources["log_level"] == config.SourceDefault {
+		level = "warn"
+	}
+	logging.InitWriter(level, os.Stderr)
+
+	store := openHistory(context.Background(), cfg)
+	if store == nil {
+		fmt.Fprintln(os.Stderr, "Error: review history is disabled")
+		return exitError
+	}
+
+	if *id != "" {
+		rec, err := store.Get(*id)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitError
+		}
+		if *format == "json" {
+			err = writeJSON(os.Stdout, mcp.FormatHistoryRecord(rec))
+		} else {
+			err = printRecord(os.Stdout, rec)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitError
+		}
+		return exitPass
+	}
+
+	query := history.Query{Repository: *repo, Branch: *branch, Limit: *limit}
+	now := time.Now()
+	if *since != "" {
+		if query.Since, err = history.ParseTime(*since, now); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --since: %v\n", err)
+			return exitError
+		}
+	}
+	if *until != "" {
+		if query.Until, err = history.ParseTime(*until, now); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --until: %v\n", err)
+			return exitError
+		}
+	}
+
+	records, err := store.List(query)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitError
+	}
+
+	if *format == "json" {
+		summaries := make([]history.Record, len(records))
+		for i, rec := range records {
+			summaries[i] = rec.Summary()
+		}
+		err = writeJSON(os.Stdout, map[string]interface{}{
+			"reviews": summaries,
+			"count":   len(summaries),
+		})
+	} else {
+		err = printRecords(os.Stdout, records)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitError
+	}
+	return exitPass
+}
+
+// printRecords writes one line per review
+func printRecords(w io.Writer, records []history.Record) error {
+	var b strings.Builder
+	for _, rec := range records {
+		findings := 0
+		for _, n := range rec.Counts.BySeverity {
+			findings += n
+		}
+		fmt.Fprintf(&b, "%s  %s  %-4s  %3d finding(s)  %s", rec.ID, rec.Time.Local().Format("2006-01-02 15:04"), rec.Verdict, findings, rec.Request.SourceType)
+		if rec.Branch != "" {
+			fmt.Fprintf(&b, "  %s", rec.Branch)
+		}
+		if rec.CommitSHA != "" {
+			fmt.Fprintf(&b, "@%s", shortSHA(rec.CommitSHA))
+		}
+		if rec.Repository != "" {
+			fmt.Fprintf(&b, "  %s", rec.Repository)
+		}
+		b.WriteString("\n")
+	}
+	if len(records) == 0 {
+		b.WriteString("No reviews found\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// printRecord writes a stored review in the same layout as the review command
+func printRecord(w io.Writer, rec *history.Record) error {
+	header := fmt.Sprintf("Review %s at %s with %s", rec.ID, rec.Time.Local().Format(time.RFC3339), rec.Provider)
+	if rec.Repository != "" {
+		header += fmt.Sprintf(" of %s", rec.Repository)
+	}
+	if rec.Branch != "" {
+		header += fmt.Sprintf(" (%s", rec.Branch)
+		if rec.CommitSHA != "" {
+			header += "@" + shortSHA(rec.CommitSHA)
+		}
+		header += ")"
+	}
+	if _, err := fmt.Fprintln(w, header+"\n"); err != nil {
+		return err
+	}
+	if rec.Response == nil {
+		return nil
+	}
+	return printText(w, rec.Response)
+}
+
+// shortSHA abbreviates a commit SHA for display
+func shortSHA(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return sha
+}
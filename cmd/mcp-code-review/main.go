@@ -2,20 +2,41 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 
+	"github.com/dshills/mcp-pr/internal/analysis"
 	"github.com/dshills/mcp-pr/internal/config"
 	"github.com/dshills/mcp-pr/internal/credentials"
+	"github.com/dshills/mcp-pr/internal/enrich"
+	"github.com/dshills/mcp-pr/internal/gocontext"
+	"github.com/dshills/mcp-pr/internal/history"
 	"github.com/dshills/mcp-pr/internal/logging"
 	"github.com/dshills/mcp-pr/internal/mcp"
 	"github.com/dshills/mcp-pr/internal/providers"
 	"github.com/dshills/mcp-pr/internal/review"
+	"github.com/dshills/mcp-pr/internal/rules"
 )
 
 func main() {
-	// Load configuration
-	cfg, err := config.Load()
+	// Subcommands run once from the command line instead of serving MCP
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "review":
+			os.Exit(runReview(os.Args[2:]))
+		case "history":
+			os.Exit(runHistory(os.Args[2:]))
+		}
+	}
+
+	// Load configuration: flags > environment > config file > defaults
+	configOpts := config.BindFlags(flag.CommandLine)
+	flag.Parse()
+
+	cfg, err := config.LoadWithOptions(*configOpts)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
 		os.Exit(1)
@@ -27,18 +48,87 @@ func main() {
 	ctx := context.Background()
 
 	// Validate credentials before any logging
-	validator := credentials.NewValidator()
-	if err := validator.ValidateAll(cfg.AnthropicAPIKey, cfg.OpenAIAPIKey, cfg.GoogleAPIKey); err != nil {
-		logging.Error(ctx, "Invalid API credentials", "error", err)
+	if err := validateCredentials(ctx, cfg); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: Invalid API credentials:\n%v\n", err)
 		os.Exit(1)
 	}
 
 	logging.Info(ctx, "Starting MCP Code Review Server",
-		"version", "1.0.0",
+		"version", mcp.Version,
 		"default_provider", cfg.DefaultProvider,
+		"config_file", cfg.ConfigFile,
 	)
 
+	store := openHistory(ctx, cfg)
+
+	engine, err := newEngine(ctx, cfg, store)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Create MCP server
+	server, err := mcp.NewServer(engine, cfg, store)
+	if err != nil {
+		logging.Error(ctx, "Failed to create MCP server", "error", err)
+		fmt.Fprintf(os.Stderr, "Failed to create MCP server: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Run the server on stdio
+	logging.Info(ctx, "Starting MCP server on stdio")
+	if err := server.Run(ctx); err != nil {
+		logging.Error(ctx, "Server error", "error", err)
+		fmt.Fprintf(os.Stderr, "Server error: %v\n", err)
+		os.Exit(1)
+	}
+
+	logging.Info(ctx, "MCP Code Review Server shutting down")
+}
+
+// validateCredentials checks the format of every configured API key
+func validateCredentials(ctx context.Context, cfg *config.Config) error {
+	validator := credentials.NewValidator()
+	if err := validator.ValidateAll(cfg.AnthropicAPIKey, cfg.OpenAIAPIKey, cfg.GoogleAPIKey); err != nil {
+		logging.Error(ctx, "Invalid API credentials", "error", err)
+		return err
+	}
+	return nil
+}
+
+// historyDisabled is the history_dir value that turns off review history
+const historyDisabled = "none"
+
+// openHistory opens the configured review history store. It returns nil
+// when history is disabled or the store cannot be opened, so reviews still
+// run without it.
+func openHistory(ctx context.Context, cfg *config.Config) *history.Store {
+	if cfg.HistoryDir == historyDisabled {
+		return nil
+	}
+	dir := cfg.HistoryDir
+	if dir == "" {
+		var err error
+		if dir, err = history.DefaultDir(); err != nil {
+			logging.Warn(ctx, "Review history disabled", "error", err.Error())
+			return nil
+		}
+	}
+	var opts []history.Option
+	if cfg.HistoryCode {
+		opts = append(opts, history.WithCode())
+	}
+	store, err := history.Open(dir, opts...)
+	if err != nil {
+		logging.Warn(ctx, "Review history disabled", "error", err.Error())
+		return nil
+	}
+	return store
+}
+
+// newEngine initializes the configured providers and the review engine;
+// store may be nil when review history is disabled
+func newEngine(ctx context.Context, cfg *config.Config, store *history.Store) (*review.Engine, error) {
 	// Initialize providers
 	providerMap := make(map[string]review.Provider)
 
@@ -75,35 +165,66 @@ func main() {
 	// Validate at least one provider is available
 	if len(providerMap) == 0 {
 		logging.Error(ctx, "No providers available - check API key configuration")
-		fmt.Fprintf(os.Stderr, "Error: No LLM providers configured. Set at least one API key:\n")
-		fmt.Fprintf(os.Stderr, "  ANTHROPIC_API_KEY\n")
-		fmt.Fprintf(os.Stderr, "  OPENAI_API_KEY\n")
-		fmt.Fprintf(os.Stderr, "  GOOGLE_API_KEY\n")
-		os.Exit(1)
+		return nil, errors.New("no LLM providers configured. Set at least one API key:\n  ANTHROPIC_API_KEY\n  OPENAI_API_KEY\n  GOOGLE_API_KEY")
 	}
 
 	// Create review engine
-	engine := review.NewEngine(providerMap, cfg.DefaultProvider, cfg.MaxDiffSize)
+	engineOpts := []review.Option{
+		review.WithSourceContext(enrich.Options{
+			WindowLines: cfg.ContextLines,
+			TokenBudget: cfg.ContextTokenBudget,
+		}),
+		review.WithFailOn(cfg.FailOn),
+		review.WithMaxDiffSizeOrigin(maxDiffSizeSource(cfg), cfg.Origin("max_diff_size")),
+	}
+	if cfg.GoContext {
+		engineOpts = append(engineOpts, review.WithGoContext(gocontext.Options{
+			TokenBudget: cfg.ContextTokenBudget,
+		}))
+	}
+	analyzers, err := analysis.ParseSpec(cfg.Analyzers)
+	if err != nil {
+		logging.Error(ctx, "Invalid analyzers setting", "error", err)
+		return nil, fmt.Errorf("invalid analyzers setting: %w", err)
+	}
+	if len(analyzers) > 0 {
+		engineOpts = append(engineOpts, review.WithAnalyzers(analyzers, cfg.AnalyzerTimeout))
+	}
+	if cfg.SecretsAction == "block" {
+		engineOpts = append(engineOpts, review.WithBlockOnSecrets())
+	}
+	if cfg.RulesFile != "" {
+		ruleSet, err := rules.Load(cfg.RulesFile)
+		if err != nil {
+			logging.Error(ctx, "Failed to load rule file", "error", err)
+			return nil, err
+		}
+		engineOpts = append(engineOpts, review.WithRules(ruleSet))
+	}
+	if cfg.InstructionsFile != "" {
+		instructions, err := review.ReadInstructionsFile(cfg.InstructionsFile)
+		if err != nil {
+			logging.Error(ctx, "Failed to load instructions file", "error", err)
+			return nil, err
+		}
+		engineOpts = append(engineOpts, review.WithInstructions(filepath.Base(cfg.InstructionsFile), instructions))
+	}
+	if store != nil {
+		engineOpts = append(engineOpts, review.WithRecorder(store))
+	}
+	engine := review.NewEngine(providerMap, cfg.DefaultProvider, cfg.MaxDiffSize, engineOpts...)
 	logging.Info(ctx, "Review engine initialized",
 		"providers", engine.ListProviders(),
 		"max_diff_size", cfg.MaxDiffSize,
 	)
 
-	// Create MCP server
-	server, err := mcp.NewServer(engine)
-	if err != nil {
-		logging.Error(ctx, "Failed to create MCP server", "error", err)
-		fmt.Fprintf(os.Stderr, "Failed to create MCP server: %v\n", err)
-		os.Exit(1)
-	}
+	return engine, nil
+}
 
-	// Run the server on stdio
-	logging.Info(ctx, "Starting MCP server on stdio")
-	if err := server.Run(ctx); err != nil {
-		logging.Error(ctx, "Server error", "error", err)
-		fmt.Fprintf(os.Stderr, "Server error: %v\n", err)
-		os.Exit(1)
+// maxDiffSizeSource maps the config layer of max_diff_size to a review setting source
+func maxDiffSizeSource(cfg *config.Config) string {
+	if cfg.Sources["max_diff_size"] == config.SourceDefault {
+		return review.SourceDefault
 	}
-
-	logging.Info(ctx, "MCP Code Review Server shutting down")
+	return review.SourceConfig
 }
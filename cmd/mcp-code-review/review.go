@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dshills/mcp-pr/internal/config"
+	"github.com/dshills/mcp-pr/internal/logging"
+	"github.com/dshills/mcp-pr/internal/mcp"
+	"github.com/dshills/mcp-pr/internal/review"
+)
+
+// Exit codes for command-line reviews
+const (
+	exitPass  = 0 // Review passed
+	exitFail  = 1 // Review verdict is fail
+	exitError = 2 // Invalid usage, configuration or review error
+)
+
+// stringList is a repeatable string flag
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// runReview reviews git changes from the command line, prints the result and
+// returns an exit code that follows the review verdict
+func runReview(args []string) int {
+	fs := flag.NewFlagSet("review", flag.ContinueOnError)
+	configOpts := config.BindFlags(fs)
+	repo := fs.String("repo", ".", "path to the git repository")
+	source := fs.String("source", "staged", "changes to review: staged, unstaged, working-tree or commit")
+	commit := fs.String("commit", "", "commit SHA to review with --source commit")
+	reviewMessage := fs.Bool("review-message", false, "also check the commit message with --source commit")
+	patchPath := fs.String("patch", "", "review a .patch, .diff or mbox file instead of the repository (- reads standard input)")
+	provider := fs.String("provider", "", "LLM provider (default from configuration)")
+	depth := fs.String("depth", "", "review depth: quick or thorough")
+	instructions := fs.String("instructions", "", "extra review guidance for this review")
+	format := fs.String("format", "text", "output format: text or json")
+	writeBaseline := fs.Bool("write-baseline", false, "record the findings in the repository baseline so later reviews suppress them")
+	var include, exclude stringList
+	fs.Var(&include, "include", "glob of files to review (repeatable)")
+	fs.Var(&exclude, "exclude", "glob of files to skip (repeatable)")
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitPass
+		}
+		return exitError
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "Error: --format must be text or json, got %q\n", *format)
+		return exitError
+	}
+
+	sourceType := strings.ReplaceAll(*source, "-", "_")
+	if *patchPath != "" {
+		sourceType = "patch"
+	}
+	switch sourceType {
+	case "staged", "unstaged", "commit", "working_tree", "patch":
+	default:
+		fmt.Fprintf(os.Stderr, "Error: --source must be staged, unstaged, working-tree or commit, got %q\n", *source)
+		return exitError
+	}
+
+	cfg, err := config.LoadWithOptions(*configOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		return exitError
+	}
+
+	// Logs go to stderr so stdout carries only the result; keep them quiet unless asked
+	level := cfg.LogLevel
+	if cfg.Sources["log_level"] == config.SourceDefault {
+		level = "warn"
+	}
+	logging.InitWriter(level, os.Stderr)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ReviewTimeout)
+	defer cancel()
+
+	if err := validateCredentials(ctx, cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Invalid API credentials:\n%v\n", err)
+		return exitError
+	}
+	engine, err := newEngine(ctx, cfg, openHistory(ctx, cfg))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitError
+	}
+
+	req := review.Request{
+		SourceType:     sourceType,
+		RepositoryPath: *repo,
+		CommitSHA:      *commit,
+		ReviewMessage:  *reviewMessage,
+		Provider:       *provider,
+		ReviewDepth:    *depth,
+		Language:       "diff",
+		IncludePaths:   include,
+		ExcludePaths:   exclude,
+		Instructions:   *instructions,
+	}
+	switch {
+	case sourceType == "working_tree":
+		req.IncludeStaged, req.IncludeUnstaged, req.IncludeUntracked = true, true, true
+	case *patchPath == "-":
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to read patch: %v\n", err)
+			return exitError
+		}
+		req.RepositoryPath, req.Code = "", string(data)
+	case sourceType == "patch":
+		req.RepositoryPath, req.PatchPath = "", *patchPath
+	}
+
+	run := engine.Review
+	if *writeBaseline {
+		run = engine.WriteBaseline
+	}
+	resp, err := run(ctx, req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Review failed: %v\n", err)
+		return exitError
+	}
+	if *writeBaseline {
+		recorded := 0
+		for _, f := range resp.Findings {
+			if f.Suppressed == review.SuppressedByBaseline {
+				recorded++
+			}
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %d finding(s) to %s\n", recorded, resp.Metadata.BaselineFile)
+	}
+
+	if *format == "json" {
+		err = printJSON(os.Stdout, resp)
+	} else {
+		err = printText(os.Stdout, resp)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitError
+	}
+
+	if resp.Verdict == review.VerdictFail {
+		return exitFail
+	}
+	return exitPass
+}
+
+// printJSON writes the review in the same format as the MCP tools
+func printJSON(w io.Writer, resp *review.Response) error {
+	return writeJSON(w, mcp.FormatReviewResponse(resp))
+}
+
+// writeJSON writes v as indented JSON
+func writeJSON(w io.Writer, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to format response: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// printText writes the review for people reading a terminal
+func printText(w io.Writer, resp *review.Response) error {
+	var b strings.Builder
+
+	if resp.Summary != "" {
+		b.WriteString(resp.Summary + "\n\n")
+	}
+	for _, f := range resp.Findings {
+		if f.Suppressed != "" {
+			continue
+		}
+		location := f.FilePath
+		if f.Line != nil {
+			location = fmt.Sprintf("%s:%d", location, *f.Line)
+		}
+		if location != "" {
+			location += ": "
+		}
+		status := ""
+		if f.Status != "" && f.Status != review.StatusStillPresent {
+			status = " (" + f.Status + ")"
+		}
+		fmt.Fprintf(&b, "[%s] %s%s: %s%s\n", f.Severity, f.Category, status, location, f.Description)
+		if f.Suggestion != "" {
+			fmt.Fprintf(&b, "    suggestion: %s\n", f.Suggestion)
+		}
+	}
+
+	var counts []string
+	for _, severity := range []string{"critical", "high", "medium", "low", "info"} {
+		if n := resp.Counts.BySeverity[severity]; n > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", n, severity))
+		}
+	}
+	if len(counts) == 0 {
+		counts = append(counts, "no findings")
+	}
+	if resp.Counts.Suppressed > 0 {
+		counts = append(counts, fmt.Sprintf("%d suppressed", resp.Counts.Suppressed))
+	}
+	if n := resp.Counts.ByStatus[review.StatusFixed]; n > 0 {
+		counts = append(counts, fmt.Sprintf("%d fixed since the last review", n))
+	}
+	failOn := ""
+	if resp.Metadata != nil && resp.Metadata.Settings != nil {
+		failOn = fmt.Sprintf(" (fail_on %s)", resp.Metadata.Settings.FailOn)
+	}
+	fmt.Fprintf(&b, "\n%s. Verdict: %s%s\n", strings.Join(counts, ", "), resp.Verdict, failOn)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -8,6 +8,7 @@ require (
 	github.com/modelcontextprotocol/go-sdk v1.0.0
 	github.com/openai/openai-go v1.12.0
 	google.golang.org/api v0.251.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
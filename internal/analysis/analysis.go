@@ -0,0 +1,261 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dshills/mcp-pr/internal/git"
+)
+
+// Placeholders expanded in analyzer commands
+const (
+	filesPlaceholder    = "{files}"    // Changed file paths relative to the repository root
+	packagesPlaceholder = "{packages}" // Go package directories containing changed .go files, e.g. ./internal/git
+)
+
+// diagnosticPattern matches file:line[:column]: message output
+var diagnosticPattern = regexp.MustCompile(`^([^:\s][^:]*):(\d+)(?::(\d+))?:\s*(.+)$`)
+
+// Analyzer is a local command that reports file:line:message diagnostics
+type Analyzer struct {
+	Name     string   // Name used to tag diagnostics, e.g. "go-vet"
+	Command  []string // Program and arguments; may contain {files} or {packages}
+	Category string   // Finding category for diagnostics
+	Severity string   // Finding severity for diagnostics
+}
+
+// Diagnostic is a single analyzer report on a changed line
+type Diagnostic struct {
+	Source   string // Analyzer name
+	FilePath string // Path relative to the repository root
+	Line     int
+	Column   int // 0 when the analyzer does not report columns
+	Message  string
+	Category string
+	Severity string
+}
+
+// Result holds diagnostics and which analyzers took part
+type Result struct {
+	Diagnostics []Diagnostic
+	Ran         []string // Analyzers that ran
+	Unavailable []string // Analyzers skipped because the tool is missing or failed to run
+}
+
+// ParseSpec parses an analyzer configuration of the form
+// "name=command args;name=command args". "none" disables all analyzers.
+func ParseSpec(spec string) ([]Analyzer, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" || spec == "none" {
+		return nil, nil
+	}
+
+	var analyzers []Analyzer
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, command, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		fields := strings.Fields(command)
+		if !ok || name == "" || len(fields) == 0 {
+			return nil, fmt.Errorf("invalid analyzer %q: expected name=command", entry)
+		}
+
+		analyzers = append(analyzers, Analyzer{
+			Name:     name,
+			Command:  fields,
+			Category: "bug",
+			Severity: "medium",
+		})
+	}
+
+	return analyzers, nil
+}
+
+// Run executes each analyzer in the repository root and keeps diagnostics
+// reported on lines added by the diff. Missing tools are skipped.
+func Run(ctx context.Context, root string, files []git.FileDiff, analyzers []Analyzer, timeout time.Duration) Result {
+	var result Result
+
+	added := map[string]map[int]bool{}
+	var paths, packages []string
+	seenPackages := map[string]bool{}
+
+	for _, file := range files {
+		if file.IsDeleted || file.IsBinary {
+			continue
+		}
+		filePath := file.Path()
+		lines := map[int]bool{}
+		for _, line := range file.AddedLines() {
+			lines[line.Number] = true
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		added[filePath] = lines
+		paths = append(paths, filePath)
+
+		if strings.HasSuffix(filePath, ".go") {
+			pkg := "./" + path.Dir(filePath)
+			if path.Dir(filePath) == "." {
+				pkg = "."
+			}
+			if !seenPackages[pkg] {
+				seenPackages[pkg] = true
+				packages = append(packages, pkg)
+			}
+		}
+	}
+
+	if len(paths) == 0 {
+		return result
+	}
+	sort.Strings(packages)
+
+	for _, analyzer := range analyzers {
+		args, ok := expandCommand(analyzer.Command, paths, packages)
+		if !ok {
+			continue
+		}
+
+		if _, err := exec.LookPath(args[0]); err != nil {
+			result.Unavailable = append(result.Unavailable, analyzer.Name)
+			continue
+		}
+
+		output, err := runCommand(ctx, root, args, timeout)
+		if err != nil {
+			result.Unavailable = append(result.Unavailable, analyzer.Name)
+			continue
+		}
+		result.Ran = append(result.Ran, analyzer.Name)
+
+		for _, diag := range parseOutput(output, root) {
+			if !added[diag.FilePath][diag.Line] {
+				continue
+			}
+			diag.Source = analyzer.Name
+			diag.Category = analyzer.Category
+			diag.Severity = analyzer.Severity
+			result.Diagnostics = append(result.Diagnostics, diag)
+		}
+	}
+
+	return result
+}
+
+// Format renders diagnostics as a prompt section body
+func Format(diagnostics []Diagnostic) string {
+	var builder strings.Builder
+
+	builder.WriteString("Local analyzers reported the following on changed lines. They are already included in the review results; confirm, explain or dismiss them, and only add a finding when you can add a root cause or a fix.\n\n")
+	for _, diag := range diagnostics {
+		builder.WriteString(fmt.Sprintf("- [%s] %s:%d: %s\n", diag.Source, diag.FilePath, diag.Line, diag.Message))
+	}
+
+	return builder.String()
+}
+
+// expandCommand substitutes placeholders; it reports false when the analyzer has nothing to check
+func expandCommand(command, paths, packages []string) ([]string, bool) {
+	var args []string
+	usesPlaceholder := false
+
+	for _, arg := range command {
+		switch arg {
+		case filesPlaceholder:
+			usesPlaceholder = true
+			args = append(args, paths...)
+		case packagesPlaceholder:
+			if len(packages) == 0 {
+				return nil, false
+			}
+			usesPlaceholder = true
+			args = append(args, packages...)
+		default:
+			args = append(args, arg)
+		}
+	}
+
+	if !usesPlaceholder {
+		args = append(args, paths...)
+	}
+
+	return args, len(args) > 0
+}
+
+// runCommand runs an analyzer and returns its combined output. Non-zero exit
+// codes are expected when diagnostics are found, so only start failures and
+// timeouts are errors.
+func runCommand(ctx context.Context, root string, args []string, timeout time.Duration) (string, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...) //nolint:gosec // G204: analyzer commands come from server configuration
+	cmd.Dir = root
+
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return "", fmt.Errorf("%s: %w", args[0], ctx.Err())
+	}
+
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return "", fmt.Errorf("%s: %w", args[0], err)
+	}
+
+	return string(output), nil
+}
+
+// parseOutput extracts file:line[:column]: message diagnostics
+func parseOutput(output, root string) []Diagnostic {
+	var diagnostics []Diagnostic
+
+	for _, line := range strings.Split(output, "\n") {
+		match := diagnosticPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+
+		lineNumber, err := strconv.Atoi(match[2])
+		if err != nil {
+			continue
+		}
+		column, _ := strconv.Atoi(match[3])
+
+		diagnostics = append(diagnostics, Diagnostic{
+			FilePath: relativePath(match[1], root),
+			Line:     lineNumber,
+			Column:   column,
+			Message:  strings.TrimSpace(match[4]),
+		})
+	}
+
+	return diagnostics
+}
+
+// relativePath converts an analyzer-reported path to a slash path relative to root
+func relativePath(filePath, root string) string {
+	if filepath.IsAbs(filePath) {
+		if rel, err := filepath.Rel(root, filePath); err == nil {
+			filePath = rel
+		}
+	}
+	return path.Clean(filepath.ToSlash(filePath))
+}
@@ -0,0 +1,167 @@
+package baseline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// FileName is the baseline file looked up at the repository root
+const FileName = ".mcp-pr-baseline.json"
+
+// version is the current baseline file format
+const version = 1
+
+// Entry is an accepted finding recorded in a baseline
+type Entry struct {
+	Fingerprint string `json:"fingerprint"`
+	FilePath    string `json:"file_path,omitempty"`
+	Category    string `json:"category"`
+	Severity    string `json:"severity"`
+	Description string `json:"description"` // Kept so people can review what was accepted
+}
+
+// Baseline is a set of accepted findings keyed by fingerprint
+type Baseline struct {
+	Version int     `json:"version"`
+	Entries []Entry `json:"findings"`
+
+	index map[string]bool
+}
+
+// New creates a baseline from entries, dropping duplicate fingerprints
+func New(entries []Entry) *Baseline {
+	b := &Baseline{Version: version, index: map[string]bool{}}
+	for _, entry := range entries {
+		if b.index[entry.Fingerprint] {
+			continue
+		}
+		b.index[entry.Fingerprint] = true
+		b.Entries = append(b.Entries, entry)
+	}
+
+	sort.Slice(b.Entries, func(i, j int) bool {
+		if b.Entries[i].FilePath != b.Entries[j].FilePath {
+			return b.Entries[i].FilePath < b.Entries[j].FilePath
+		}
+		return b.Entries[i].Fingerprint < b.Entries[j].Fingerprint
+	})
+	return b
+}
+
+// Load reads a baseline file. It returns nil without error when the file does not exist.
+func Load(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is the repository baseline file
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+
+	var file Baseline
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid baseline %s: %w", path, err)
+	}
+	if file.Version != version {
+		return nil, fmt.Errorf("invalid baseline %s: unsupported version %d", path, file.Version)
+	}
+	return New(file.Entries), nil
+}
+
+// Save writes the baseline, replacing any existing file atomically
+func (b *Baseline) Save(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode baseline: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".mcp-pr-baseline-*")
+	if err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // Already renamed on success
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close() //nolint:errcheck,gosec // Reporting the write error
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil { //nolint:gosec // G302: baseline is committed with the repository
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return nil
+}
+
+// Contains reports whether a fingerprint is in the baseline
+func (b *Baseline) Contains(fingerprint string) bool {
+	return b != nil && b.index[fingerprint]
+}
+
+// Fingerprint identifies a finding independently of its line number, so it
+// survives edits elsewhere in the file. The snippet is normalized so
+// whitespace and indentation changes do not affect it.
+func Fingerprint(filePath, category, snippet string) string {
+	sum := sha256.Sum256([]byte(filePath + "\x00" + category + "\x00" + Normalize(snippet)))
+	return hex.EncodeToString(sum[:12])
+}
+
+// Normalize collapses runs of whitespace and trims the result
+func Normalize(snippet string) string {
+	return strings.Join(strings.Fields(snippet), " ")
+}
+
+// ignoreDirective marks a line whose findings should be suppressed
+const ignoreDirective = "mcp-pr:ignore"
+
+// validCategories are the categories an ignore comment can name
+var validCategories = []string{"bug", "security", "performance", "style", "best-practice"}
+
+// Ignores reports whether a line carries an ignore comment covering the
+// category. The directive works in any comment syntax, e.g.
+// "// mcp-pr:ignore style" or "# mcp-pr:ignore bug,performance". Without a
+// category list it covers every category.
+func Ignores(line, category string) bool {
+	idx := strings.Index(line, ignoreDirective)
+	if idx < 0 {
+		return false
+	}
+
+	rest := strings.Fields(line[idx+len(ignoreDirective):])
+	if len(rest) == 0 {
+		return true
+	}
+
+	named := strings.Split(rest[0], ",")
+	if !isCategory(named[0]) {
+		return true // Free-text reason without a category
+	}
+	for _, name := range named {
+		if name == category {
+			return true
+		}
+	}
+	return false
+}
+
+// isCategory reports whether a word names a finding category
+func isCategory(word string) bool {
+	for _, c := range validCategories {
+		if c == word {
+			return true
+		}
+	}
+	return false
+}
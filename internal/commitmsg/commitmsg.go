@@ -0,0 +1,150 @@
+// Package commitmsg parses commit messages and checks them against the
+// Conventional Commits specification (https://www.conventionalcommits.org).
+package commitmsg
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Path is the pseudo file path of findings about a commit message
+const Path = "COMMIT_MSG"
+
+// MaxSubjectLength is the longest subject line that displays well in git tooling
+const MaxSubjectLength = 72
+
+// Types are the commit types of the conventional changelog convention
+var Types = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+
+var (
+	header         = regexp.MustCompile(`^([A-Za-z]+)(?:\(([^()]+)\))?(!)?: (\S.*)$`)
+	breakingFooter = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE: *(.*)$`)
+)
+
+// Message is a parsed commit message
+type Message struct {
+	Subject      string // First line
+	Body         string // Everything after the subject, trimmed
+	Type         string // Conventional Commits type, e.g. "feat"; empty when the subject does not follow the format
+	Scope        string // Optional scope in parentheses
+	Description  string // Subject text after the type and scope
+	Breaking     bool   // Marked as a breaking change with "!" or a BREAKING CHANGE footer
+	BreakingNote string // Text of the BREAKING CHANGE footer
+	separated    bool   // Subject and body are separated by a blank line
+}
+
+// Conventional reports whether the subject follows the Conventional Commits format
+func (m Message) Conventional() bool {
+	return m.Type != ""
+}
+
+// Generated reports whether git wrote the message, as for merges and reverts
+func (m Message) Generated() bool {
+	return strings.HasPrefix(m.Subject, "Merge ") || strings.HasPrefix(m.Subject, `Revert "`)
+}
+
+// Parse splits a commit message into its parts. Comment lines, as left by
+// git commit templates, are ignored.
+func Parse(text string) Message {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, strings.TrimRight(line, " \t"))
+		}
+	}
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	if len(lines) == 0 {
+		return Message{}
+	}
+
+	msg := Message{
+		Subject:   lines[0],
+		Body:      strings.TrimSpace(strings.Join(lines[1:], "\n")),
+		separated: len(lines) < 2 || lines[1] == "",
+	}
+	if m := header.FindStringSubmatch(msg.Subject); m != nil {
+		msg.Type = strings.ToLower(m[1])
+		msg.Scope = m[2]
+		msg.Breaking = m[3] == "!"
+		msg.Description = m[4]
+	}
+	if m := breakingFooter.FindStringSubmatch(msg.Body); m != nil {
+		msg.Breaking = true
+		msg.BreakingNote = strings.TrimSpace(m[1])
+	}
+	return msg
+}
+
+// Issue is a problem found in a commit message
+type Issue struct {
+	Severity   string
+	Message    string
+	Suggestion string
+}
+
+// Check returns the problems with a commit message. Messages generated by
+// git for merges and reverts are only checked for length.
+func Check(msg Message) []Issue {
+	if msg.Subject == "" {
+		return []Issue{{
+			Severity:   "high",
+			Message:    "Commit message is empty",
+			Suggestion: "Describe what the commit changes and why",
+		}}
+	}
+
+	var issues []Issue
+	if len(msg.Subject) > MaxSubjectLength {
+		issues = append(issues, Issue{
+			Severity:   "info",
+			Message:    fmt.Sprintf("Subject line is %d characters long", len(msg.Subject)),
+			Suggestion: fmt.Sprintf("Keep the subject within %d characters and move details to the body", MaxSubjectLength),
+		})
+	}
+	if !msg.separated {
+		issues = append(issues, Issue{
+			Severity:   "low",
+			Message:    "Subject is not followed by a blank line",
+			Suggestion: "Separate the subject from the body with a blank line so git tools show the subject alone",
+		})
+	}
+	if msg.Generated() {
+		return issues
+	}
+
+	switch {
+	case !msg.Conventional():
+		issues = append(issues, Issue{
+			Severity:   "low",
+			Message:    "Subject does not follow Conventional Commits",
+			Suggestion: "Use `type(scope): description`, e.g. `fix(parser): handle empty input`",
+		})
+	case !isType(msg.Type):
+		issues = append(issues, Issue{
+			Severity:   "low",
+			Message:    fmt.Sprintf("Commit type %q is not a conventional type", msg.Type),
+			Suggestion: "Use one of " + strings.Join(Types, ", "),
+		})
+	}
+	if msg.Breaking && msg.BreakingNote == "" && msg.Body == "" {
+		issues = append(issues, Issue{
+			Severity:   "low",
+			Message:    "Breaking change is not explained",
+			Suggestion: "Add a `BREAKING CHANGE:` footer describing what breaks and how to migrate",
+		})
+	}
+	return issues
+}
+
+// isType reports whether a commit type is one of Types
+func isType(commitType string) bool {
+	for _, t := range Types {
+		if t == commitType {
+			return true
+		}
+	}
+	return false
+}
@@ -1,10 +1,24 @@
 package config
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/dshills/mcp-pr/internal/policy"
+)
+
+// Setting sources, from highest to lowest precedence
+const (
+	SourceFlag    = "flag"
+	SourceEnv     = "env"
+	SourceFile    = "file"
+	SourceDefault = "default"
 )
 
 // Config holds all server configuration
@@ -20,32 +34,240 @@ type Config struct {
 	ReviewTimeout   time.Duration
 	MaxDiffSize     int
 
+	// Source context for git-based reviews
+	ContextLines       int  // Lines around each change when no enclosing function is found (0 disables)
+	ContextTokenBudget int  // Approximate token budget for all source context
+	GoContext          bool // Include type information for changed Go code
+
+	// Local static analyzers for git-based reviews
+	Analyzers       string        // "name=command;..." with {files}/{packages} placeholders, or "none"
+	AnalyzerTimeout time.Duration // Per-analyzer run timeout
+
+	// SecretsAction is "redact" to send redacted code or "block" to skip the provider when secrets are found
+	SecretsAction string
+
+	// RulesFile is an optional YAML file of deterministic review rules
+	RulesFile string
+
+	// FailOn is the severity at or above which reviews fail, or "none"
+	FailOn string
+
+	// HistoryDir stores review history; empty uses the default location and "none" disables it
+	HistoryDir string
+	// HistoryCode keeps the reviewed code in history so ask_about_review can answer questions
+	HistoryCode bool
+
+	// InstructionsFile is an optional file of team review instructions, such as a style guide
+	InstructionsFile string
+
 	// Per-provider timeouts
 	AnthropicTimeout time.Duration
 	OpenAITimeout    time.Duration
 	GoogleTimeout    time.Duration
+
+	// ConfigFile is the config file that was read, if any
+	ConfigFile string
+
+	// Sources maps each setting key to the layer it came from
+	Sources map[string]string
+
+	origins map[string]string // Human-readable origin of each setting
+}
+
+// Options control where configuration is read from besides the environment
+type Options struct {
+	ConfigFile string            // Explicit config file (--config); must exist
+	Flags      map[string]string // Values by setting key, taking precedence over everything else
+
+	// AllowMissingKeys skips the API key check for commands that never call a provider
+	AllowMissingKeys bool
+
+	flagSet *flag.FlagSet // Parsed flags registered by BindFlags
+}
+
+// setting describes one configuration value and the places it can be set
+type setting struct {
+	key    string // Config file key; the flag name uses dashes instead of underscores
+	env    string // Environment variable
+	oldEnv string // Deprecated environment variable, if any
+	def    string // Default value
+	usage  string // Flag usage; empty for settings without a flag
+}
+
+// settings lists every configuration value. API keys have no flag so they
+// never appear in process listings.
+var settings = []setting{
+	{key: "anthropic_api_key", env: "ANTHROPIC_API_KEY"},
+	{key: "openai_api_key", env: "OPENAI_API_KEY"},
+	{key: "google_api_key", env: "GOOGLE_API_KEY"},
+	{key: "log_level", env: "MCP_PR_LOG_LEVEL", oldEnv: "MCP_LOG_LEVEL", def: "info", usage: "log level: debug, info, warn or error"},
+	{key: "default_provider", env: "MCP_PR_DEFAULT_PROVIDER", oldEnv: "MCP_DEFAULT_PROVIDER", def: "anthropic", usage: "provider used when a request names none"},
+	{key: "review_timeout", env: "MCP_PR_REVIEW_TIMEOUT", oldEnv: "MCP_REVIEW_TIMEOUT", def: "240s", usage: "overall review timeout"},
+	{key: "max_diff_size", env: "MCP_PR_MAX_DIFF_SIZE", oldEnv: "MCP_MAX_DIFF_SIZE", def: "200000", usage: "maximum diff size in bytes"},
+	{key: "context_lines", env: "MCP_PR_CONTEXT_LINES", def: "20", usage: "lines of source context per change (0 disables)"},
+	{key: "context_token_budget", env: "MCP_PR_CONTEXT_TOKEN_BUDGET", def: "4000", usage: "approximate token budget for source context"},
+	{key: "go_context", env: "MCP_PR_GO_CONTEXT", def: "true", usage: "include type information for changed Go code"},
+	{key: "analyzers", env: "MCP_PR_ANALYZERS", usage: `local analyzers as "name=command;...", e.g. "go-vet=go vet {packages}" (default none)`},
+	{key: "analyzer_timeout", env: "MCP_PR_ANALYZER_TIMEOUT", def: "60s", usage: "per-analyzer timeout"},
+	{key: "secrets_action", env: "MCP_PR_SECRETS_ACTION", def: "redact", usage: `"redact" or "block" when secrets are found`},
+	{key: "rules_file", env: "MCP_PR_RULES_FILE", usage: "YAML file of local review rules"},
+	{key: "fail_on", env: "MCP_PR_FAIL_ON", def: "none", usage: `severity at or above which reviews fail, or "none"`},
+	{key: "history_dir", env: "MCP_PR_HISTORY_DIR", usage: `review history directory, or "none" to disable (default $XDG_DATA_HOME/mcp-pr/history)`},
+	{key: "history_code", env: "MCP_PR_HISTORY_CODE", def: "false", usage: "keep reviewed code in history for follow-up questions"},
+	{key: "instructions_file", env: "MCP_PR_INSTRUCTIONS_FILE", usage: "file of team review instructions added to every prompt"},
+	{key: "anthropic_timeout", env: "ANTHROPIC_TIMEOUT", def: "240s", usage: "Anthropic API timeout"},
+	{key: "openai_timeout", env: "OPENAI_TIMEOUT", def: "240s", usage: "OpenAI API timeout"},
+	{key: "google_timeout", env: "GOOGLE_TIMEOUT", def: "240s", usage: "Google API timeout"},
+}
+
+// Origin describes where a setting's value came from for messages, e.g.
+// "MCP_PR_MAX_DIFF_SIZE", "--max-diff-size", "max_diff_size in
+// /home/me/.config/mcp-pr/config.yaml" or "the built-in default"
+func (c *Config) Origin(key string) string {
+	switch c.Sources[key] {
+	case SourceFile:
+		return key + " in " + c.origins[key]
+	case SourceEnv, SourceFlag:
+		return c.origins[key]
+	default:
+		return "the built-in default"
+	}
+}
+
+// BindFlags registers --config and a flag for each setting on fs. The
+// returned options pick up the flags that were set once fs is parsed.
+func BindFlags(fs *flag.FlagSet) *Options {
+	opts := &Options{flagSet: fs}
+	fs.StringVar(&opts.ConfigFile, "config", "", "path to a YAML config file")
+	for _, s := range settings {
+		if s.usage == "" {
+			continue
+		}
+		usage := s.usage
+		if s.def != "" {
+			usage += fmt.Sprintf(" (default %q)", s.def)
+		}
+		fs.String(flagName(s.key), "", usage)
+	}
+	return opts
 }
 
-// Load reads configuration from environment variables
+// Load reads configuration from environment variables and the first config
+// file found in the default locations
 func Load() (*Config, error) {
+	return LoadWithOptions(Options{})
+}
+
+// LoadWithOptions reads configuration with precedence flags > environment >
+// config file > defaults
+func LoadWithOptions(opts Options) (*Config, error) {
+	values := make(map[string]string, len(settings))
+	sources := make(map[string]string, len(settings))
+	origins := make(map[string]string, len(settings)) // Human-readable origin for error messages
+
+	for _, s := range settings {
+		values[s.key] = s.def
+		sources[s.key] = SourceDefault
+		origins[s.key] = "default"
+	}
+
+	path, err := findConfigFile(opts.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	if path != "" {
+		fileValues, err := readConfigFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range fileValues {
+			values[key] = value
+			sources[key] = SourceFile
+			origins[key] = path
+		}
+	}
+
+	for _, s := range settings {
+		value := os.Getenv(s.env)
+		name := s.env
+		if s.oldEnv != "" {
+			value = GetEnvWithFallback(s.env, s.oldEnv, "")
+			if os.Getenv(s.env) == "" {
+				name = s.oldEnv
+			}
+		}
+		if value != "" {
+			values[s.key] = value
+			sources[s.key] = SourceEnv
+			origins[s.key] = name
+		}
+	}
+
+	flags := make(map[string]string, len(opts.Flags))
+	for key, value := range opts.Flags {
+		flags[key] = value
+	}
+	if opts.flagSet != nil {
+		opts.flagSet.Visit(func(f *flag.Flag) {
+			key := strings.ReplaceAll(f.Name, "-", "_")
+			if isSetting(key) {
+				flags[key] = f.Value.String()
+			}
+		})
+	}
+	for key, value := range flags {
+		if !isSetting(key) {
+			return nil, fmt.Errorf("unknown setting %q", key)
+		}
+		values[key] = value
+		sources[key] = SourceFlag
+		origins[key] = "--" + flagName(key)
+	}
+
+	p := &parser{values: values, origins: origins}
 	cfg := &Config{
-		AnthropicAPIKey:  os.Getenv("ANTHROPIC_API_KEY"),
-		OpenAIAPIKey:     os.Getenv("OPENAI_API_KEY"),
-		GoogleAPIKey:     os.Getenv("GOOGLE_API_KEY"),
-		LogLevel:         GetEnvWithFallback("MCP_PR_LOG_LEVEL", "MCP_LOG_LEVEL", "info"),
-		DefaultProvider:  GetEnvWithFallback("MCP_PR_DEFAULT_PROVIDER", "MCP_DEFAULT_PROVIDER", "anthropic"),
-		ReviewTimeout:    parseDuration(GetEnvWithFallback("MCP_PR_REVIEW_TIMEOUT", "MCP_REVIEW_TIMEOUT", "240s"), 240*time.Second),
-		MaxDiffSize:      parseInt(GetEnvWithFallback("MCP_PR_MAX_DIFF_SIZE", "MCP_MAX_DIFF_SIZE", "200000"), 200000),
-		AnthropicTimeout: parseDuration(getEnv("ANTHROPIC_TIMEOUT", "240s"), 240*time.Second),
-		OpenAITimeout:    parseDuration(getEnv("OPENAI_TIMEOUT", "240s"), 240*time.Second),
-		GoogleTimeout:    parseDuration(getEnv("GOOGLE_TIMEOUT", "240s"), 240*time.Second),
+		AnthropicAPIKey:    p.str("anthropic_api_key"),
+		OpenAIAPIKey:       p.str("openai_api_key"),
+		GoogleAPIKey:       p.str("google_api_key"),
+		LogLevel:           p.str("log_level"),
+		DefaultProvider:    p.str("default_provider"),
+		ReviewTimeout:      p.duration("review_timeout"),
+		MaxDiffSize:        p.integer("max_diff_size", 1),
+		ContextLines:       p.integer("context_lines", 0),
+		ContextTokenBudget: p.integer("context_token_budget", 0),
+		GoContext:          p.boolean("go_context"),
+		Analyzers:          p.str("analyzers"),
+		AnalyzerTimeout:    p.duration("analyzer_timeout"),
+		SecretsAction:      p.str("secrets_action"),
+		RulesFile:          p.str("rules_file"),
+		FailOn:             p.str("fail_on"),
+		HistoryDir:         p.str("history_dir"),
+		HistoryCode:        p.boolean("history_code"),
+		InstructionsFile:   p.str("instructions_file"),
+		AnthropicTimeout:   p.duration("anthropic_timeout"),
+		OpenAITimeout:      p.duration("openai_timeout"),
+		GoogleTimeout:      p.duration("google_timeout"),
+		ConfigFile:         path,
+		Sources:            sources,
+		origins:            origins,
+	}
+	if err := errors.Join(p.errs...); err != nil {
+		return nil, err
 	}
 
 	// Validate at least one API key is present
-	if cfg.AnthropicAPIKey == "" && cfg.OpenAIAPIKey == "" && cfg.GoogleAPIKey == "" {
+	if cfg.AnthropicAPIKey == "" && cfg.OpenAIAPIKey == "" && cfg.GoogleAPIKey == "" && !opts.AllowMissingKeys {
 		return nil, fmt.Errorf("at least one provider API key must be configured (ANTHROPIC_API_KEY, OPENAI_API_KEY, or GOOGLE_API_KEY)")
 	}
 
+	if cfg.SecretsAction != "redact" && cfg.SecretsAction != "block" {
+		return nil, fmt.Errorf("secrets_action must be \"redact\" or \"block\", got %q (from %s)", cfg.SecretsAction, origins["secrets_action"])
+	}
+
+	if cfg.FailOn != "none" && !policy.IsSeverity(cfg.FailOn) {
+		return nil, fmt.Errorf("fail_on must be a severity or \"none\", got %q (from %s)", cfg.FailOn, origins["fail_on"])
+	}
+
 	return cfg, nil
 }
 
@@ -82,27 +304,65 @@ func GetEnvWithFallback(newKey, oldKey, defaultValue string) string {
 	return defaultValue
 }
 
-// getEnv gets environment variable with default fallback
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// flagName returns the command-line flag for a setting key
+func flagName(key string) string {
+	return strings.ReplaceAll(key, "_", "-")
+}
+
+// isSetting reports whether key names a known setting
+func isSetting(key string) bool {
+	for _, s := range settings {
+		if s.key == key {
+			return true
+		}
 	}
-	return defaultValue
+	return false
+}
+
+// parser converts layered string values to typed settings, collecting every
+// invalid value instead of stopping at the first
+type parser struct {
+	values  map[string]string
+	origins map[string]string
+	errs    []error
+}
+
+// str returns a string setting
+func (p *parser) str(key string) string {
+	return p.values[key]
 }
 
-// parseDuration parses duration string with fallback
-func parseDuration(value string, defaultValue time.Duration) time.Duration {
-	if d, err := time.ParseDuration(value); err == nil {
-		return d
+// duration parses a positive duration setting
+func (p *parser) duration(key string) time.Duration {
+	d, err := time.ParseDuration(p.values[key])
+	if err != nil || d <= 0 {
+		p.fail(key, "a positive duration such as \"90s\"")
+		return 0
 	}
-	return defaultValue
+	return d
 }
 
-// parseInt parses int string with fallback
-func parseInt(value string, defaultValue int) int {
-	var result int
-	if _, err := fmt.Sscanf(value, "%d", &result); err == nil {
-		return result
+// integer parses an integer setting no smaller than min
+func (p *parser) integer(key string, min int) int {
+	n, err := strconv.Atoi(p.values[key])
+	if err != nil || n < min {
+		p.fail(key, fmt.Sprintf("an integer of at least %d", min))
+		return 0
 	}
-	return defaultValue
+	return n
+}
+
+// boolean parses a boolean setting
+func (p *parser) boolean(key string) bool {
+	b, err := strconv.ParseBool(p.values[key])
+	if err != nil {
+		p.fail(key, "true or false")
+		return false
+	}
+	return b
+}
+
+// fail records an invalid value
+func (p *parser) fail(key, want string) {
+	p.errs = append(p.errs, fmt.Errorf("%s must be %s, got %q (from %s)", key, want, p.values[key], p.origins[key]))
 }
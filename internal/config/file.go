@@ -0,0 +1,104 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// findConfigFile returns the config file to read: the explicit path, then
+// $XDG_CONFIG_HOME/mcp-pr/config.yaml (or the platform config directory). It
+// returns "" when none exists. The working directory is never searched: the
+// file sets credentials, limits and analyzer commands, so it must come from
+// the operator rather than whichever repository the server was started in.
+func findConfigFile(explicit string) (string, error) {
+	if explicit != "" {
+		if _, err := os.Stat(explicit); err != nil {
+			return "", fmt.Errorf("config file: %w", err)
+		}
+		return explicit, nil
+	}
+
+	var candidates []string
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		candidates = append(candidates, filepath.Join(dir, "mcp-pr", "config.yaml"))
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		candidates = append(candidates, filepath.Join(dir, "mcp-pr", "config.yaml"))
+	}
+
+	for _, path := range candidates {
+		info, err := os.Stat(path)
+		if err == nil && !info.IsDir() {
+			return path, nil
+		}
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("config file: %w", err)
+		}
+	}
+	return "", nil
+}
+
+// readConfigFile reads a flat YAML mapping of setting keys to scalar values.
+// Unknown and duplicate keys are rejected; empty values leave the setting unset.
+func readConfigFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is chosen by the operator
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	values, err := parseConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// parseConfig decodes config file content
+func parseConfig(data []byte) (map[string]string, error) {
+	var doc yaml.Node
+	if err := yaml.NewDecoder(bytes.NewReader(data)).Decode(&doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+
+	root := &doc
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	if root.Kind != yaml.MappingNode {
+		return nil, errors.New("expected a mapping of settings")
+	}
+
+	values := make(map[string]string, len(root.Content)/2)
+	seen := make(map[string]bool, len(root.Content)/2)
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		keyNode, valueNode := root.Content[i], root.Content[i+1]
+		key := keyNode.Value
+
+		if !isSetting(key) {
+			return nil, fmt.Errorf("line %d: unknown setting %q", keyNode.Line, key)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("line %d: duplicate setting %q", keyNode.Line, key)
+		}
+		seen[key] = true
+
+		if valueNode.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("line %d: %s must be a single value", valueNode.Line, key)
+		}
+		if valueNode.Tag == "!!null" || valueNode.Value == "" {
+			continue
+		}
+		values[key] = valueNode.Value
+	}
+	return values, nil
+}
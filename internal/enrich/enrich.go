@@ -0,0 +1,278 @@
+package enrich
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dshills/mcp-pr/internal/git"
+)
+
+// bytesPerToken approximates how many bytes of source make up one LLM token
+const bytesPerToken = 4
+
+// maxFunctionLines bounds how far function detection scans before falling back to a window
+const maxFunctionLines = 400
+
+// functionStartPattern matches common function and type declaration lines across languages
+var functionStartPattern = regexp.MustCompile(`^\s*(?:(?:export|public|private|protected|internal|static|async|final|override|virtual|abstract|pub(?:\([a-z]+\))?|unsafe|extern)\s+)*(?:func|def|function|fn|class|interface|struct|impl|module|sub|procedure)\b`)
+
+// Source reads the post-change contents of a file
+type Source func(ctx context.Context, path string) (string, error)
+
+// Options controls source context enrichment
+type Options struct {
+	WindowLines int // Lines of context around changes when no enclosing function is found
+	TokenBudget int // Approximate maximum number of tokens of context to include
+}
+
+// Enabled reports whether enrichment should run
+func (o Options) Enabled() bool {
+	return o.WindowLines > 0 && o.TokenBudget > 0
+}
+
+// Snippet is a read-only excerpt of a changed file
+type Snippet struct {
+	Path      string // File path relative to the repository root
+	StartLine int    // First line of the excerpt (1-based)
+	EndLine   int    // Last line of the excerpt (inclusive)
+	Kind      string // "function" or "window"
+	Content   string // Excerpt text without line numbers
+}
+
+// Result holds collected snippets and what was left out
+type Result struct {
+	Snippets     []Snippet
+	OmittedFiles []string // Files whose context did not fit the budget or could not be read
+}
+
+// Collect gathers the enclosing function, or a window of lines, around each
+// changed hunk from the post-change version of the files
+func Collect(ctx context.Context, files []git.FileDiff, source Source, opts Options) Result {
+	var result Result
+	if !opts.Enabled() {
+		return result
+	}
+
+	budget := opts.TokenBudget * bytesPerToken
+
+	for _, file := range files {
+		// New files are already shown in full; deleted and binary files have no post-change text
+		if file.IsNew || file.IsDeleted || file.IsBinary || len(file.Hunks) == 0 {
+			continue
+		}
+
+		content, err := source(ctx, file.Path())
+		if err != nil {
+			result.OmittedFiles = append(result.OmittedFiles, file.Path())
+			continue
+		}
+
+		for _, snippet := range fileSnippets(file, strings.Split(content, "\n"), opts.WindowLines) {
+			if len(snippet.Content) > budget {
+				result.OmittedFiles = appendUnique(result.OmittedFiles, file.Path())
+				continue
+			}
+			budget -= len(snippet.Content)
+			result.Snippets = append(result.Snippets, snippet)
+		}
+	}
+
+	return result
+}
+
+// Format renders snippets as a prompt section body with line numbers
+func Format(snippets []Snippet) string {
+	var builder strings.Builder
+
+	for _, snippet := range snippets {
+		builder.WriteString(fmt.Sprintf("File: %s (lines %d-%d, %s)\n", snippet.Path, snippet.StartLine, snippet.EndLine, snippet.Kind))
+		for i, line := range strings.Split(snippet.Content, "\n") {
+			builder.WriteString(fmt.Sprintf("%6d | %s\n", snippet.StartLine+i, line))
+		}
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
+}
+
+// lineRange is an inclusive range of 1-based line numbers
+type lineRange struct {
+	start, end int
+	kind       string
+}
+
+// fileSnippets computes merged excerpt ranges for each hunk of a file
+func fileSnippets(file git.FileDiff, lines []string, window int) []Snippet {
+	var ranges []lineRange
+
+	for _, hunk := range file.Hunks {
+		first, last := changedRange(hunk)
+		if first == 0 {
+			continue
+		}
+
+		if r, ok := enclosingFunction(lines, first, last, hunk.Section); ok {
+			ranges = append(ranges, r)
+			continue
+		}
+
+		ranges = append(ranges, lineRange{
+			start: max(1, first-window),
+			end:   min(len(lines), last+window),
+			kind:  "window",
+		})
+	}
+
+	var snippets []Snippet
+	for _, r := range mergeRanges(ranges) {
+		if r.start > r.end || r.start > len(lines) {
+			continue
+		}
+		snippets = append(snippets, Snippet{
+			Path:      file.Path(),
+			StartLine: r.start,
+			EndLine:   r.end,
+			Kind:      r.kind,
+			Content:   strings.Join(lines[r.start-1:r.end], "\n"),
+		})
+	}
+
+	return snippets
+}
+
+// changedRange returns the post-change line numbers spanned by a hunk's changes
+func changedRange(hunk git.Hunk) (int, int) {
+	first, last := 0, 0
+	line := hunk.NewStart
+
+	for _, text := range hunk.Lines {
+		switch {
+		case strings.HasPrefix(text, "+"):
+			if first == 0 {
+				first = line
+			}
+			last = line
+			line++
+		case strings.HasPrefix(text, "-"):
+			// Deletions sit between new-file lines; anchor them to the next line
+			if first == 0 {
+				first = max(1, line)
+			}
+			last = max(last, max(1, line))
+		case strings.HasPrefix(text, " "):
+			line++
+		}
+	}
+
+	return first, last
+}
+
+// enclosingFunction finds the declaration enclosing the changed lines and its end
+func enclosingFunction(lines []string, first, last int, section string) (lineRange, bool) {
+	start := 0
+	section = strings.TrimSpace(section)
+
+	for i := min(first, len(lines)); i >= 1 && first-i < maxFunctionLines; i-- {
+		text := lines[i-1]
+		if (section != "" && strings.HasPrefix(strings.TrimSpace(text), section)) || functionStartPattern.MatchString(text) {
+			start = i
+			break
+		}
+	}
+	if start == 0 {
+		return lineRange{}, false
+	}
+
+	end := blockEnd(lines, start)
+	if end < last || end-start > maxFunctionLines {
+		return lineRange{}, false
+	}
+
+	return lineRange{start: start, end: end, kind: "function"}, true
+}
+
+// blockEnd finds the last line of the block starting at a declaration, using
+// brace matching or, for brace-less languages, indentation
+func blockEnd(lines []string, start int) int {
+	depth := 0
+	opened := false
+
+	for i := start; i <= len(lines) && i-start <= maxFunctionLines; i++ {
+		for _, r := range lines[i-1] {
+			switch r {
+			case '{':
+				depth++
+				opened = true
+			case '}':
+				depth--
+			}
+		}
+		if opened && depth <= 0 {
+			return i
+		}
+		// No brace on the declaration line or the next: treat as an indentation block
+		if !opened && i > start+1 {
+			break
+		}
+	}
+
+	if opened {
+		return 0
+	}
+
+	indent := indentation(lines[start-1])
+	end := start
+	for i := start + 1; i <= len(lines) && i-start <= maxFunctionLines; i++ {
+		text := lines[i-1]
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		if indentation(text) <= indent {
+			break
+		}
+		end = i
+	}
+	return end
+}
+
+// indentation counts leading whitespace, treating tabs as one column
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// mergeRanges sorts ranges and merges overlapping or adjacent ones
+func mergeRanges(ranges []lineRange) []lineRange {
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].start < ranges[j].start })
+
+	merged := []lineRange{ranges[0]}
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if r.start <= last.end+1 {
+			last.end = max(last.end, r.end)
+			if r.kind == "function" {
+				last.kind = "function"
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+
+	return merged
+}
+
+// appendUnique appends a value if it is not already present
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
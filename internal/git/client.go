@@ -2,12 +2,18 @@ package git
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 )
 
+// gitTimeout bounds individual git invocations
+const gitTimeout = 30 * time.Second
+
 // Client provides git operations on a repository
 type Client struct {
 	repoPath string
@@ -70,7 +76,10 @@ func (c *Client) GetUnstagedDiffContext(ctx context.Context) (string, error) {
 	return string(output), nil
 }
 
-// GetCommitDiff retrieves diff for a specific commit using `git show <sha>`
+// GetCommitDiff retrieves diff for a specific commit using `git show <sha>`.
+// Merge commits are diffed against their first parent instead of the
+// combined diff `git show` prints by default, so the result shows everything
+// the merge brought into the mainline.
 func (c *Client) GetCommitDiff(commitSHA string) (string, error) {
 	return c.GetCommitDiffContext(context.Background(), commitSHA)
 }
@@ -82,11 +91,20 @@ func (c *Client) GetCommitDiffContext(ctx context.Context, commitSHA string) (st
 		return "", err
 	}
 
+	args := []string{"show", commitSHA}
+	commit, err := c.CommitContext(ctx, commitSHA)
+	if err != nil {
+		return "", err
+	}
+	if commit.IsMerge() {
+		args = []string{"show", "-m", "--first-parent", commitSHA}
+	}
+
 	// Add 30s timeout for git operations
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "git", "show", commitSHA)
+	cmd := exec.CommandContext(ctx, "git", args...)
 	cmd.Dir = c.repoPath
 
 	output, err := cmd.CombinedOutput()
@@ -125,6 +143,194 @@ func (c *Client) ValidateCommitContext(ctx context.Context, commitSHA string) er
 	return nil
 }
 
+// WorkingTreeOptions selects which categories of changes a working tree diff includes
+type WorkingTreeOptions struct {
+	IncludeStaged    bool // Changes added to the index
+	IncludeUnstaged  bool // Changes to tracked files not yet staged
+	IncludeUntracked bool // New files not yet tracked (ignored files are skipped)
+}
+
+// GetWorkingTreeDiffContext combines staged, unstaged and untracked changes into a single diff
+func (c *Client) GetWorkingTreeDiffContext(ctx context.Context, opts WorkingTreeOptions) (string, error) {
+	var builder strings.Builder
+
+	switch {
+	case opts.IncludeStaged && opts.IncludeUnstaged:
+		diff, err := c.getTrackedChangesDiff(ctx)
+		if err != nil {
+			return "", err
+		}
+		builder.WriteString(diff)
+	case opts.IncludeStaged:
+		diff, err := c.GetStagedDiffContext(ctx)
+		if err != nil {
+			return "", err
+		}
+		builder.WriteString(diff)
+	case opts.IncludeUnstaged:
+		diff, err := c.GetUnstagedDiffContext(ctx)
+		if err != nil {
+			return "", err
+		}
+		builder.WriteString(diff)
+	}
+
+	if opts.IncludeUntracked {
+		diff, err := c.GetUntrackedDiffContext(ctx)
+		if err != nil {
+			return "", err
+		}
+		builder.WriteString(diff)
+	}
+
+	return builder.String(), nil
+}
+
+// getTrackedChangesDiff returns staged and unstaged changes to tracked files relative to HEAD.
+// Repositories without commits fall back to the staged diff followed by the unstaged diff.
+func (c *Client) getTrackedChangesDiff(ctx context.Context) (string, error) {
+	if err := c.ValidateCommitContext(ctx, "HEAD"); err != nil {
+		staged, err := c.GetStagedDiffContext(ctx)
+		if err != nil {
+			return "", err
+		}
+		unstaged, err := c.GetUnstagedDiffContext(ctx)
+		if err != nil {
+			return "", err
+		}
+		return staged + unstaged, nil
+	}
+
+	output, err := c.runGit(ctx, "diff", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to get working tree diff: %w", err)
+	}
+
+	return string(output), nil
+}
+
+// GetUntrackedFilesContext lists untracked files that are not excluded by .gitignore
+func (c *Client) GetUntrackedFilesContext(ctx context.Context) ([]string, error) {
+	output, err := c.runGit(ctx, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files: %w", err)
+	}
+
+	var files []string
+	for _, name := range strings.Split(string(output), "\x00") {
+		if name != "" {
+			files = append(files, name)
+		}
+	}
+
+	return files, nil
+}
+
+// GetUntrackedDiffContext renders untracked files as new-file diffs
+func (c *Client) GetUntrackedDiffContext(ctx context.Context) (string, error) {
+	files, err := c.GetUntrackedFilesContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return c.GetNewFileDiffContext(ctx, files)
+}
+
+// GetNewFileDiffContext renders working tree files as new-file diffs, so
+// every line of each file appears as added
+func (c *Client) GetNewFileDiffContext(ctx context.Context, files []string) (string, error) {
+	var builder strings.Builder
+	for _, file := range files {
+		// git diff --no-index exits with status 1 when the inputs differ, which is always the case here
+		output, err := c.runGit(ctx, "diff", "--no-index", "--", "/dev/null", file)
+		var exitErr *exec.ExitError
+		if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+			return "", fmt.Errorf("failed to diff file %s: %w", file, err)
+		}
+		builder.Write(output)
+	}
+
+	return builder.String(), nil
+}
+
+// ShowFileContext returns a file's contents at a revision using `git show <rev>:<path>`.
+// An empty revision reads the staged version from the index.
+func (c *Client) ShowFileContext(ctx context.Context, rev, path string) (string, error) {
+	output, err := c.runGit(ctx, "show", rev+":"+path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s at %q: %w", path, rev, err)
+	}
+	return string(output), nil
+}
+
+// ReadWorkingTreeFile returns the current contents of a file given relative to the repository root
+func (c *Client) ReadWorkingTreeFile(path string) (string, error) {
+	root, err := c.GetRepositoryRoot()
+	if err != nil {
+		return "", err
+	}
+
+	fullPath := filepath.Join(root, filepath.FromSlash(path))
+	rel, err := filepath.Rel(root, fullPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %s is outside the repository", path)
+	}
+
+	content, err := os.ReadFile(fullPath) //nolint:gosec // G304: Path is confined to the repository root above
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return string(content), nil
+}
+
+// CurrentBranchContext returns the checked-out branch, or an error for a detached HEAD
+func (c *Client) CurrentBranchContext(ctx context.Context) (string, error) {
+	output, err := c.runGit(ctx, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// ResolveCommitContext returns the full SHA of the commit a revision names
+func (c *Client) ResolveCommitContext(ctx context.Context, rev string) (string, error) {
+	output, err := c.runGit(ctx, "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", rev, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// runGit runs a git command in the repository and returns its standard output
+func (c *Client) runGit(ctx context.Context, args ...string) ([]byte, error) {
+	return c.runGitInput(ctx, "", args...)
+}
+
+// runGitInput runs a git command with input on its standard input
+func (c *Client) runGitInput(ctx context.Context, input string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, gitTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = c.repoPath
+	if input != "" {
+		cmd.Stdin = strings.NewReader(input)
+	}
+
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return output, fmt.Errorf("git %s timed out after %v", args[0], gitTimeout)
+		}
+		return output, fmt.Errorf("git %s: %w (output: %s)", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return output, nil
+}
+
 // IsGitRepository checks if the path is a valid git repository
 func (c *Client) IsGitRepository() bool {
 	cmd := exec.Command("git", "rev-parse", "--git-dir")
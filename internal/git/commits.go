@@ -0,0 +1,108 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidRange is returned for revision ranges that look like options
+var ErrInvalidRange = errors.New("invalid revision range")
+
+// Commit describes a commit listed by RevListContext
+type Commit struct {
+	SHA     string
+	Parents []string
+	Subject string
+}
+
+// IsMerge reports whether the commit has more than one parent
+func (c Commit) IsMerge() bool {
+	return len(c.Parents) > 1
+}
+
+// RevListContext lists the commits in a revision range such as main..HEAD,
+// oldest first
+func (c *Client) RevListContext(ctx context.Context, revRange string) ([]Commit, error) {
+	if revRange == "" || strings.HasPrefix(revRange, "-") {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidRange, revRange)
+	}
+
+	// Each commit prints as "commit <sha>" followed by "\x00<parents>\x00<subject>"
+	output, err := c.runGit(ctx, "rev-list", "--reverse", "--format=%x00%P%x00%s", revRange, "--")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits in %s: %w", revRange, err)
+	}
+
+	var commits []Commit
+	for _, line := range strings.Split(string(output), "\n") {
+		switch {
+		case strings.HasPrefix(line, "commit "):
+			commits = append(commits, Commit{SHA: strings.TrimPrefix(line, "commit ")})
+		case strings.HasPrefix(line, "\x00") && len(commits) > 0:
+			fields := strings.SplitN(line[1:], "\x00", 2)
+			last := &commits[len(commits)-1]
+			last.Parents = strings.Fields(fields[0])
+			if len(fields) > 1 {
+				last.Subject = fields[1]
+			}
+		}
+	}
+	return commits, nil
+}
+
+// CommitContext describes a single commit
+func (c *Client) CommitContext(ctx context.Context, rev string) (Commit, error) {
+	output, err := c.runGit(ctx, "rev-list", "--parents", "-n", "1", rev, "--")
+	if err != nil {
+		return Commit{}, fmt.Errorf("failed to read commit %s: %w", rev, err)
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return Commit{}, fmt.Errorf("commit %s not found", rev)
+	}
+	return Commit{SHA: fields[0], Parents: fields[1:]}, nil
+}
+
+// CommitMessageContext returns the full message of a commit
+func (c *Client) CommitMessageContext(ctx context.Context, rev string) (string, error) {
+	output, err := c.runGit(ctx, "log", "-1", "--format=%B", rev, "--")
+	if err != nil {
+		return "", fmt.Errorf("failed to read message of %s: %w", rev, err)
+	}
+	return strings.TrimRight(string(output), "\n"), nil
+}
+
+// RecentSubjectsContext returns the subjects of the last n commits on HEAD,
+// newest first; a repository without commits has none
+func (c *Client) RecentSubjectsContext(ctx context.Context, n int) ([]string, error) {
+	if err := c.ValidateCommitContext(ctx, "HEAD"); err != nil {
+		return nil, nil
+	}
+	output, err := c.runGit(ctx, "log", "-n", strconv.Itoa(n), "--no-merges", "--format=%s", "HEAD", "--")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recent commits: %w", err)
+	}
+	return splitLines(string(output)), nil
+}
+
+// GetRangeDiffContext returns the changes head brings relative to its merge
+// base with base (`git diff base...head`), as a pull request shows them
+func (c *Client) GetRangeDiffContext(ctx context.Context, base, head string) (string, error) {
+	for _, rev := range []string{base, head} {
+		if rev == "" || strings.HasPrefix(rev, "-") {
+			return "", fmt.Errorf("%w: %q", ErrInvalidRange, rev)
+		}
+		if _, err := c.ResolveCommitContext(ctx, rev); err != nil {
+			return "", err
+		}
+	}
+
+	output, err := c.runGit(ctx, "diff", base+"..."+head, "--")
+	if err != nil {
+		return "", fmt.Errorf("failed to get range diff: %w", err)
+	}
+	return string(output), nil
+}
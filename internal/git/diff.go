@@ -1,19 +1,95 @@
 package git
 
 import (
-	"bufio"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
+// devNull is the path git uses for the missing side of added and deleted files
+const devNull = "/dev/null"
+
+// noNewlineMarker is the line git emits after a line that lacks a trailing newline
+const noNewlineMarker = `\ No newline at end of file`
+
 // FileDiff represents changes to a single file
 type FileDiff struct {
-	OldPath   string // Path before change (or empty for new files)
-	NewPath   string // Path after change (or empty for deleted files)
-	Hunks     []Hunk // Individual change hunks
-	IsNew     bool   // True if file is newly added
-	IsDeleted bool   // True if file is deleted
+	OldPath    string // Path before change (or empty for new files)
+	NewPath    string // Path after change (or empty for deleted files)
+	Hunks      []Hunk // Individual change hunks
+	IsNew      bool   // True if file is newly added
+	IsDeleted  bool   // True if file is deleted
+	IsRename   bool   // True if file was renamed from OldPath to NewPath
+	IsCopy     bool   // True if file was copied from OldPath to NewPath
+	Similarity int    // Similarity index percentage for renames and copies
+	IsBinary   bool   // True for binary changes (content is not included)
+	OldMode    string // File mode before change (e.g. "100644"), if the mode changed
+	NewMode    string // File mode after change, if the mode changed or the file is new
+}
+
+// Path returns the path that identifies the file in the reviewed tree
+func (f FileDiff) Path() string {
+	if f.NewPath != "" && !f.IsDeleted {
+		return f.NewPath
+	}
+	if f.OldPath != "" {
+		return f.OldPath
+	}
+	return f.NewPath
+}
+
+// ModeChanged reports whether the file mode differs between the two sides
+func (f FileDiff) ModeChanged() bool {
+	return f.OldMode != "" && f.NewMode != "" && f.OldMode != f.NewMode
+}
+
+// Line is a line of a diff with its number in the post-change file
+type Line struct {
+	Number int    // 1-based line number in the new file
+	Text   string // Line content without the diff prefix
+}
+
+// AddedLines returns the lines added by the diff, numbered in the post-change file
+func (f FileDiff) AddedLines() []Line {
+	var added []Line
+	for _, hunk := range f.Hunks {
+		number := hunk.NewStart
+		for _, line := range hunk.Lines {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				added = append(added, Line{Number: number, Text: line[1:]})
+				number++
+			case strings.HasPrefix(line, " "):
+				number++
+			}
+		}
+	}
+	return added
+}
+
+// NewContent reconstructs the post-change file from the hunks. Lines the
+// diff does not show are left empty, so line numbers match the new file; a
+// new file is reconstructed completely.
+func (f FileDiff) NewContent() string {
+	var lines []string
+	for _, hunk := range f.Hunks {
+		number := hunk.NewStart
+		for _, line := range hunk.Lines {
+			if !strings.HasPrefix(line, "+") && !strings.HasPrefix(line, " ") {
+				continue
+			}
+			for len(lines) < number {
+				lines = append(lines, "")
+			}
+			lines[number-1] = line[1:]
+			number++
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
 }
 
 // Hunk represents a contiguous block of changes
@@ -22,109 +98,387 @@ type Hunk struct {
 	OldLines int      // Number of lines in old file
 	NewStart int      // Starting line in new file
 	NewLines int      // Number of lines in new file
-	Lines    []string // Actual diff lines (with +/- prefix)
+	Section  string   // Heading after the closing @@ (usually the enclosing function)
+	Lines    []string // Actual diff lines (with +/- prefix, or a `\ No newline at end of file` marker)
 }
 
-// Parse parses unified diff format into structured data
+// Regex patterns for diff headers
+var (
+	hunkHeaderPattern  = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@ ?(.*)$`)
+	similarityPattern  = regexp.MustCompile(`^(?:dis)?similarity index (\d+)%$`)
+	indexHeaderPattern = regexp.MustCompile(`^index [0-9a-f]+\.\.[0-9a-f]+(?: [0-7]+)?$`)
+)
+
+// Parse parses unified diff format into structured data.
+//
+// It understands git extended headers (renames, copies, mode changes, binary
+// patches), C-style quoted paths and `\ No newline at end of file` markers,
+// and also accepts plain unified diffs without `diff --git` lines and diffs
+// with CRLF line endings. Text outside of file sections, such as a commit
+// message, is ignored.
 func Parse(diffText string) ([]FileDiff, error) {
 	if diffText == "" {
 		return []FileDiff{}, nil
 	}
 
-	var fileDiffs []FileDiff
-	var currentFile *FileDiff
-	var currentHunk *Hunk
+	// A diff with CRLF line endings throughout was converted in transit; a
+	// carriage return in a diff with LF endings belongs to the content
+	crlf := strings.Count(diffText, "\n") == strings.Count(diffText, "\r\n")
 
-	scanner := bufio.NewScanner(strings.NewReader(diffText))
+	p := &parser{}
+	for _, line := range strings.Split(strings.TrimSuffix(diffText, "\n"), "\n") {
+		if crlf {
+			line = strings.TrimSuffix(line, "\r")
+		}
+		p.parseLine(line)
+	}
+	p.flushFile()
 
-	// Regex patterns
-	diffHeaderPattern := regexp.MustCompile(`^diff --git a/(.*) b/(.*)$`)
-	oldFilePattern := regexp.MustCompile(`^--- (.*)$`)
-	newFilePattern := regexp.MustCompile(`^\+\+\+ (.*)$`)
-	hunkHeaderPattern := regexp.MustCompile(`^@@ -(\d+),?(\d*) \+(\d+),?(\d*) @@`)
+	return p.files, nil
+}
 
-	for scanner.Scan() {
-		line := scanner.Text()
+// Preamble returns the text before the first file section of a diff, such as
+// the commit header and message printed by `git show`, or "" when the diff
+// starts with a file
+func Preamble(diffText string) string {
+	offset := 0
+	for offset < len(diffText) {
+		line, rest, _ := strings.Cut(diffText[offset:], "\n")
+		next, _, _ := strings.Cut(rest, "\n")
+		if strings.HasPrefix(line, "diff --git ") || (strings.HasPrefix(line, "--- ") && strings.HasPrefix(next, "+++ ")) {
+			return diffText[:offset]
+		}
+		offset += len(line) + 1
+	}
+	return diffText
+}
 
-		// File header: diff --git a/path b/path
-		if matches := diffHeaderPattern.FindStringSubmatch(line); matches != nil {
-			// Save previous file if exists
-			if currentFile != nil {
-				fileDiffs = append(fileDiffs, *currentFile)
-			}
+// parser holds the state of an in-progress Parse
+type parser struct {
+	files       []FileDiff
+	current     *FileDiff
+	hunk        *Hunk
+	oldLeft     int  // Old-side lines remaining in the current hunk
+	newLeft     int  // New-side lines remaining in the current hunk
+	sawOldPath  bool // A "---" line was seen for the current file
+	binaryPatch bool // Inside a "GIT binary patch" payload
+}
 
-			currentFile = &FileDiff{
-				OldPath: matches[1],
-				NewPath: matches[2],
-				Hunks:   []Hunk{},
-			}
-			currentHunk = nil
-			continue
+// parseLine consumes a single line of diff text
+func (p *parser) parseLine(line string) {
+	// Hunk bodies take priority so that content such as "--- x" is not mistaken for a header
+	if p.hunk != nil && (p.oldLeft > 0 || p.newLeft > 0) && p.parseHunkLine(line) {
+		return
+	}
+
+	// A marker may trail the last line of a hunk
+	if p.hunk != nil && line == noNewlineMarker {
+		p.hunk.Lines = append(p.hunk.Lines, line)
+		return
+	}
+
+	p.flushHunk()
+
+	switch {
+	case strings.HasPrefix(line, "diff --git "):
+		p.flushFile()
+		oldPath, newPath := parseDiffGitHeader(strings.TrimPrefix(line, "diff --git "))
+		p.current = &FileDiff{OldPath: oldPath, NewPath: newPath, Hunks: []Hunk{}}
+		return
+
+	case p.binaryPatch:
+		// Skip literal/delta payload lines until the next file
+		return
+
+	case strings.HasPrefix(line, "--- ") && (p.current == nil || p.sawOldPath || len(p.current.Hunks) > 0):
+		// Plain unified diff without a "diff --git" header
+		p.flushFile()
+		p.current = &FileDiff{Hunks: []Hunk{}}
+	}
+
+	if p.current == nil {
+		return
+	}
+
+	if p.parseHeaderLine(line) {
+		return
+	}
+
+	if matches := hunkHeaderPattern.FindStringSubmatch(line); matches != nil {
+		p.hunk = &Hunk{
+			OldStart: parseInt(matches[1]),
+			OldLines: parseCount(matches[2]),
+			NewStart: parseInt(matches[3]),
+			NewLines: parseCount(matches[4]),
+			Section:  matches[5],
+			Lines:    []string{},
 		}
+		p.oldLeft = p.hunk.OldLines
+		p.newLeft = p.hunk.NewLines
+	}
+}
 
-		// Old file marker: --- a/path
-		if oldFilePattern.MatchString(line) {
-			if currentFile != nil && strings.Contains(line, "/dev/null") {
-				currentFile.IsNew = true
-			}
-			continue
+// parseHunkLine consumes a line of hunk content, reporting whether it was one
+func (p *parser) parseHunkLine(line string) bool {
+	switch {
+	case line == noNewlineMarker:
+		// Counts are unaffected by the marker
+	case strings.HasPrefix(line, "+"):
+		p.newLeft--
+	case strings.HasPrefix(line, "-"):
+		p.oldLeft--
+	case strings.HasPrefix(line, " "):
+		p.oldLeft--
+		p.newLeft--
+	case line == "":
+		// Some tools strip the space from empty context lines
+		line = " "
+		p.oldLeft--
+		p.newLeft--
+	default:
+		return false
+	}
+
+	p.hunk.Lines = append(p.hunk.Lines, line)
+	return true
+}
+
+// parseHeaderLine handles per-file header lines, reporting whether the line was one
+func (p *parser) parseHeaderLine(line string) bool {
+	file := p.current
+
+	switch {
+	case strings.HasPrefix(line, "--- "):
+		p.sawOldPath = true
+		name := parsePathLine(strings.TrimPrefix(line, "--- "))
+		if name == devNull {
+			file.IsNew = true
+			file.OldPath = ""
+		} else {
+			file.OldPath = stripPrefix(name, "a/")
 		}
+	case strings.HasPrefix(line, "+++ "):
+		name := parsePathLine(strings.TrimPrefix(line, "+++ "))
+		if name == devNull {
+			file.IsDeleted = true
+			file.NewPath = ""
+		} else {
+			file.NewPath = stripPrefix(name, "b/")
+		}
+	case strings.HasPrefix(line, "new file mode "):
+		file.IsNew = true
+		file.OldPath = ""
+		file.NewMode = strings.TrimPrefix(line, "new file mode ")
+	case strings.HasPrefix(line, "deleted file mode "):
+		file.IsDeleted = true
+		file.NewPath = ""
+		file.OldMode = strings.TrimPrefix(line, "deleted file mode ")
+	case strings.HasPrefix(line, "old mode "):
+		file.OldMode = strings.TrimPrefix(line, "old mode ")
+	case strings.HasPrefix(line, "new mode "):
+		file.NewMode = strings.TrimPrefix(line, "new mode ")
+	case strings.HasPrefix(line, "rename from "):
+		file.IsRename = true
+		file.OldPath = unquotePath(strings.TrimPrefix(line, "rename from "))
+	case strings.HasPrefix(line, "rename to "):
+		file.IsRename = true
+		file.NewPath = unquotePath(strings.TrimPrefix(line, "rename to "))
+	case strings.HasPrefix(line, "copy from "):
+		file.IsCopy = true
+		file.OldPath = unquotePath(strings.TrimPrefix(line, "copy from "))
+	case strings.HasPrefix(line, "copy to "):
+		file.IsCopy = true
+		file.NewPath = unquotePath(strings.TrimPrefix(line, "copy to "))
+	case similarityPattern.MatchString(line):
+		file.Similarity = parseInt(similarityPattern.FindStringSubmatch(line)[1])
+	case indexHeaderPattern.MatchString(line):
+		// Blob hashes are not needed for review
+	case strings.HasPrefix(line, "Binary files ") && strings.HasSuffix(line, " differ"):
+		file.IsBinary = true
+	case line == "GIT binary patch":
+		file.IsBinary = true
+		p.binaryPatch = true
+	default:
+		return false
+	}
 
-		// New file marker: +++ b/path
-		if newFilePattern.MatchString(line) {
-			if currentFile != nil && strings.Contains(line, "/dev/null") {
-				currentFile.IsDeleted = true
-			}
-			continue
+	return true
+}
+
+// flushHunk appends the in-progress hunk to the current file
+func (p *parser) flushHunk() {
+	if p.hunk != nil && p.current != nil {
+		p.current.Hunks = append(p.current.Hunks, *p.hunk)
+	}
+	p.hunk = nil
+	p.oldLeft = 0
+	p.newLeft = 0
+}
+
+// flushFile appends the in-progress file to the results
+func (p *parser) flushFile() {
+	p.flushHunk()
+	if p.current != nil {
+		p.files = append(p.files, *p.current)
+	}
+	p.current = nil
+	p.sawOldPath = false
+	p.binaryPatch = false
+}
+
+// parseDiffGitHeader extracts both paths from the remainder of a "diff --git" line
+func parseDiffGitHeader(rest string) (string, string) {
+	// Quoted first path: "a/x y" b/z or "a/x y" "b/z"
+	if strings.HasPrefix(rest, `"`) {
+		if end := closingQuote(rest); end > 0 {
+			oldPath := unquotePath(rest[:end+1])
+			newPath := unquotePath(strings.TrimPrefix(rest[end+1:], " "))
+			return stripPrefix(oldPath, "a/"), stripPrefix(newPath, "b/")
 		}
+	}
 
-		// Hunk header: @@ -1,5 +1,7 @@
-		if matches := hunkHeaderPattern.FindStringSubmatch(line); matches != nil {
-			// Save previous hunk if exists
-			if currentHunk != nil && currentFile != nil {
-				currentFile.Hunks = append(currentFile.Hunks, *currentHunk)
-			}
+	// Quoted second path only
+	if idx := strings.Index(rest, ` "b/`); idx >= 0 && strings.HasSuffix(rest, `"`) {
+		return stripPrefix(rest[:idx], "a/"), stripPrefix(unquotePath(rest[idx+1:]), "b/")
+	}
 
-			oldStart := parseInt(matches[1])
-			oldLines := parseInt(matches[2])
-			if oldLines == 0 {
-				oldLines = 1 // Default to 1 if not specified
-			}
-			newStart := parseInt(matches[3])
-			newLines := parseInt(matches[4])
-			if newLines == 0 {
-				newLines = 1
-			}
+	// Unquoted paths are ambiguous when they contain " b/"; prefer the split
+	// where both sides name the same file, which holds for everything but renames
+	if len(rest)%2 == 1 {
+		mid := len(rest) / 2
+		oldPart, newPart := rest[:mid], rest[mid+1:]
+		if strings.HasPrefix(oldPart, "a/") && strings.HasPrefix(newPart, "b/") && oldPart[2:] == newPart[2:] {
+			return oldPart[2:], newPart[2:]
+		}
+	}
 
-			currentHunk = &Hunk{
-				OldStart: oldStart,
-				OldLines: oldLines,
-				NewStart: newStart,
-				NewLines: newLines,
-				Lines:    []string{},
-			}
+	// Otherwise prefer the first split that names a file on both sides; the
+	// "---" and "+++" lines, when present, override the guess
+	first := -1
+	for i := 0; i+3 <= len(rest); i++ {
+		if !strings.HasPrefix(rest[i:], " b/") {
 			continue
 		}
+		if first < 0 {
+			first = i
+		}
+		if oldPath, newPath := stripPrefix(rest[:i], "a/"), rest[i+3:]; oldPath != "" && newPath != "" {
+			return oldPath, newPath
+		}
+	}
+	if first >= 0 {
+		return stripPrefix(rest[:first], "a/"), rest[first+3:]
+	}
+
+	return rest, rest
+}
 
-		// Diff content lines (start with +, -, or space)
-		if currentHunk != nil && (strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-") || strings.HasPrefix(line, " ")) {
-			currentHunk.Lines = append(currentHunk.Lines, line)
+// parsePathLine extracts the path from a "---" or "+++" line
+func parsePathLine(value string) string {
+	if strings.HasPrefix(value, `"`) {
+		if end := closingQuote(value); end > 0 {
+			return unquotePath(value[:end+1])
 		}
 	}
 
-	// Save last hunk and file
-	if currentHunk != nil && currentFile != nil {
-		currentFile.Hunks = append(currentFile.Hunks, *currentHunk)
+	// git appends a tab to names containing spaces; other tools append a timestamp
+	if idx := strings.Index(value, "\t"); idx >= 0 {
+		value = value[:idx]
 	}
-	if currentFile != nil {
-		fileDiffs = append(fileDiffs, *currentFile)
+
+	return value
+}
+
+// closingQuote returns the index of the quote closing a C-style quoted string, or -1
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+// unquotePath decodes a C-style quoted path as written by git
+func unquotePath(s string) string {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		return unquoted
+	}
+	return s[1 : len(s)-1]
+}
+
+// quotePath quotes a path the way git does when it contains special characters
+func quotePath(s string) string {
+	needsQuote := false
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c == '"' || c == '\\' || c < 0x20 || c >= 0x7f {
+			needsQuote = true
+			break
+		}
+	}
+	if !needsQuote {
+		return s
+	}
+
+	var builder strings.Builder
+	builder.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			builder.WriteByte('\\')
+			builder.WriteByte(c)
+		case c == '\t':
+			builder.WriteString(`\t`)
+		case c == '\n':
+			builder.WriteString(`\n`)
+		case c < 0x20 || c >= 0x7f:
+			builder.WriteString(fmt.Sprintf(`\%03o`, c))
+		default:
+			builder.WriteByte(c)
+		}
+	}
+	builder.WriteByte('"')
+	return builder.String()
+}
+
+// diffGitHeader renders the paths of a "diff --git" line. Unquoted paths
+// containing " b/" can be split in more than one place, so both are quoted
+// whenever the plain form would not parse back to the same paths.
+func diffGitHeader(oldPath, newPath string) string {
+	header := quotePath("a/"+oldPath) + " " + quotePath("b/"+newPath)
+	if o, n := parseDiffGitHeader(header); o == oldPath && n == newPath {
+		return header
+	}
+	return forceQuotePath("a/"+oldPath) + " " + forceQuotePath("b/"+newPath)
+}
+
+// forceQuotePath quotes a path as git does, even when it has no special characters
+func forceQuotePath(s string) string {
+	if quoted := quotePath(s); strings.HasPrefix(quoted, `"`) {
+		return quoted
 	}
+	return `"` + s + `"`
+}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error scanning diff: %w", err)
+// prefixedPath joins a diff prefix and path, quoting the result when needed
+func prefixedPath(prefix, path string) string {
+	quoted := quotePath(prefix + path)
+	if !strings.HasPrefix(quoted, `"`) && strings.Contains(path, " ") {
+		// Mirror git, which terminates names containing spaces with a tab
+		return quoted + "\t"
 	}
+	return quoted
+}
 
-	return fileDiffs, nil
+// stripPrefix removes a diff path prefix such as "a/" if present
+func stripPrefix(path, prefix string) string {
+	return strings.TrimPrefix(path, prefix)
 }
 
 // parseInt safely parses an integer from a string, returning 0 if empty
@@ -137,27 +491,140 @@ func parseInt(s string) int {
 	return n
 }
 
+// parseCount parses a hunk line count, which defaults to 1 when omitted
+func parseCount(s string) int {
+	if s == "" {
+		return 1
+	}
+	return parseInt(s)
+}
+
+// Render converts parsed file diffs back into unified diff text
+func Render(fileDiffs []FileDiff) string {
+	var builder strings.Builder
+
+	for _, file := range fileDiffs {
+		oldPath := file.OldPath
+		if oldPath == "" {
+			oldPath = file.NewPath
+		}
+		newPath := file.NewPath
+		if newPath == "" {
+			newPath = file.OldPath
+		}
+
+		// Added and deleted files name the same path on both sides of the header
+		switch {
+		case file.IsDeleted:
+			newPath = oldPath
+		case file.IsNew:
+			oldPath = newPath
+		}
+
+		builder.WriteString("diff --git " + diffGitHeader(oldPath, newPath) + "\n")
+
+		switch {
+		case file.IsNew:
+			builder.WriteString(fmt.Sprintf("new file mode %s\n", modeOrDefault(file.NewMode)))
+		case file.IsDeleted:
+			builder.WriteString(fmt.Sprintf("deleted file mode %s\n", modeOrDefault(file.OldMode)))
+		case file.ModeChanged():
+			builder.WriteString(fmt.Sprintf("old mode %s\nnew mode %s\n", file.OldMode, file.NewMode))
+		}
+
+		if file.IsRename || file.IsCopy {
+			verb := "rename"
+			if file.IsCopy {
+				verb = "copy"
+			}
+			builder.WriteString(fmt.Sprintf("similarity index %d%%\n", file.Similarity))
+			builder.WriteString(fmt.Sprintf("%s from %s\n%s to %s\n", verb, quotePath(oldPath), verb, quotePath(newPath)))
+		}
+
+		oldName := prefixedPath("a/", oldPath)
+		if file.IsNew {
+			oldName = devNull
+		}
+		newName := prefixedPath("b/", newPath)
+		if file.IsDeleted {
+			newName = devNull
+		}
+
+		if file.IsBinary {
+			builder.WriteString(fmt.Sprintf("Binary files %s and %s differ\n", strings.TrimSuffix(oldName, "\t"), strings.TrimSuffix(newName, "\t")))
+			continue
+		}
+
+		if len(file.Hunks) == 0 {
+			continue
+		}
+
+		builder.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", oldName, newName))
+
+		for _, hunk := range file.Hunks {
+			builder.WriteString(formatHunkHeader(hunk))
+			builder.WriteString("\n")
+
+			for _, line := range hunk.Lines {
+				builder.WriteString(line)
+				builder.WriteString("\n")
+			}
+		}
+	}
+
+	return builder.String()
+}
+
+// formatHunkHeader renders the "@@ -a,b +c,d @@" line of a hunk
+func formatHunkHeader(hunk Hunk) string {
+	header := fmt.Sprintf("@@ -%d,%d +%d,%d @@", hunk.OldStart, hunk.OldLines, hunk.NewStart, hunk.NewLines)
+	if hunk.Section != "" {
+		header += " " + hunk.Section
+	}
+	return header
+}
+
+// modeOrDefault returns the file mode, defaulting to a regular file
+func modeOrDefault(mode string) string {
+	if mode == "" {
+		return "100644"
+	}
+	return mode
+}
+
 // FormatForReview converts parsed diff back to a simplified format for LLM review
 func FormatForReview(fileDiffs []FileDiff) string {
 	var builder strings.Builder
 
 	for _, file := range fileDiffs {
-		builder.WriteString(fmt.Sprintf("File: %s\n", file.NewPath))
+		builder.WriteString(fmt.Sprintf("File: %s\n", file.Path()))
 
 		switch {
 		case file.IsNew:
 			builder.WriteString("Status: New file\n")
 		case file.IsDeleted:
 			builder.WriteString("Status: Deleted\n")
+		case file.IsRename:
+			builder.WriteString(fmt.Sprintf("Status: Renamed from %s (%d%% similar)\n", file.OldPath, file.Similarity))
+		case file.IsCopy:
+			builder.WriteString(fmt.Sprintf("Status: Copied from %s (%d%% similar)\n", file.OldPath, file.Similarity))
 		default:
 			builder.WriteString("Status: Modified\n")
 		}
 
+		if file.ModeChanged() {
+			builder.WriteString(fmt.Sprintf("Mode: %s -> %s\n", file.OldMode, file.NewMode))
+		}
+
+		if file.IsBinary {
+			builder.WriteString("Binary file (content not shown)\n")
+		}
+
 		builder.WriteString("\n")
 
 		for _, hunk := range file.Hunks {
-			builder.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n",
-				hunk.OldStart, hunk.OldLines, hunk.NewStart, hunk.NewLines))
+			builder.WriteString(formatHunkHeader(hunk))
+			builder.WriteString("\n")
 
 			for _, line := range hunk.Lines {
 				builder.WriteString(line)
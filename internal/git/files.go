@@ -0,0 +1,71 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrPathOutsideRepository is returned for file patterns that leave the repository
+var ErrPathOutsideRepository = errors.New("path is outside the repository")
+
+// ListFilesContext lists the tracked and untracked, non-ignored files of the
+// working tree selected by the patterns, sorted. A pattern selects a file by
+// its path, everything below a directory, or files matching a glob as in
+// MatchGlob; "." selects every file. Files deleted from the working tree,
+// symlinks and submodules are left out.
+func (c *Client) ListFilesContext(ctx context.Context, patterns []string) ([]string, error) {
+	cleaned := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(filepath.ToSlash(pattern))
+		if pattern == "" {
+			continue
+		}
+		if path.IsAbs(pattern) || filepath.IsAbs(pattern) {
+			return nil, fmt.Errorf("%w: %s", ErrPathOutsideRepository, pattern)
+		}
+		pattern = path.Clean(pattern)
+		if pattern == ".." || strings.HasPrefix(pattern, "../") {
+			return nil, fmt.Errorf("%w: %s", ErrPathOutsideRepository, pattern)
+		}
+		cleaned = append(cleaned, pattern)
+	}
+
+	output, err := c.runGit(ctx, "ls-files", "--cached", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	seen := map[string]bool{}
+	var files []string
+	for _, name := range strings.Split(string(output), "\x00") {
+		if name == "" || seen[name] || !selectsFile(cleaned, name) {
+			continue
+		}
+		seen[name] = true
+
+		info, err := os.Lstat(filepath.Join(c.repoPath, filepath.FromSlash(name)))
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		files = append(files, name)
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// selectsFile reports whether any of the cleaned patterns selects the file
+func selectsFile(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if pattern == "." || pattern == name || strings.HasPrefix(name, pattern+"/") || MatchGlob(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
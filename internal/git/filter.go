@@ -0,0 +1,220 @@
+package git
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// defaultExcludes lists lockfiles, minified assets and generated sources that are
+// rarely worth reviewing
+var defaultExcludes = []string{
+	// Lockfiles and dependency checksums
+	"go.sum",
+	"package-lock.json",
+	"npm-shrinkwrap.json",
+	"yarn.lock",
+	"pnpm-lock.yaml",
+	"Cargo.lock",
+	"Gemfile.lock",
+	"composer.lock",
+	"poetry.lock",
+	"Pipfile.lock",
+	"uv.lock",
+
+	// Minified assets and source maps
+	"*.min.js",
+	"*.min.css",
+	"*.map",
+
+	// Generated protobuf code
+	"*.pb.go",
+	"*.pb.gw.go",
+	"*_pb2.py",
+}
+
+// generatedHeaderPattern matches the standard generated-code marker (https://go.dev/s/generatedcode)
+var generatedHeaderPattern = regexp.MustCompile(`^\s*(//|#) Code generated .* DO NOT EDIT\.\s*$`)
+
+// DefaultExcludes returns the built-in exclude patterns
+func DefaultExcludes() []string {
+	patterns := make([]string, len(defaultExcludes))
+	copy(patterns, defaultExcludes)
+	return patterns
+}
+
+// PathFilter selects which files of a diff are sent for review.
+//
+// Patterns are globs matched against slash-separated paths relative to the
+// repository root. A pattern without a slash matches the file name in any
+// directory; "**" matches any number of directories (e.g. "vendor/**").
+type PathFilter struct {
+	Include            []string // When non-empty, only files matching at least one pattern are kept
+	Exclude            []string // Files matching any pattern are dropped
+	UseDefaultExcludes bool     // Also drop DefaultExcludes and files with a generated-code header
+
+	// Source reads a file as it is after the change, to find a generated-code
+	// header the diff does not show (nil = only the diff is checked)
+	Source func(filePath string) (string, error)
+}
+
+// Apply filters file diffs, returning the kept files and the paths of skipped files
+func (f PathFilter) Apply(files []FileDiff) ([]FileDiff, []string) {
+	kept := make([]FileDiff, 0, len(files))
+	var skipped []string
+
+	for _, file := range files {
+		if f.Matches(file) {
+			kept = append(kept, file)
+		} else {
+			skipped = append(skipped, file.Path())
+		}
+	}
+
+	return kept, skipped
+}
+
+// Matches reports whether a file diff passes the filter
+func (f PathFilter) Matches(file FileDiff) bool {
+	filePath := file.Path()
+
+	if len(f.Include) > 0 && !MatchAny(f.Include, filePath) {
+		return false
+	}
+
+	if MatchAny(f.Exclude, filePath) {
+		return false
+	}
+
+	if f.UseDefaultExcludes && (MatchAny(defaultExcludes, filePath) || IsGenerated(file) || f.generatedSource(file)) {
+		return false
+	}
+
+	return true
+}
+
+// IsEmpty reports whether the filter keeps every file
+func (f PathFilter) IsEmpty() bool {
+	return len(f.Include) == 0 && len(f.Exclude) == 0 && !f.UseDefaultExcludes
+}
+
+// IsGenerated reports whether the diff shows a generated-code header in the
+// file. Only a hunk starting at the first line shows the header; a marker
+// further down, such as in a test fixture, does not make a file generated.
+func IsGenerated(file FileDiff) bool {
+	if len(file.Hunks) == 0 || file.Hunks[0].NewStart != 1 {
+		return false
+	}
+
+	var head strings.Builder
+	for _, line := range file.Hunks[0].Lines {
+		if len(line) == 0 || line[0] == '-' || line[0] == '\\' {
+			continue
+		}
+		head.WriteString(line[1:] + "\n")
+	}
+	return HasGeneratedHeader(head.String())
+}
+
+// generatedSource reports whether the file read from Source starts with a
+// generated-code header. Files that cannot be read are treated as hand-written.
+func (f PathFilter) generatedSource(file FileDiff) bool {
+	if f.Source == nil || file.IsDeleted || file.IsBinary {
+		return false
+	}
+	content, err := f.Source(file.Path())
+	if err != nil {
+		return false
+	}
+	return HasGeneratedHeader(content)
+}
+
+// maxHeaderLines bounds how far into a file a generated-code header is looked for
+const maxHeaderLines = 50
+
+// HasGeneratedHeader reports whether a file has a generated-code header
+// before its first line of code, where the convention places it
+func HasGeneratedHeader(content string) bool {
+	for i, line := range strings.SplitN(content, "\n", maxHeaderLines+1) {
+		if i == maxHeaderLines {
+			break
+		}
+		if generatedHeaderPattern.MatchString(line) {
+			return true
+		}
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" && !strings.HasPrefix(trimmed, "//") && !strings.HasPrefix(trimmed, "#") &&
+			!strings.HasPrefix(trimmed, "/*") && !strings.HasPrefix(trimmed, "*") {
+			break
+		}
+	}
+	return false
+}
+
+// MatchAny reports whether the path matches any of the glob patterns
+func MatchAny(patterns []string, filePath string) bool {
+	for _, pattern := range patterns {
+		if MatchGlob(pattern, filePath) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchGlob reports whether a slash-separated path matches a glob pattern.
+// Invalid patterns never match.
+func MatchGlob(pattern, filePath string) bool {
+	pattern = strings.TrimPrefix(strings.TrimSpace(pattern), "./")
+	filePath = strings.TrimPrefix(filePath, "./")
+	if pattern == "" {
+		return false
+	}
+
+	// A trailing slash selects everything below a directory
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+
+	// Patterns without a directory part match the base name anywhere
+	if !strings.Contains(pattern, "/") {
+		matched, err := path.Match(pattern, path.Base(filePath))
+		return err == nil && matched
+	}
+
+	return matchSegments(strings.Split(strings.TrimPrefix(pattern, "/"), "/"), strings.Split(filePath, "/"))
+}
+
+// matchSegments matches path segments against pattern segments, expanding "**"
+func matchSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Collapse consecutive "**" and try every possible split
+			for len(pattern) > 0 && pattern[0] == "**" {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := 0; i <= len(segments); i++ {
+				if matchSegments(pattern, segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(segments) == 0 {
+			return false
+		}
+
+		matched, err := path.Match(pattern[0], segments[0])
+		if err != nil || !matched {
+			return false
+		}
+
+		pattern = pattern[1:]
+		segments = segments[1:]
+	}
+
+	return len(segments) == 0
+}
@@ -0,0 +1,100 @@
+package git
+
+import (
+	"io"
+	"mime"
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+// mboxSeparator matches the "From " line that starts each message of an mbox:
+// a sender, or the commit for `git format-patch`, and a ctime date. The date
+// is required because format-patch does not escape "From " in commit messages.
+var mboxSeparator = regexp.MustCompile(`(?m)^From \S+ +(?:Mon|Tue|Wed|Thu|Fri|Sat|Sun) (?:Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec) +\d{1,2} \d{1,2}:\d{2}(?::\d{2})?\b.*$`)
+
+// escapedFrom matches body lines a mail client escaped as ">From " (mboxo) or
+// with more ">" (mboxrd)
+var escapedFrom = regexp.MustCompile(`(?m)^>(>*From )`)
+
+// subjectPrefix matches the [PATCH n/m] tag format-patch adds to subjects
+var subjectPrefix = regexp.MustCompile(`^(\[[^\]]*\]\s*)+`)
+
+// Patch is one message of an mbox produced by `git format-patch` or a mail client
+type Patch struct {
+	Author  string // From header, decoded
+	Date    string // Date header as written
+	Subject string // Subject without the [PATCH] tag
+	Message string // Commit message body before the diff
+	Diff    string // Everything after the message, including the diff
+}
+
+// IsMbox reports whether text is an mbox of patches rather than a bare diff
+func IsMbox(text string) bool {
+	return mboxSeparator.MatchString(text) || strings.HasPrefix(text, "From: ")
+}
+
+// ParseMbox splits an mbox into its patches. Messages whose headers cannot be
+// read are kept with their whole text as the diff, so no change is lost.
+func ParseMbox(text string) []Patch {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	var messages []string
+	bounds := mboxSeparator.FindAllStringIndex(text, -1)
+	if len(bounds) == 0 {
+		messages = []string{text}
+	}
+	for i, b := range bounds {
+		end := len(text)
+		if i+1 < len(bounds) {
+			end = bounds[i+1][0]
+		}
+		message := strings.TrimPrefix(text[b[1]:end], "\n")
+		messages = append(messages, escapedFrom.ReplaceAllString(message, "$1"))
+	}
+
+	patches := make([]Patch, 0, len(messages))
+	for _, message := range messages {
+		patches = append(patches, parseMessage(message))
+	}
+	return patches
+}
+
+// parseMessage reads the headers and splits the body of one mbox message
+func parseMessage(text string) Patch {
+	msg, err := mail.ReadMessage(strings.NewReader(text))
+	if err != nil {
+		return Patch{Diff: text}
+	}
+
+	var decoder mime.WordDecoder
+	decode := func(value string) string {
+		if decoded, err := decoder.DecodeHeader(value); err == nil {
+			return decoded
+		}
+		return value
+	}
+
+	body, err := io.ReadAll(msg.Body)
+	if err != nil {
+		return Patch{Diff: text}
+	}
+
+	patch := Patch{
+		Author:  decode(msg.Header.Get("From")),
+		Date:    msg.Header.Get("Date"),
+		Subject: subjectPrefix.ReplaceAllString(decode(msg.Header.Get("Subject")), ""),
+	}
+
+	// The message ends at the "---" line before the diffstat, or at the diff itself
+	content := string(body)
+	split := len(content)
+	for _, marker := range []string{"\n---\n", "\ndiff --git ", "\nIndex: ", "\n--- "} {
+		if i := strings.Index("\n"+content, marker); i >= 0 && i < split {
+			split = i
+		}
+	}
+	patch.Message = strings.TrimSpace(content[:split])
+	patch.Diff = content[split:]
+	return patch
+}
@@ -0,0 +1,93 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// patchContextLines is the number of unchanged lines around a replacement
+const patchContextLines = 3
+
+// ErrEmptyPatch is returned when a replacement leaves the file unchanged
+var ErrEmptyPatch = errors.New("replacement does not change the file")
+
+// ReplacementPatch returns a unified diff that replaces lines start through
+// end (1-based, inclusive) of a file's content with replacement. The
+// replacement is a sequence of whole lines; its trailing newline is optional.
+func ReplacementPatch(path, content string, start, end int, replacement string) (string, error) {
+	lines, missingNewline := splitFileLines(content)
+	if start < 1 || end < start || end > len(lines) {
+		return "", fmt.Errorf("line range %d-%d is outside %s (%d lines)", start, end, path, len(lines))
+	}
+
+	var added []string
+	if replacement != "" {
+		added, _ = splitFileLines(replacement)
+	}
+	removed := lines[start-1 : end]
+	if strings.Join(removed, "\n") == strings.Join(added, "\n") {
+		return "", ErrEmptyPatch
+	}
+
+	before := max(start-1-patchContextLines, 0)
+	after := min(end+patchContextLines, len(lines))
+	leading := lines[before : start-1]
+	trailing := lines[end:after]
+
+	oldCount := len(leading) + len(removed) + len(trailing)
+	newCount := len(leading) + len(added) + len(trailing)
+	oldStart, newStart := before+1, before+1
+	if newCount == 0 {
+		newStart = before // An empty range starts at the line before it
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("diff --git %s %s\n", quotePath("a/"+path), quotePath("b/"+path)))
+	builder.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", prefixedPath("a/", path), prefixedPath("b/", path)))
+	builder.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount))
+
+	// Only the last line of the file can lack a newline; the replacement keeps
+	// the original file's ending when it replaces that line
+	lastLine := after == len(lines) && missingNewline
+	writeLines := func(prefix string, lines []string, endsFile bool) {
+		for i, line := range lines {
+			builder.WriteString(prefix + line + "\n")
+			if endsFile && i == len(lines)-1 {
+				builder.WriteString("\\ No newline at end of file\n")
+			}
+		}
+	}
+	writeLines(" ", leading, false)
+	writeLines("-", removed, lastLine && len(trailing) == 0)
+	writeLines("+", added, lastLine && len(trailing) == 0)
+	writeLines(" ", trailing, lastLine)
+
+	return builder.String(), nil
+}
+
+// splitFileLines splits text into lines and reports whether the last line lacks a newline
+func splitFileLines(text string) ([]string, bool) {
+	if text == "" {
+		return nil, false
+	}
+	missingNewline := !strings.HasSuffix(text, "\n")
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n"), missingNewline
+}
+
+// ApplyCheckContext reports whether a patch applies cleanly to the working tree
+func (c *Client) ApplyCheckContext(ctx context.Context, patch string) error {
+	if _, err := c.runGitInput(ctx, patch, "apply", "--check", "-"); err != nil {
+		return fmt.Errorf("patch does not apply: %w", err)
+	}
+	return nil
+}
+
+// ApplyContext applies a patch to the working tree
+func (c *Client) ApplyContext(ctx context.Context, patch string) error {
+	if _, err := c.runGitInput(ctx, patch, "apply", "-"); err != nil {
+		return fmt.Errorf("failed to apply patch: %w", err)
+	}
+	return nil
+}
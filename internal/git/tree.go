@@ -0,0 +1,117 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// treeKind identifies which version of the repository a Tree reads
+type treeKind int
+
+const (
+	treeCommit treeKind = iota
+	treeIndex
+	treeWorking
+)
+
+// Tree reads files from one version of the repository: a commit, the index,
+// or the working tree. Paths are slash-separated and relative to the repository root.
+type Tree struct {
+	client *Client
+	kind   treeKind
+	rev    string
+}
+
+// CommitTree returns a Tree reading files as of a commit
+func (c *Client) CommitTree(rev string) Tree {
+	return Tree{client: c, kind: treeCommit, rev: rev}
+}
+
+// IndexTree returns a Tree reading staged files from the index
+func (c *Client) IndexTree() Tree {
+	return Tree{client: c, kind: treeIndex}
+}
+
+// WorkingTree returns a Tree reading files from the working directory
+func (c *Client) WorkingTree() Tree {
+	return Tree{client: c, kind: treeWorking}
+}
+
+// ReadFile returns the contents of a file
+func (t Tree) ReadFile(ctx context.Context, filePath string) (string, error) {
+	switch t.kind {
+	case treeCommit:
+		return t.client.ShowFileContext(ctx, t.rev, filePath)
+	case treeIndex:
+		return t.client.ShowFileContext(ctx, "", filePath)
+	default:
+		return t.client.ReadWorkingTreeFile(filePath)
+	}
+}
+
+// ListDir returns the names of files directly inside a directory ("" or "." for the root)
+func (t Tree) ListDir(ctx context.Context, dir string) ([]string, error) {
+	dir = strings.Trim(path.Clean("/"+dir), "/")
+
+	switch t.kind {
+	case treeCommit:
+		spec := t.rev + ":" + dir
+		output, err := t.client.runGit(ctx, "ls-tree", "--name-only", spec)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s at %s: %w", dir, t.rev, err)
+		}
+		return splitLines(string(output)), nil
+
+	case treeIndex:
+		output, err := t.client.runGit(ctx, "ls-files", "--full-name", "-z", "--", ":(top)"+dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list staged files in %s: %w", dir, err)
+		}
+		prefix := ""
+		if dir != "" {
+			prefix = dir + "/"
+		}
+		var names []string
+		for _, name := range strings.Split(string(output), "\x00") {
+			rest := strings.TrimPrefix(name, prefix)
+			if name != "" && strings.HasPrefix(name, prefix) && !strings.Contains(rest, "/") {
+				names = append(names, rest)
+			}
+		}
+		return names, nil
+
+	default:
+		root, err := t.client.GetRepositoryRoot()
+		if err != nil {
+			return nil, err
+		}
+		entries, err := os.ReadDir(filepath.Join(root, filepath.FromSlash(dir)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+		}
+		var names []string
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				names = append(names, entry.Name())
+			}
+		}
+		sort.Strings(names)
+		return names, nil
+	}
+}
+
+// splitLines splits command output into non-empty lines
+func splitLines(output string) []string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,609 @@
+package gocontext
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/dshills/mcp-pr/internal/git"
+)
+
+// bytesPerToken approximates how many bytes of source make up one LLM token
+const bytesPerToken = 4
+
+// maxTypeLines bounds how much of a single type definition is included
+const maxTypeLines = 40
+
+// Tree provides Go sources from the reviewed version of the repository
+type Tree interface {
+	ReadFile(ctx context.Context, path string) (string, error)
+	ListDir(ctx context.Context, dir string) ([]string, error)
+}
+
+// Options controls Go package context collection
+type Options struct {
+	TokenBudget int // Approximate maximum number of tokens of context to include
+}
+
+// Enabled reports whether Go context collection should run
+func (o Options) Enabled() bool {
+	return o.TokenBudget > 0
+}
+
+// Function describes a function or method called from changed lines
+type Function struct {
+	Signature string // e.g. "func (*Engine).Review(ctx context.Context, req Request) (*Response, error)"
+	Position  string // Declaration position as path:line
+	Doc       string // First line of the doc comment, if any
+}
+
+// Type describes a named type used on changed lines
+type Type struct {
+	Name       string   // Type name
+	Position   string   // Declaration position as path:line
+	Definition string   // Source of the type declaration
+	Implements []string // Interfaces implemented by the type or its pointer
+}
+
+// Result holds Go context for a set of changed files
+type Result struct {
+	Functions []Function
+	Types     []Type
+	Truncated bool // Some entries were dropped to fit the token budget
+}
+
+// IsEmpty reports whether no context was collected
+func (r Result) IsEmpty() bool {
+	return len(r.Functions) == 0 && len(r.Types) == 0
+}
+
+// Collect type-checks the packages containing changed .go files and returns
+// the functions and types of the reviewed module referenced from added lines.
+// Packages of the same module, named by the go.mod at the root of the tree,
+// are loaded from source so that calls across packages are described too.
+func Collect(ctx context.Context, files []git.FileDiff, tree Tree, opts Options) (Result, error) {
+	var result Result
+
+	// Group changed lines by directory and file
+	changed := map[string]map[string]map[int]bool{}
+	for _, file := range files {
+		filePath := file.Path()
+		if file.IsDeleted || file.IsBinary || !strings.HasSuffix(filePath, ".go") {
+			continue
+		}
+		lines := map[int]bool{}
+		for _, line := range file.AddedLines() {
+			lines[line.Number] = true
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		dir := path.Dir(filePath)
+		if changed[dir] == nil {
+			changed[dir] = map[string]map[int]bool{}
+		}
+		changed[dir][filePath] = lines
+	}
+
+	dirs := make([]string, 0, len(changed))
+	for dir := range changed {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	imp := newTolerantImporter(ctx, tree)
+	var errs []string
+
+	for _, dir := range dirs {
+		dirResult, err := collectDir(ctx, dir, changed[dir], imp)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		result.Functions = append(result.Functions, dirResult.Functions...)
+		result.Types = append(result.Types, dirResult.Types...)
+	}
+
+	result = applyBudget(result, opts.TokenBudget*bytesPerToken)
+
+	if len(errs) > 0 && result.IsEmpty() {
+		return result, fmt.Errorf("go context unavailable: %s", strings.Join(errs, "; "))
+	}
+	return result, nil
+}
+
+// Format renders the result as a prompt section body
+func Format(result Result) string {
+	var builder strings.Builder
+
+	if len(result.Functions) > 0 {
+		builder.WriteString("Functions and methods called by the changes:\n")
+		for _, fn := range result.Functions {
+			builder.WriteString(fmt.Sprintf("- %s  // %s\n", fn.Signature, fn.Position))
+			if fn.Doc != "" {
+				builder.WriteString(fmt.Sprintf("    %s\n", fn.Doc))
+			}
+		}
+		builder.WriteString("\n")
+	}
+
+	if len(result.Types) > 0 {
+		builder.WriteString("Types used by the changes:\n")
+		for _, typ := range result.Types {
+			builder.WriteString(fmt.Sprintf("// %s\n%s\n", typ.Position, typ.Definition))
+			if len(typ.Implements) > 0 {
+				builder.WriteString(fmt.Sprintf("// %s implements: %s\n", typ.Name, strings.Join(typ.Implements, ", ")))
+			}
+			builder.WriteString("\n")
+		}
+	}
+
+	if result.Truncated {
+		builder.WriteString("(Further entries omitted to fit the context budget.)\n")
+	}
+
+	return builder.String()
+}
+
+// collectDir analyzes the packages in one directory
+func collectDir(ctx context.Context, dir string, changedFiles map[string]map[int]bool, imp *tolerantImporter) (Result, error) {
+	var result Result
+
+	names, err := imp.tree.ListDir(ctx, dir)
+	if err != nil {
+		return result, err
+	}
+
+	// Parse every Go file in the directory, grouped by package name
+	fset := imp.fset
+	packages := map[string][]*ast.File{}
+	changedPackages := map[string]bool{}
+
+	for _, name := range names {
+		if !strings.HasSuffix(name, ".go") {
+			continue
+		}
+		filePath := path.Join(dir, name)
+		if dir == "." {
+			filePath = name
+		}
+
+		src, err := imp.tree.ReadFile(ctx, filePath)
+		if err != nil {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, filePath, src, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil && file == nil {
+			continue
+		}
+
+		pkgName := file.Name.Name
+		packages[pkgName] = append(packages[pkgName], file)
+		if changedFiles[filePath] != nil {
+			changedPackages[pkgName] = true
+		}
+	}
+
+	pkgNames := make([]string, 0, len(changedPackages))
+	for name := range changedPackages {
+		pkgNames = append(pkgNames, name)
+	}
+	sort.Strings(pkgNames)
+
+	for _, pkgName := range pkgNames {
+		pkgResult := analyzePackage(dir, packages[pkgName], changedFiles, imp)
+		result.Functions = append(result.Functions, pkgResult.Functions...)
+		result.Types = append(result.Types, pkgResult.Types...)
+	}
+
+	return result, nil
+}
+
+// analyzePackage type-checks one package and extracts references from changed lines
+func analyzePackage(dir string, files []*ast.File, changedFiles map[string]map[int]bool, imp *tolerantImporter) Result {
+	fset := imp.fset
+	pkg, info := imp.check(dir, files)
+	if pkg == nil {
+		return Result{}
+	}
+
+	// Objects of this package and of module packages loaded from source
+	// have declarations to show
+	local := func(p *types.Package) bool {
+		return p == pkg || imp.fromSource(p)
+	}
+
+	usedFuncs := map[types.Object]bool{}
+	usedTypes := map[*types.TypeName]bool{}
+
+	isChanged := func(pos token.Pos) bool {
+		position := fset.Position(pos)
+		return changedFiles[position.Filename][position.Line]
+	}
+
+	for _, file := range files {
+		if changedFiles[fset.Position(file.Pos()).Filename] == nil {
+			continue
+		}
+
+		ast.Inspect(file, func(n ast.Node) bool {
+			ident, ok := n.(*ast.Ident)
+			if !ok || !isChanged(ident.Pos()) {
+				return true
+			}
+
+			obj := info.Uses[ident]
+			if obj == nil {
+				obj = info.Defs[ident]
+			}
+			if obj == nil || obj.Pkg() == nil || !local(obj.Pkg()) {
+				return true
+			}
+
+			switch o := obj.(type) {
+			case *types.Func:
+				// Declarations within the changes are already visible to the reviewer
+				if !isChanged(o.Pos()) {
+					usedFuncs[o] = true
+				}
+				if recv := o.Type().(*types.Signature).Recv(); recv != nil {
+					addNamedType(usedTypes, recv.Type(), local)
+				}
+			case *types.TypeName:
+				addNamedType(usedTypes, o.Type(), local)
+			case *types.Var:
+				addNamedType(usedTypes, o.Type(), local)
+			}
+			return true
+		})
+	}
+
+	// Other packages are named as they are referred to in source
+	qualifier := func(p *types.Package) string {
+		if p == pkg {
+			return ""
+		}
+		return p.Name()
+	}
+	var result Result
+
+	for obj := range usedFuncs {
+		fn := Function{
+			Signature: types.ObjectString(obj, qualifier),
+			Position:  shortPosition(fset, obj.Pos()),
+		}
+		if decl := imp.funcDecls[obj]; decl != nil && decl.Doc != nil {
+			fn.Doc = firstLine(decl.Doc.Text())
+		}
+		result.Functions = append(result.Functions, fn)
+	}
+	sort.Slice(result.Functions, func(i, j int) bool {
+		return result.Functions[i].Position < result.Functions[j].Position
+	})
+
+	interfaces := packageInterfaces(pkg)
+
+	for typeName := range usedTypes {
+		spec := imp.typeSpecs[typeName]
+		if spec == nil || isChanged(spec.Pos()) {
+			continue
+		}
+		result.Types = append(result.Types, Type{
+			Name:       typeName.Name(),
+			Position:   shortPosition(fset, typeName.Pos()),
+			Definition: formatTypeSpec(fset, spec),
+			Implements: implementedInterfaces(typeName, interfaces, qualifier),
+		})
+	}
+	sort.Slice(result.Types, func(i, j int) bool {
+		return result.Types[i].Position < result.Types[j].Position
+	})
+
+	return result
+}
+
+// addNamedType records the named type behind t, if any, when its package is local
+func addNamedType(used map[*types.TypeName]bool, t types.Type, local func(*types.Package) bool) {
+	for {
+		switch typ := t.(type) {
+		case *types.Pointer:
+			t = typ.Elem()
+			continue
+		case *types.Slice:
+			t = typ.Elem()
+			continue
+		case *types.Array:
+			t = typ.Elem()
+			continue
+		case *types.Map:
+			t = typ.Elem()
+			continue
+		case *types.Named:
+			if obj := typ.Obj(); obj.Pkg() != nil && local(obj.Pkg()) {
+				used[obj] = true
+			}
+		}
+		return
+	}
+}
+
+// packageInterfaces returns the non-empty interfaces declared in a package plus error
+func packageInterfaces(pkg *types.Package) []*types.TypeName {
+	var ifaces []*types.TypeName
+
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		typeName, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok {
+			continue
+		}
+		if iface, ok := typeName.Type().Underlying().(*types.Interface); ok && iface.NumMethods() > 0 {
+			ifaces = append(ifaces, typeName)
+		}
+	}
+
+	if errType, ok := types.Universe.Lookup("error").(*types.TypeName); ok {
+		ifaces = append(ifaces, errType)
+	}
+
+	return ifaces
+}
+
+// implementedInterfaces lists the interfaces implemented by a type or a pointer to it
+func implementedInterfaces(typeName *types.TypeName, ifaces []*types.TypeName, qualifier types.Qualifier) []string {
+	typ := typeName.Type()
+	if _, isInterface := typ.Underlying().(*types.Interface); isInterface {
+		return nil
+	}
+
+	var implemented []string
+	for _, candidate := range ifaces {
+		iface := candidate.Type().Underlying().(*types.Interface)
+		switch {
+		case types.Implements(typ, iface):
+			implemented = append(implemented, types.TypeString(candidate.Type(), qualifier))
+		case types.Implements(types.NewPointer(typ), iface):
+			implemented = append(implemented, types.TypeString(candidate.Type(), qualifier)+" (pointer receiver)")
+		}
+	}
+	return implemented
+}
+
+// formatTypeSpec prints a type declaration, truncating long definitions
+func formatTypeSpec(fset *token.FileSet, spec *ast.TypeSpec) string {
+	var buf bytes.Buffer
+	decl := &ast.GenDecl{Tok: token.TYPE, Specs: []ast.Spec{spec}}
+	if err := format.Node(&buf, fset, decl); err != nil {
+		return "type " + spec.Name.Name
+	}
+
+	lines := strings.Split(buf.String(), "\n")
+	if len(lines) > maxTypeLines {
+		lines = append(lines[:maxTypeLines], "\t// ... truncated")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// shortPosition formats a position as path:line
+func shortPosition(fset *token.FileSet, pos token.Pos) string {
+	position := fset.Position(pos)
+	return fmt.Sprintf("%s:%d", position.Filename, position.Line)
+}
+
+// firstLine returns the first line of a comment
+func firstLine(text string) string {
+	text = strings.TrimSpace(text)
+	if idx := strings.Index(text, "\n"); idx >= 0 {
+		return text[:idx]
+	}
+	return text
+}
+
+// applyBudget drops entries beyond the byte budget, keeping functions first
+func applyBudget(result Result, budget int) Result {
+	trimmed := Result{}
+	remaining := budget
+
+	for _, fn := range result.Functions {
+		size := len(fn.Signature) + len(fn.Position) + len(fn.Doc)
+		if size > remaining {
+			trimmed.Truncated = true
+			continue
+		}
+		remaining -= size
+		trimmed.Functions = append(trimmed.Functions, fn)
+	}
+
+	for _, typ := range result.Types {
+		size := len(typ.Definition) + len(typ.Position) + len(strings.Join(typ.Implements, ", "))
+		if size > remaining {
+			trimmed.Truncated = true
+			continue
+		}
+		remaining -= size
+		trimmed.Types = append(trimmed.Types, typ)
+	}
+
+	return trimmed
+}
+
+// tolerantImporter imports standard library packages from export data and
+// packages of the reviewed module from source, and substitutes empty packages
+// for anything it cannot load, so that type checking can proceed without a
+// full build environment. It also indexes the declarations of every package
+// checked from source.
+type tolerantImporter struct {
+	ctx        context.Context
+	tree       Tree
+	fset       *token.FileSet
+	base       types.Importer
+	modulePath string // Module path from the root go.mod, or "" when there is none
+	cache      map[string]*types.Package
+	source     map[*types.Package]bool
+	funcDecls  map[types.Object]*ast.FuncDecl
+	typeSpecs  map[types.Object]*ast.TypeSpec
+}
+
+// newTolerantImporter creates an importer backed by the default compiler
+// importer and the module rooted at the top of the tree
+func newTolerantImporter(ctx context.Context, tree Tree) *tolerantImporter {
+	imp := &tolerantImporter{
+		ctx:       ctx,
+		tree:      tree,
+		fset:      token.NewFileSet(),
+		base:      importer.Default(),
+		cache:     map[string]*types.Package{},
+		source:    map[*types.Package]bool{},
+		funcDecls: map[types.Object]*ast.FuncDecl{},
+		typeSpecs: map[types.Object]*ast.TypeSpec{},
+	}
+	if goMod, err := tree.ReadFile(ctx, "go.mod"); err == nil {
+		imp.modulePath = modulePath(goMod)
+	}
+	return imp
+}
+
+// Import implements types.Importer
+func (i *tolerantImporter) Import(importPath string) (*types.Package, error) {
+	if pkg, ok := i.cache[importPath]; ok {
+		return pkg, nil
+	}
+
+	// A stub breaks import cycles while a package is being checked
+	stub := types.NewPackage(importPath, path.Base(importPath))
+	stub.MarkComplete()
+	i.cache[importPath] = stub
+
+	var pkg *types.Package
+	if dir, ok := i.moduleDir(importPath); ok {
+		pkg = i.importSource(importPath, dir)
+	} else if first, _, _ := strings.Cut(importPath, "/"); !strings.Contains(first, ".") {
+		// Only standard library paths have no dot in their first element
+		if imported, err := i.base.Import(importPath); err == nil {
+			pkg = imported
+		}
+	}
+
+	if pkg == nil {
+		pkg = stub
+	}
+	i.cache[importPath] = pkg
+	return pkg, nil
+}
+
+// fromSource reports whether a package was type-checked from the reviewed tree
+func (i *tolerantImporter) fromSource(pkg *types.Package) bool {
+	return i.source[pkg]
+}
+
+// moduleDir returns the tree directory of an import path within the module
+func (i *tolerantImporter) moduleDir(importPath string) (string, bool) {
+	if i.modulePath == "" {
+		return "", false
+	}
+	if importPath == i.modulePath {
+		return ".", true
+	}
+	if rest, ok := strings.CutPrefix(importPath, i.modulePath+"/"); ok {
+		return rest, true
+	}
+	return "", false
+}
+
+// importSource parses and type-checks the non-test files of a module
+// package, or returns nil when the directory holds no Go files
+func (i *tolerantImporter) importSource(importPath, dir string) *types.Package {
+	names, err := i.tree.ListDir(i.ctx, dir)
+	if err != nil {
+		return nil
+	}
+
+	packages := map[string][]*ast.File{}
+	var pkgName string
+	for _, name := range names {
+		if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		filePath := path.Join(dir, name)
+		src, err := i.tree.ReadFile(i.ctx, filePath)
+		if err != nil {
+			continue
+		}
+		file, err := parser.ParseFile(i.fset, filePath, src, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil && file == nil {
+			continue
+		}
+		packages[file.Name.Name] = append(packages[file.Name.Name], file)
+		// The package with the most files wins over stray ignored files
+		if pkgName == "" || len(packages[file.Name.Name]) > len(packages[pkgName]) {
+			pkgName = file.Name.Name
+		}
+	}
+	if pkgName == "" {
+		return nil
+	}
+
+	pkg, _ := i.check(importPath, packages[pkgName])
+	return pkg
+}
+
+// check type-checks files as the package at pkgPath, tolerating errors, and
+// indexes their declarations
+func (i *tolerantImporter) check(pkgPath string, files []*ast.File) (*types.Package, *types.Info) {
+	info := &types.Info{
+		Defs: map[*ast.Ident]types.Object{},
+		Uses: map[*ast.Ident]types.Object{},
+	}
+
+	conf := types.Config{
+		Importer: i,
+		Error:    func(error) {}, // Partial information is still useful
+	}
+	pkg, _ := conf.Check(pkgPath, i.fset, files, info)
+	if pkg == nil {
+		return nil, nil
+	}
+	i.source[pkg] = true
+
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if obj := info.Defs[d.Name]; obj != nil {
+					i.funcDecls[obj] = d
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					if ts, ok := spec.(*ast.TypeSpec); ok {
+						if obj := info.Defs[ts.Name]; obj != nil {
+							i.typeSpecs[obj] = ts
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return pkg, info
+}
+
+// modulePath returns the module path declared in go.mod content, or ""
+func modulePath(goMod string) string {
+	for _, line := range strings.Split(goMod, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,446 @@
+package history
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dshills/mcp-pr/internal/git"
+	"github.com/dshills/mcp-pr/internal/review"
+)
+
+// DefaultLimit bounds List results when the query sets no limit
+const DefaultLimit = 50
+
+// monthLayout names the JSON-lines file holding a month of records
+const monthLayout = "2006-01"
+
+// lifecycleDepth is how many earlier comparable reviews of a branch are
+// searched for findings that were fixed and have come back
+const lifecycleDepth = 20
+
+// maxRecordSize bounds a single stored line
+const maxRecordSize = 16 * 1024 * 1024
+
+// ErrNotFound is returned when no record has the requested ID
+var ErrNotFound = errors.New("review not found in history")
+
+// RequestSummary describes a review request without its code
+type RequestSummary struct {
+	SourceType     string   `json:"source_type"`
+	RepositoryPath string   `json:"repository_path,omitempty"`
+	CommitSHA      string   `json:"commit_sha,omitempty"`
+	Provider       string   `json:"provider"`
+	Model          string   `json:"model,omitempty"`
+	ReviewDepth    string   `json:"review_depth,omitempty"`
+	FocusAreas     []string `json:"focus_areas,omitempty"`
+	IncludePaths   []string `json:"include_paths,omitempty"`
+	ExcludePaths   []string `json:"exclude_paths,omitempty"`
+	Language       string   `json:"language,omitempty"`
+	CodeBytes      int      `json:"code_bytes"`
+	FileCount      int      `json:"file_count,omitempty"`
+}
+
+// Record is one stored review
+type Record struct {
+	ID           string           `json:"id"`
+	Time         time.Time        `json:"time"`
+	Repository   string           `json:"repository,omitempty"` // Repository root
+	Branch       string           `json:"branch,omitempty"`
+	CommitSHA    string           `json:"commit_sha,omitempty"` // Reviewed commit, or HEAD for uncommitted changes
+	Files        []string         `json:"files,omitempty"`      // Old and new paths of the reviewed files
+	Provider     string           `json:"provider"`
+	Model        string           `json:"model,omitempty"`
+	InputTokens  int              `json:"input_tokens,omitempty"`
+	OutputTokens int              `json:"output_tokens,omitempty"`
+	Verdict      string           `json:"verdict"`
+	Counts       review.Counts    `json:"counts"`
+	Request      RequestSummary   `json:"request"`
+	Response     *review.Response `json:"response,omitempty"`
+}
+
+// Query selects records; zero fields match everything
+type Query struct {
+	Repository string    // Repository root, or any path inside the repository
+	Branch     string    // Branch name
+	Since      time.Time // Inclusive lower bound
+	Until      time.Time // Exclusive upper bound
+	Limit      int       // Maximum records, newest first (0 = DefaultLimit)
+}
+
+// Store keeps review records as JSON lines, one file per month
+type Store struct {
+	dir      string
+	keepCode bool // Save the reviewed code as a conversation thread
+	mu       sync.Mutex
+}
+
+// Option configures a Store
+type Option func(*Store)
+
+// WithCode keeps the reviewed code, as sent to the provider, alongside each
+// record so that follow-up questions can be asked. Without it only the
+// request settings, findings and metadata are stored.
+func WithCode() Option {
+	return func(s *Store) {
+		s.keepCode = true
+	}
+}
+
+// DefaultDir returns $XDG_DATA_HOME/mcp-pr/history, falling back to ~/.local/share
+func DefaultDir() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "mcp-pr", "history"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find history directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "share", "mcp-pr", "history"), nil
+}
+
+// Open creates the store directory if needed
+func Open(dir string, opts ...Option) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+	s := &Store{dir: dir}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+// KeepsCode reports whether reviewed code is stored for follow-up questions
+func (s *Store) KeepsCode() bool {
+	return s.keepCode
+}
+
+// Dir returns the store directory
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Annotate labels the findings of a review relative to earlier reviews of
+// the same branch, source type and, for commits, commit, and applies triage
+// states. It implements review.Recorder.
+func (s *Store) Annotate(ctx context.Context, req review.Request, resp *review.Response) error {
+	repository, branch, commit := locate(ctx, req)
+
+	if err := s.applyTriage(repository, resp); err != nil {
+		return err
+	}
+
+	if repository == "" || branch == "" {
+		return nil
+	}
+	prior, err := s.list(Query{Repository: repository, Branch: branch, Limit: lifecycleDepth}, func(rec Record) bool {
+		// Blocked reviews lack the provider's findings, so they cannot show what was fixed
+		switch {
+		case rec.Request.SourceType != req.SourceType:
+			return false
+		case req.SourceType == "commit" && rec.CommitSHA != commit:
+			return false
+		case rec.Response != nil && rec.Response.Metadata != nil && rec.Response.Metadata.Blocked:
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	Label(resp, resp.ReviewedFiles(), prior)
+	return nil
+}
+
+// Record stores a completed review and returns its ID. It implements review.Recorder.
+func (s *Store) Record(ctx context.Context, req review.Request, resp *review.Response) (string, error) {
+	now := time.Now().UTC()
+	id, err := newID(now)
+	if err != nil {
+		return "", err
+	}
+
+	rec := Record{
+		ID:       id,
+		Time:     now,
+		Provider: resp.Provider,
+		Verdict:  resp.Verdict,
+		Counts:   resp.Counts,
+		Request: RequestSummary{
+			SourceType:     req.SourceType,
+			RepositoryPath: req.RepositoryPath,
+			CommitSHA:      req.CommitSHA,
+			Provider:       req.Provider,
+			Model:          req.Model,
+			ReviewDepth:    req.ReviewDepth,
+			FocusAreas:     req.FocusAreas,
+			IncludePaths:   req.IncludePaths,
+			ExcludePaths:   req.ExcludePaths,
+			Language:       req.Language,
+			CodeBytes:      len(req.Code),
+			FileCount:      len(req.Files),
+		},
+		Response: resp,
+	}
+	if resp.Metadata != nil {
+		rec.Model = resp.Metadata.Model
+		rec.InputTokens = resp.Metadata.InputTokens
+		rec.OutputTokens = resp.Metadata.OutputTokens
+	}
+
+	rec.Repository, rec.Branch, rec.CommitSHA = locate(ctx, req)
+	rec.Files = resp.ReviewedFiles()
+
+	// Keep the reviewed code for follow-up questions only when asked to
+	if s.keepCode {
+		if err := s.SaveThread(review.NewThread(rec.ID, req, resp)); err != nil {
+			return "", err
+		}
+	}
+	if err := s.Append(rec); err != nil {
+		return "", err
+	}
+	return rec.ID, nil
+}
+
+// Append writes a record to the file for its month
+func (s *Store) Append(rec Record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to encode history record: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := filepath.Join(s.dir, rec.Time.UTC().Format(monthLayout)+".jsonl")
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600) //nolint:gosec // G304: path is inside the store directory
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close() //nolint:errcheck,gosec // Reporting the write error
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return f.Close()
+}
+
+// List returns records matching the query, newest first
+func (s *Store) List(q Query) ([]Record, error) {
+	if q.Repository != "" {
+		q.Repository = resolveRepository(q.Repository)
+	}
+	return s.list(q, nil)
+}
+
+// list returns records matching a query whose repository is already resolved
+// and, if keep is not nil, accepted by keep
+func (s *Store) list(q Query, keep func(Record) bool) ([]Record, error) {
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	var records []Record
+	err := s.scan(q.Since, q.Until, func(rec Record) bool {
+		if q.matches(rec) && (keep == nil || keep(rec)) {
+			records = append(records, rec)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time.After(records[j].Time)
+	})
+	if len(records) > limit {
+		records = records[:limit]
+	}
+	return records, nil
+}
+
+// Get returns the record with the given ID
+func (s *Store) Get(id string) (*Record, error) {
+	var found *Record
+	err := s.scan(time.Time{}, time.Time{}, func(rec Record) bool {
+		if rec.ID == id {
+			found = &rec
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return found, nil
+}
+
+// scan calls fn for each record in month files overlapping the time range,
+// newest file first, until fn returns false. Lines that cannot be decoded,
+// such as a write cut short by a crash, are skipped.
+func (s *Store) scan(since, until time.Time, fn func(Record) bool) error {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read history: %w", err)
+	}
+
+	var months []string
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".jsonl")
+		month, err := time.Parse(monthLayout, name)
+		if err != nil || entry.IsDir() || name == entry.Name() {
+			continue
+		}
+		if !since.IsZero() && !month.AddDate(0, 1, 0).After(since) {
+			continue
+		}
+		if !until.IsZero() && !month.Before(until) {
+			continue
+		}
+		months = append(months, entry.Name())
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(months)))
+
+	for _, name := range months {
+		more, err := s.scanFile(filepath.Join(s.dir, name), fn)
+		if err != nil {
+			return err
+		}
+		if !more {
+			return nil
+		}
+	}
+	return nil
+}
+
+// scanFile calls fn for each record in a file and reports whether to continue
+func (s *Store) scanFile(path string, fn func(Record) bool) (bool, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: path is inside the store directory
+	if err != nil {
+		return false, fmt.Errorf("failed to read history: %w", err)
+	}
+	defer f.Close() //nolint:errcheck // Read-only
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRecordSize)
+	for scanner.Scan() {
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			continue
+		}
+		if !fn(rec) {
+			return false, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("failed to read history: %w", err)
+	}
+	return true, nil
+}
+
+// matches reports whether a record satisfies the query
+func (q Query) matches(rec Record) bool {
+	switch {
+	case q.Repository != "" && rec.Repository != q.Repository:
+		return false
+	case q.Branch != "" && rec.Branch != q.Branch:
+		return false
+	case !q.Since.IsZero() && rec.Time.Before(q.Since):
+		return false
+	case !q.Until.IsZero() && !rec.Time.Before(q.Until):
+		return false
+	}
+	return true
+}
+
+// Summary returns the record without the stored response
+func (r Record) Summary() Record {
+	r.Response = nil
+	return r
+}
+
+// ParseTime parses a query bound: an RFC 3339 time, a date (2006-01-02) or
+// an age relative to now such as "36h" or "7d"
+func ParseTime(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: use RFC 3339, YYYY-MM-DD, or an age such as 36h or 7d", value)
+}
+
+// locate returns the repository root, current branch and reviewed commit of
+// a git review; values that cannot be determined are left empty
+func locate(ctx context.Context, req review.Request) (repository, branch, commit string) {
+	if req.RepositoryPath == "" {
+		return "", "", ""
+	}
+
+	client := git.NewClient(req.RepositoryPath)
+	if root, err := client.GetRepositoryRoot(); err == nil {
+		repository = root
+	}
+	if name, err := client.CurrentBranchContext(ctx); err == nil {
+		branch = name
+	}
+	rev := "HEAD"
+	if req.CommitSHA != "" {
+		rev = req.CommitSHA
+	}
+	if sha, err := client.ResolveCommitContext(ctx, rev); err == nil {
+		commit = sha
+	}
+	return repository, branch, commit
+}
+
+// resolveRepository maps a path inside a repository to the repository root.
+// Paths that are no longer repositories are compared as absolute paths.
+func resolveRepository(path string) string {
+	if root, err := git.NewClient(path).GetRepositoryRoot(); err == nil {
+		return root
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// newID returns a time-ordered unique record ID
+func newID(t time.Time) (string, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("failed to generate history ID: %w", err)
+	}
+	return t.UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(suffix), nil
+}
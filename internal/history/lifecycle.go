@@ -0,0 +1,122 @@
+package history
+
+import "github.com/dshills/mcp-pr/internal/review"
+
+// Label sets the lifecycle status of each finding in resp relative to prior,
+// earlier comparable reviews newest first. files lists the paths resp
+// reviewed; each is compared with the newest prior review that covered it,
+// and only findings in those files can be reported in resp.Fixed. A nil
+// files, or a record without files, is taken to cover every path.
+func Label(resp *review.Response, files []string, prior []Record) {
+	reviewed := map[string]bool{}
+	for _, path := range files {
+		reviewed[path] = true
+	}
+	inScope := func(path string) bool {
+		return len(reviewed) == 0 || reviewed[path]
+	}
+
+	// The previous review is the newest one that covered any reviewed file;
+	// findings without a file are compared with it
+	var previous *Record
+	for i := range prior {
+		if overlaps(prior[i], files) {
+			previous = &prior[i]
+			break
+		}
+	}
+	if previous != nil && resp.Metadata != nil {
+		resp.Metadata.PreviousReviewID = previous.ID
+	}
+
+	// latest finds the newest prior review that covered a path
+	latest := func(path string) *Record {
+		if path == "" {
+			return previous
+		}
+		for i := range prior {
+			if covers(prior[i], path) {
+				return &prior[i]
+			}
+		}
+		return nil
+	}
+
+	// For each fingerprint, whether it was in the latest review of its file
+	// (still present) or only in an older one (regressed)
+	seen := map[string]bool{}
+	var fixable []review.Finding
+	for i := range prior {
+		rec := &prior[i]
+		if rec.Response == nil {
+			continue
+		}
+		for _, f := range rec.Response.Findings {
+			// Findings without a fingerprint cannot be matched across reviews
+			if f.Fingerprint == "" || (f.FilePath != "" && !inScope(f.FilePath)) {
+				continue
+			}
+			latestReview := latest(f.FilePath) == rec
+			seen[f.Fingerprint] = seen[f.Fingerprint] || latestReview
+			if latestReview {
+				fixable = append(fixable, f)
+			}
+		}
+	}
+
+	current := map[string]bool{}
+	for i := range resp.Findings {
+		f := &resp.Findings[i]
+		current[f.Fingerprint] = true
+		still, ok := seen[f.Fingerprint]
+		switch {
+		case f.Fingerprint == "" || !ok:
+			f.Status = review.StatusNew
+		case still:
+			f.Status = review.StatusStillPresent
+		default:
+			f.Status = review.StatusRegressed
+		}
+	}
+
+	// A review that was never sent to the provider cannot show that its findings are gone
+	resp.Fixed = nil
+	if resp.Metadata != nil && resp.Metadata.Blocked {
+		return
+	}
+	for _, f := range fixable {
+		if current[f.Fingerprint] {
+			continue
+		}
+		// Mark the fingerprint so duplicates in the previous review are reported once
+		current[f.Fingerprint] = true
+		f.Status = review.StatusFixed
+		resp.Fixed = append(resp.Fixed, f)
+	}
+}
+
+// covers reports whether a record reviewed a path
+func covers(rec Record, path string) bool {
+	if len(rec.Files) == 0 {
+		return true
+	}
+	for _, file := range rec.Files {
+		if file == path {
+			return true
+		}
+	}
+	return false
+}
+
+// overlaps reports whether a record reviewed any of files
+func overlaps(rec Record, files []string) bool {
+	if len(files) == 0 {
+		return true
+	}
+	for _, path := range files {
+		if covers(rec, path) {
+			return true
+		}
+	}
+	return false
+}
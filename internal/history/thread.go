@@ -0,0 +1,85 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dshills/mcp-pr/internal/review"
+)
+
+// threadsDir holds one conversation file per review
+const threadsDir = "threads"
+
+// ErrNoThread is returned for reviews recorded without conversation context
+var ErrNoThread = errors.New("no conversation is stored for this review")
+
+// Thread returns the follow-up conversation of a stored review
+func (s *Store) Thread(id string) (*review.Thread, error) {
+	path, err := s.threadPath(id)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is inside the store directory
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNoThread, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read conversation: %w", err)
+	}
+
+	var thread review.Thread
+	if err := json.Unmarshal(data, &thread); err != nil {
+		return nil, fmt.Errorf("failed to read conversation: %w", err)
+	}
+	return &thread, nil
+}
+
+// SaveThread stores a review conversation, replacing any earlier version
+func (s *Store) SaveThread(thread *review.Thread) error {
+	path, err := s.threadPath(thread.ReviewID)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(thread)
+	if err != nil {
+		return fmt.Errorf("failed to encode conversation: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create conversation directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".thread-*")
+	if err != nil {
+		return fmt.Errorf("failed to write conversation: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // Already renamed on success
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close() //nolint:errcheck,gosec // Reporting the write error
+		return fmt.Errorf("failed to write conversation: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write conversation: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write conversation: %w", err)
+	}
+	return nil
+}
+
+// threadPath returns the conversation file of a review ID
+func (s *Store) threadPath(id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || !filepath.IsLocal(id) {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return filepath.Join(s.dir, threadsDir, id+".json"), nil
+}
@@ -0,0 +1,266 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/dshills/mcp-pr/internal/review"
+)
+
+// Triage states
+const (
+	TriageAccepted      = "accepted"       // A real issue the team intends to fix
+	TriageFalsePositive = "false-positive" // Not an issue; suppressed in later reviews
+	TriageWontFix       = "wont-fix"       // A real issue the team accepts; suppressed in later reviews
+	TriageFixed         = "fixed"          // Fixed outside the review workflow
+)
+
+// MaxTriageNote bounds the note stored with a triage state
+const MaxTriageNote = 2000
+
+// triageFile holds triage entries as JSON lines, latest entry winning
+const triageFile = "triage.jsonl"
+
+// Triage errors
+var (
+	ErrInvalidTriageState = errors.New("state must be accepted, false-positive, wont-fix or fixed")
+	ErrTriageNoteTooLong  = fmt.Errorf("note exceeds %d characters", MaxTriageNote)
+	ErrFindingNotFound    = errors.New("finding not found in review")
+)
+
+// Triage records a team decision about a finding. It applies to every later
+// review of the same repository that reports the fingerprint.
+type Triage struct {
+	Time        time.Time `json:"time"`
+	Repository  string    `json:"repository,omitempty"` // Repository root; empty for code snippet reviews
+	Fingerprint string    `json:"fingerprint"`
+	State       string    `json:"state"`
+	Note        string    `json:"note,omitempty"`
+	ReviewID    string    `json:"review_id"` // Review the finding was triaged from
+	Provider    string    `json:"provider"`
+	Model       string    `json:"model,omitempty"`
+	Category    string    `json:"category"`
+}
+
+// ValidTriageState reports whether a value is a triage state
+func ValidTriageState(state string) bool {
+	switch state {
+	case TriageAccepted, TriageFalsePositive, TriageWontFix, TriageFixed:
+		return true
+	}
+	return false
+}
+
+// SetTriage records a triage state for a finding of a stored review
+func (s *Store) SetTriage(reviewID, fingerprint, state, note string) (*Triage, error) {
+	if !ValidTriageState(state) {
+		return nil, fmt.Errorf("%w, got %q", ErrInvalidTriageState, state)
+	}
+	if len(note) > MaxTriageNote {
+		return nil, ErrTriageNoteTooLong
+	}
+
+	rec, err := s.Get(reviewID)
+	if err != nil {
+		return nil, err
+	}
+	finding, ok := rec.Finding(fingerprint)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrFindingNotFound, fingerprint)
+	}
+
+	t := Triage{
+		Time:        time.Now().UTC(),
+		Repository:  rec.Repository,
+		Fingerprint: fingerprint,
+		State:       state,
+		Note:        note,
+		ReviewID:    rec.ID,
+		Provider:    rec.Provider,
+		Model:       rec.Model,
+		Category:    finding.Category,
+	}
+	data, err := json.Marshal(t)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode triage: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(filepath.Join(s.dir, triageFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600) //nolint:gosec // G304: path is inside the store directory
+	if err != nil {
+		return nil, fmt.Errorf("failed to open triage: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close() //nolint:errcheck,gosec // Reporting the write error
+		return nil, fmt.Errorf("failed to write triage: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write triage: %w", err)
+	}
+	return &t, nil
+}
+
+// Finding returns the finding of the record with the given fingerprint,
+// including findings reported as fixed
+func (r Record) Finding(fingerprint string) (review.Finding, bool) {
+	if r.Response == nil || fingerprint == "" {
+		return review.Finding{}, false
+	}
+	for _, list := range [][]review.Finding{r.Response.Findings, r.Response.Fixed} {
+		for _, f := range list {
+			if f.Fingerprint == fingerprint {
+				return f, true
+			}
+		}
+	}
+	return review.Finding{}, false
+}
+
+// triageKey identifies a finding across reviews of a repository
+type triageKey struct {
+	repository  string
+	fingerprint string
+}
+
+// triage returns the latest triage entry for each finding
+func (s *Store) triage() (map[triageKey]Triage, error) {
+	f, err := os.Open(filepath.Join(s.dir, triageFile)) //nolint:gosec // G304: path is inside the store directory
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read triage: %w", err)
+	}
+	defer f.Close() //nolint:errcheck // Read-only
+
+	entries := map[triageKey]Triage{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRecordSize)
+	for scanner.Scan() {
+		var t Triage
+		if err := json.Unmarshal(scanner.Bytes(), &t); err != nil {
+			continue
+		}
+		entries[triageKey{t.Repository, t.Fingerprint}] = t
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read triage: %w", err)
+	}
+	return entries, nil
+}
+
+// applyTriage sets the triage state of each finding and suppresses findings
+// triaged as false positives or won't fix. Secret findings are never suppressed.
+func (s *Store) applyTriage(repository string, resp *review.Response) error {
+	entries, err := s.triage()
+	if err != nil || len(entries) == 0 {
+		return err
+	}
+
+	for i := range resp.Findings {
+		f := &resp.Findings[i]
+		t, ok := entries[triageKey{repository, f.Fingerprint}]
+		if !ok || f.Fingerprint == "" {
+			continue
+		}
+		f.Triage = t.State
+		f.TriageNote = t.Note
+		if f.Suppressed == "" && f.Source != review.SecretSource &&
+			(t.State == TriageFalsePositive || t.State == TriageWontFix) {
+			f.Suppressed = review.SuppressedByTriage
+		}
+	}
+	return nil
+}
+
+// TriageStats aggregates triage feedback for one provider, model and category
+type TriageStats struct {
+	Provider          string         `json:"provider"`
+	Model             string         `json:"model,omitempty"`
+	Category          string         `json:"category"`
+	Findings          int            `json:"findings"`            // Distinct findings reported
+	Triaged           int            `json:"triaged"`             // Distinct findings with a triage state
+	ByState           map[string]int `json:"by_state"`            // Triaged findings by state
+	FalsePositiveRate float64        `json:"false_positive_rate"` // Share of triaged findings marked false-positive
+}
+
+// Stats aggregates triage feedback for reviews matching the query, grouped
+// by provider, model and category. Each finding is counted once per group,
+// however many reviews reported it.
+func (s *Store) Stats(q Query) ([]TriageStats, error) {
+	if q.Repository != "" {
+		q.Repository = resolveRepository(q.Repository)
+	}
+	entries, err := s.triage()
+	if err != nil {
+		return nil, err
+	}
+
+	type groupKey struct{ provider, model, category string }
+	groups := map[groupKey]*TriageStats{}
+	seen := map[groupKey]map[triageKey]bool{}
+	group := func(k groupKey) *TriageStats {
+		if g, ok := groups[k]; ok {
+			return g
+		}
+		g := &TriageStats{Provider: k.provider, Model: k.model, Category: k.category, ByState: map[string]int{}}
+		groups[k] = g
+		seen[k] = map[triageKey]bool{}
+		return g
+	}
+
+	err = s.scan(q.Since, q.Until, func(rec Record) bool {
+		if !q.matches(rec) || rec.Response == nil {
+			return true
+		}
+		for _, f := range rec.Response.Findings {
+			if f.Fingerprint == "" || f.Source != "" {
+				continue // Only model findings are attributed to a provider
+			}
+			k := groupKey{rec.Provider, rec.Model, f.Category}
+			g := group(k)
+			id := triageKey{rec.Repository, f.Fingerprint}
+			if seen[k][id] {
+				continue
+			}
+			seen[k][id] = true
+			g.Findings++
+			if t, ok := entries[id]; ok {
+				g.Triaged++
+				g.ByState[t.State]++
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]TriageStats, 0, len(groups))
+	for _, g := range groups {
+		if g.Triaged > 0 {
+			g.FalsePositiveRate = float64(g.ByState[TriageFalsePositive]) / float64(g.Triaged)
+		}
+		stats = append(stats, *g)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		a, b := stats[i], stats[j]
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		if a.Model != b.Model {
+			return a.Model < b.Model
+		}
+		return a.Category < b.Category
+	})
+	return stats, nil
+}
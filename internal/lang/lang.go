@@ -0,0 +1,328 @@
+// Package lang detects the programming language of source files and code
+// snippets from file names, shebang lines and content heuristics. Languages
+// are named by their usual markdown code fence tag, e.g. "go" or "python".
+package lang
+
+import (
+	"encoding/json"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// fileNames maps well-known file names without a telling extension to their language
+var fileNames = map[string]string{
+	"dockerfile":     "dockerfile",
+	"containerfile":  "dockerfile",
+	"makefile":       "makefile",
+	"gnumakefile":    "makefile",
+	"gemfile":        "ruby",
+	"rakefile":       "ruby",
+	"vagrantfile":    "ruby",
+	"jenkinsfile":    "groovy",
+	"cmakelists.txt": "cmake",
+	".bashrc":        "shell",
+	".zshrc":         "shell",
+	".profile":       "shell",
+}
+
+// extensions maps lowercase file extensions to their language
+var extensions = map[string]string{
+	".go":      "go",
+	".py":      "python",
+	".pyi":     "python",
+	".js":      "javascript",
+	".mjs":     "javascript",
+	".cjs":     "javascript",
+	".jsx":     "jsx",
+	".ts":      "typescript",
+	".mts":     "typescript",
+	".cts":     "typescript",
+	".tsx":     "tsx",
+	".rs":      "rust",
+	".java":    "java",
+	".kt":      "kotlin",
+	".kts":     "kotlin",
+	".scala":   "scala",
+	".groovy":  "groovy",
+	".c":       "c",
+	".h":       "c",
+	".cc":      "cpp",
+	".cpp":     "cpp",
+	".cxx":     "cpp",
+	".hpp":     "cpp",
+	".hh":      "cpp",
+	".cs":      "csharp",
+	".m":       "objectivec",
+	".swift":   "swift",
+	".rb":      "ruby",
+	".php":     "php",
+	".pl":      "perl",
+	".pm":      "perl",
+	".lua":     "lua",
+	".r":       "r",
+	".dart":    "dart",
+	".ex":      "elixir",
+	".exs":     "elixir",
+	".erl":     "erlang",
+	".hs":      "haskell",
+	".clj":     "clojure",
+	".sh":      "shell",
+	".bash":    "shell",
+	".zsh":     "shell",
+	".ps1":     "powershell",
+	".sql":     "sql",
+	".html":    "html",
+	".htm":     "html",
+	".css":     "css",
+	".scss":    "scss",
+	".vue":     "vue",
+	".svelte":  "svelte",
+	".json":    "json",
+	".yaml":    "yaml",
+	".yml":     "yaml",
+	".toml":    "toml",
+	".xml":     "xml",
+	".md":      "markdown",
+	".proto":   "protobuf",
+	".tf":      "terraform",
+	".hcl":     "hcl",
+	".graphql": "graphql",
+	".gql":     "graphql",
+}
+
+// interpreters maps shebang interpreters to their language
+var interpreters = map[string]string{
+	"sh":      "shell",
+	"bash":    "shell",
+	"zsh":     "shell",
+	"ksh":     "shell",
+	"dash":    "shell",
+	"python":  "python",
+	"node":    "javascript",
+	"deno":    "typescript",
+	"bun":     "javascript",
+	"ts-node": "typescript",
+	"ruby":    "ruby",
+	"perl":    "perl",
+	"php":     "php",
+	"lua":     "lua",
+	"rscript": "r",
+	"pwsh":    "powershell",
+}
+
+// heuristic scores a language by how many of its patterns a snippet matches
+type heuristic struct {
+	language string
+	patterns []*regexp.Regexp
+}
+
+// heuristics are tried in order; the language matching the most patterns
+// wins, and earlier languages win ties
+var heuristics = []heuristic{
+	{"php", compile(`<\?php`)},
+	{"go", compile(
+		`(?m)^package \w+\s*$`,
+		`(?m)^func (\(\w+ \*?\w+(\[[\w, ]+\])?\) )?\w+(\[[^\]]*\])?\(`,
+		`(?m)^import \($`,
+		`\w+ := `,
+		`\bif err != nil \{`,
+	)},
+	{"rust", compile(
+		`\bfn \w+(<[^>]*>)?\(.*\)( -> [^{]+)? \{`,
+		`\blet mut \w+`,
+		`(?m)^use \w+(::[\w{}*, ]+)+;`,
+		`(?m)^\s*(pub )?(impl|trait|enum|struct) \w+.*\{`,
+		`\w+!\(`,
+	)},
+	{"python", compile(
+		`(?m)^\s*(async )?def \w+\(.*\)( -> .+)?:\s*$`,
+		`(?m)^(from [\w.]+ )?import [\w.]+( as \w+)?(, [\w.]+)*\s*$`,
+		`(?m)^\s*class \w+(\(.*\))?:\s*$`,
+		`\bself\.\w+`,
+		`(?m)^if __name__ == ['"]__main__['"]:`,
+		`(?m)^\s*(elif|except|finally)\b.*:\s*$`,
+	)},
+	{"typescript", compile(
+		`\w\)?: (string|number|boolean|void|any|unknown|never)\b`,
+		`(?m)^\s*(export )?(interface \w+( extends [\w, <>]+)? \{|type \w+(<[^>]*>)? = )`,
+		`\bas (const|string|number|unknown)\b`,
+		`(?m)^\s*(public|private|protected|readonly) \w+(\?)?: `,
+		`(?m)^import .* from ['"][^'"]+['"];?$`,
+	)},
+	{"javascript", compile(
+		`(?m)^\s*(const|let|var) \w+ = `,
+		`\bfunction\*?\s*\w*\s*\([^)]*\)\s*\{`,
+		`\) => \{?`,
+		`\brequire\(['"][^'"]+['"]\)`,
+		`(?m)^import .* from ['"][^'"]+['"];?$`,
+		`\bconsole\.(log|error|warn)\(`,
+		`\bmodule\.exports\b`,
+	)},
+	{"csharp", compile(
+		`(?m)^using System(\.\w+)*;`,
+		`(?m)^\s*namespace [\w.]+\s*[{;]?\s*$`,
+		`\bConsole\.Write(Line)?\(`,
+		`\{ get; (private )?(set|init); \}`,
+		`\bpublic (async )?(Task|void|string|int|bool)(<[^>]+>)? \w+\(`,
+	)},
+	{"java", compile(
+		`(?m)^package [\w.]+;`,
+		`(?m)^import (static )?[\w.]+(\.\*)?;`,
+		`\b(public|private|protected) (static )?(final )?(class|interface|enum|record) \w+`,
+		`\bSystem\.(out|err)\.print`,
+		`@Override\b`,
+	)},
+	{"c", compile(
+		`(?m)^#include [<"][\w/.]+\.h[>"]`,
+		`\b(printf|fprintf|malloc|free|memcpy|strlen)\(`,
+		`(?m)^(static )?(int|void|char|unsigned|struct \w+) \*?\w+\([^)]*\)\s*\{?$`,
+		`(?m)^#define \w+`,
+	)},
+	{"cpp", compile(
+		`(?m)^#include <(iostream|vector|string|map|memory|algorithm|unordered_map)>`,
+		`\bstd::\w+`,
+		`(?m)^\s*template\s*<`,
+		`(?m)^using namespace \w+;`,
+		`\b(class|namespace) \w+\s*\{`,
+	)},
+	{"ruby", compile(
+		`(?m)^\s*def (self\.)?\w+[?!]?(\(.*\))?\s*$`,
+		`(?m)^\s*end\s*$`,
+		`(?m)^require(_relative)? ['"]`,
+		`(?m)^\s*(module|class) [A-Z]\w*( < [A-Z][\w:]*)?\s*$`,
+		`\bputs\b`,
+		`\bdo \|\w+(, \w+)*\|`,
+	)},
+	{"shell", compile(
+		`(?m)^\s*(if|while|until) \[\[? `,
+		`(?m)^\s*(fi|done|esac)\s*$`,
+		`(?m)^\s*(echo|export|local|set -\w+) `,
+		`(?m)^\s*\w+\(\)\s*\{`,
+		`"\$\{?\w+\}?"`,
+	)},
+	{"sql", compile(
+		`(?is)\bselect\b.+\bfrom\b`,
+		`(?i)\b(insert into|create (table|index|view)|update \w+ set|delete from|alter table)\b`,
+		`(?i)\b(where|group by|order by|inner join|left join)\b`,
+	)},
+	{"html", compile(
+		`(?i)<!doctype html|<html[\s>]`,
+		`(?i)</(div|span|body|head|p|ul|li|a|script)>`,
+	)},
+}
+
+// compile compiles heuristic patterns
+func compile(patterns ...string) []*regexp.Regexp {
+	compiled := make([]*regexp.Regexp, len(patterns))
+	for i, pattern := range patterns {
+		compiled[i] = regexp.MustCompile(pattern)
+	}
+	return compiled
+}
+
+// maxHeuristicBytes bounds the content scanned by the heuristics
+const maxHeuristicBytes = 64 << 10
+
+// Detect returns the language of a file or snippet, or "" when it cannot be
+// told. The file name decides when it is known; otherwise a shebang line and
+// then content heuristics are used. Path may be empty for snippets.
+func Detect(filePath, content string) string {
+	if language := FromPath(filePath); language != "" {
+		if strings.EqualFold(path.Ext(filePath), ".h") && isCppHeader(content) {
+			return "cpp"
+		}
+		return language
+	}
+	return FromContent(content)
+}
+
+// FromPath returns the language of a file from its name and extension, or ""
+func FromPath(filePath string) string {
+	if filePath == "" {
+		return ""
+	}
+	name := strings.ToLower(path.Base(strings.ReplaceAll(filePath, "\\", "/")))
+	if language, ok := fileNames[name]; ok {
+		return language
+	}
+	if strings.HasPrefix(name, "dockerfile.") || strings.HasSuffix(name, ".dockerfile") {
+		return "dockerfile"
+	}
+	return extensions[path.Ext(name)]
+}
+
+// FromContent returns the language of a snippet from its shebang line or
+// content heuristics, or ""
+func FromContent(content string) string {
+	if language := fromShebang(content); language != "" {
+		return language
+	}
+
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return ""
+	}
+	if len(content) > maxHeuristicBytes {
+		content = content[:maxHeuristicBytes]
+	}
+	if (content[0] == '{' || content[0] == '[') && json.Valid([]byte(content)) {
+		return "json"
+	}
+
+	best, bestScore := "", 0
+	for _, h := range heuristics {
+		score := 0
+		for _, pattern := range h.patterns {
+			if pattern.MatchString(content) {
+				score++
+			}
+		}
+		if score > bestScore {
+			best, bestScore = h.language, score
+		}
+	}
+	return best
+}
+
+// fromShebang returns the language of the interpreter named on a #! line, or ""
+func fromShebang(content string) string {
+	if !strings.HasPrefix(content, "#!") {
+		return ""
+	}
+	line, _, _ := strings.Cut(content[2:], "\n")
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return ""
+	}
+
+	interpreter := path.Base(fields[0])
+	if interpreter == "env" {
+		interpreter = ""
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") {
+				interpreter = field
+				break
+			}
+		}
+	}
+
+	// python3.12 and similar name the language with a version
+	interpreter = strings.ToLower(strings.TrimRight(interpreter, "0123456789."))
+	return interpreters[interpreter]
+}
+
+// isCppHeader reports whether a .h file holds C++ rather than C
+func isCppHeader(content string) bool {
+	for _, h := range heuristics {
+		if h.language != "cpp" {
+			continue
+		}
+		for _, pattern := range h.patterns {
+			if pattern.MatchString(content) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -3,6 +3,7 @@ package logging
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"strings"
@@ -13,6 +14,11 @@ var Logger *slog.Logger
 
 // Init initializes the structured logger with JSON format
 func Init(level string) {
+	InitWriter(level, os.Stdout)
+}
+
+// InitWriter initializes the structured logger with JSON format, writing to w
+func InitWriter(level string, w io.Writer) {
 	var logLevel slog.Level
 	switch level {
 	case "debug":
@@ -31,7 +37,7 @@ func Init(level string) {
 		Level: logLevel,
 	}
 
-	Logger = slog.New(slog.NewJSONHandler(os.Stdout, opts))
+	Logger = slog.New(slog.NewJSONHandler(w, opts))
 }
 
 // WithFields returns a logger with additional fields
@@ -4,22 +4,44 @@ import (
 	"context"
 	"encoding/json"
 
+	"github.com/dshills/mcp-pr/internal/config"
+	"github.com/dshills/mcp-pr/internal/history"
 	"github.com/dshills/mcp-pr/internal/review"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
+// Version is the server version reported to MCP clients
+const Version = "1.0.0"
+
+// reviewOptionsSchema holds the properties shared by all review tools
+const reviewOptionsSchema = `
+				"fail_on": {"type": "string", "enum": ["critical", "high", "medium", "low", "info", "none"], "description": "Severity at or above which the verdict is fail; none always passes (default from .mcp-pr.yaml or server configuration, otherwise none)"},
+				"instructions": {"type": "string", "maxLength": 4000, "description": "Extra review guidance for this request, such as house style rules; added after team and repository instructions"}`
+
+// pathFiltersSchema holds the path filter properties of tools that read git diffs
+const pathFiltersSchema = `
+				"include_paths": {"type": "array", "items": {"type": "string"}, "description": "Glob patterns of files to review (e.g. internal/**/*.go); empty reviews all files"},
+				"exclude_paths": {"type": "array", "items": {"type": "string"}, "description": "Glob patterns of files to skip (e.g. vendor/**, *.pb.go)"},
+				"use_default_excludes": {"type": "boolean", "default": true, "description": "Skip lockfiles, minified assets and generated code"}`
+
+// gitOptionsSchema holds the properties shared by git review tools
+const gitOptionsSchema = pathFiltersSchema + `,
+				"context_lines": {"type": "integer", "minimum": 0, "description": "Lines of surrounding source to include around each change when no enclosing function is found (0 disables source context; default from server configuration)"},` + reviewOptionsSchema
+
 // Server represents the MCP code review server
 type Server struct {
 	mcpServer *mcp.Server
 	engine    *review.Engine
+	config    *config.Config
+	history   *history.Store // nil when review history is disabled
 }
 
-// NewServer creates a new MCP server
-func NewServer(engine *review.Engine) (*Server, error) {
+// NewServer creates a new MCP server; store may be nil when review history is disabled
+func NewServer(engine *review.Engine, cfg *config.Config, store *history.Store) (*Server, error) {
 	// Create MCP server implementation
 	impl := &mcp.Implementation{
 		Name:    "mcp-code-review",
-		Version: "1.0.0",
+		Version: Version,
 	}
 
 	opts := &mcp.ServerOptions{
@@ -31,6 +53,8 @@ func NewServer(engine *review.Engine) (*Server, error) {
 	srv := &Server{
 		mcpServer: mcpServer,
 		engine:    engine,
+		config:    cfg,
+		history:   store,
 	}
 
 	// Register tools
@@ -49,12 +73,12 @@ func (s *Server) registerTools() {
 			"type": "object",
 			"properties": {
 				"code": {"type": "string", "description": "Code to review"},
-				"language": {"type": "string", "description": "Programming language (e.g., go, python, javascript)"},
+				"language": {"type": "string", "description": "Programming language (e.g., go, python, javascript); detected from the code when omitted"},
 				"provider": {"type": "string", "enum": ["anthropic", "openai", "google"], "description": "LLM provider to use"},
 				"review_depth": {"type": "string", "enum": ["quick", "thorough"], "default": "quick", "description": "Review depth"},
-				"focus_areas": {"type": "array", "items": {"type": "string", "enum": ["bug", "security", "performance", "style", "best-practice"]}, "description": "Specific areas to focus on"}
+				"focus_areas": {"type": "array", "items": {"type": "string", "enum": ["bug", "security", "performance", "style", "best-practice"]}, "description": "Specific areas to focus on"},` + reviewOptionsSchema + `
 			},
-			"required": ["code", "language"]
+			"required": ["code"]
 		}`),
 	}, s.handleReviewCode)
 
@@ -67,7 +91,7 @@ func (s *Server) registerTools() {
 			"properties": {
 				"repository_path": {"type": "string", "description": "Path to git repository"},
 				"provider": {"type": "string", "enum": ["anthropic", "openai", "google"], "description": "LLM provider to use"},
-				"review_depth": {"type": "string", "enum": ["quick", "thorough"], "default": "quick", "description": "Review depth"}
+				"review_depth": {"type": "string", "enum": ["quick", "thorough"], "default": "quick", "description": "Review depth"},` + gitOptionsSchema + `
 			},
 			"required": ["repository_path"]
 		}`),
@@ -82,7 +106,7 @@ func (s *Server) registerTools() {
 			"properties": {
 				"repository_path": {"type": "string", "description": "Path to git repository"},
 				"provider": {"type": "string", "enum": ["anthropic", "openai", "google"], "description": "LLM provider to use"},
-				"review_depth": {"type": "string", "enum": ["quick", "thorough"], "default": "quick", "description": "Review depth"}
+				"review_depth": {"type": "string", "enum": ["quick", "thorough"], "default": "quick", "description": "Review depth"},` + gitOptionsSchema + `
 			},
 			"required": ["repository_path"]
 		}`),
@@ -97,12 +121,262 @@ func (s *Server) registerTools() {
 			"properties": {
 				"repository_path": {"type": "string", "description": "Path to git repository"},
 				"commit_sha": {"type": "string", "description": "Git commit SHA to review"},
+				"review_message": {"type": "boolean", "default": false, "description": "Also check the commit message against the diff: does it describe the change, follow Conventional Commits and mark breaking changes? Message findings have file_path COMMIT_MSG"},
 				"provider": {"type": "string", "enum": ["anthropic", "openai", "google"], "description": "LLM provider to use"},
-				"review_depth": {"type": "string", "enum": ["quick", "thorough"], "default": "quick", "description": "Review depth"}
+				"review_depth": {"type": "string", "enum": ["quick", "thorough"], "default": "quick", "description": "Review depth"},` + gitOptionsSchema + `
 			},
 			"required": ["repository_path", "commit_sha"]
 		}`),
 	}, s.handleReviewCommit)
+
+	// Register review_commits tool
+	s.mcpServer.AddTool(&mcp.Tool{
+		Name:        "review_commits",
+		Description: "Review each commit of a range (e.g. main..HEAD) separately, with a roll-up of verdicts and findings. Merge commits are skipped unless include_merges is set",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"repository_path": {"type": "string", "description": "Path to git repository"},
+				"range": {"type": "string", "description": "Revision range as accepted by git rev-list, e.g. main..HEAD (at most 50 commits)"},
+				"include_merges": {"type": "boolean", "default": false, "description": "Review merge commits as their diff against the first parent"},
+				"review_message": {"type": "boolean", "default": false, "description": "Also check each commit message against its diff"},
+				"concurrency": {"type": "integer", "minimum": 1, "maximum": 8, "default": 3, "description": "Commits reviewed at once"},
+				"provider": {"type": "string", "enum": ["anthropic", "openai", "google"], "description": "LLM provider to use"},
+				"review_depth": {"type": "string", "enum": ["quick", "thorough"], "default": "quick", "description": "Review depth"},` + gitOptionsSchema + `
+			},
+			"required": ["repository_path", "range"]
+		}`),
+	}, s.handleReviewCommits)
+
+	// Register draft_commit_message tool
+	s.mcpServer.AddTool(&mcp.Tool{
+		Name:        "draft_commit_message",
+		Description: "Draft a Conventional Commits message for the staged changes using the configured provider. Secrets are redacted from the diff before it is sent",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"repository_path": {"type": "string", "description": "Path to git repository"},
+				"provider": {"type": "string", "enum": ["anthropic", "openai", "google"], "description": "LLM provider to use"},
+				"instructions": {"type": "string", "maxLength": 4000, "description": "Extra guidance, e.g. the ticket to reference"},` + pathFiltersSchema + `
+			},
+			"required": ["repository_path"]
+		}`),
+	}, s.handleDraftCommitMessage)
+
+	// Register draft_pr_description tool
+	s.mcpServer.AddTool(&mcp.Tool{
+		Name:        "draft_pr_description",
+		Description: "Draft a pull request description for the changes from base to head: title, summary, risk areas, testing notes, a changelog entry and a ready-to-paste markdown body",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"repository_path": {"type": "string", "description": "Path to git repository"},
+				"base": {"type": "string", "description": "Branch or commit the pull request merges into, e.g. main"},
+				"head": {"type": "string", "default": "HEAD", "description": "Branch or commit with the changes"},
+				"provider": {"type": "string", "enum": ["anthropic", "openai", "google"], "description": "LLM provider to use"},
+				"instructions": {"type": "string", "maxLength": 4000, "description": "Extra guidance, e.g. the issue the pull request closes"},` + pathFiltersSchema + `
+			},
+			"required": ["repository_path", "base"]
+		}`),
+	}, s.handleDraftPRDescription)
+
+	// Register review_patch tool
+	s.mcpServer.AddTool(&mcp.Tool{
+		Name:        "review_patch",
+		Description: "Review a unified diff or an mbox of patches (git format-patch, mailing lists, CI artifacts) without a repository checkout",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"patch": {"type": "string", "description": "Unified diff or mbox text"},
+				"patch_path": {"type": "string", "description": "Path to a .patch, .diff or .mbox file, used when patch is empty"},
+				"provider": {"type": "string", "enum": ["anthropic", "openai", "google"], "description": "LLM provider to use"},
+				"review_depth": {"type": "string", "enum": ["quick", "thorough"], "default": "quick", "description": "Review depth"},` + pathFiltersSchema + `,` + reviewOptionsSchema + `
+			}
+		}`),
+	}, s.handleReviewPatch)
+
+	// Register review_files tool
+	s.mcpServer.AddTool(&mcp.Tool{
+		Name:        "review_files",
+		Description: "Audit the current contents of whole files or directories, not just changes. Large selections are reviewed in chunks of whole files and findings carry the file path and line number in the file",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"repository_path": {"type": "string", "description": "Path to git repository"},
+				"paths": {"type": "array", "items": {"type": "string"}, "description": "Files, directories or globs relative to the repository (e.g. internal/auth, **/*.sql); \".\" selects every file. Ignored files are never selected"},
+				"concurrency": {"type": "integer", "minimum": 1, "maximum": 8, "default": 3, "description": "Chunks reviewed at once"},
+				"provider": {"type": "string", "enum": ["anthropic", "openai", "google"], "description": "LLM provider to use"},
+				"review_depth": {"type": "string", "enum": ["quick", "thorough"], "default": "quick", "description": "Review depth"},` + pathFiltersSchema + `,` + reviewOptionsSchema + `
+			},
+			"required": ["repository_path", "paths"]
+		}`),
+	}, s.handleReviewFiles)
+
+	// Register review_working_tree tool
+	s.mcpServer.AddTool(&mcp.Tool{
+		Name:        "review_working_tree",
+		Description: "Review all local changes in a repository: staged, unstaged, and untracked (non-ignored) files",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"repository_path": {"type": "string", "description": "Path to git repository"},
+				"provider": {"type": "string", "enum": ["anthropic", "openai", "google"], "description": "LLM provider to use"},
+				"review_depth": {"type": "string", "enum": ["quick", "thorough"], "default": "quick", "description": "Review depth"},
+				"include_staged": {"type": "boolean", "default": true, "description": "Include staged changes"},
+				"include_unstaged": {"type": "boolean", "default": true, "description": "Include unstaged changes to tracked files"},
+				"include_untracked": {"type": "boolean", "default": true, "description": "Include untracked files not excluded by .gitignore"},` + gitOptionsSchema + `
+			},
+			"required": ["repository_path"]
+		}`),
+	}, s.handleReviewWorkingTree)
+
+	// Register write_baseline tool
+	s.mcpServer.AddTool(&mcp.Tool{
+		Name:        "write_baseline",
+		Description: "Review git changes and record the findings in the repository baseline (.mcp-pr-baseline.json) so later reviews mark them as suppressed; entries for files outside the review are kept",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"repository_path": {"type": "string", "description": "Path to git repository"},
+				"source_type": {"type": "string", "enum": ["staged", "unstaged", "commit", "working_tree"], "default": "working_tree", "description": "Changes to review"},
+				"commit_sha": {"type": "string", "description": "Git commit SHA when source_type is commit"},
+				"provider": {"type": "string", "enum": ["anthropic", "openai", "google"], "description": "LLM provider to use"},
+				"review_depth": {"type": "string", "enum": ["quick", "thorough"], "default": "quick", "description": "Review depth"},` + gitOptionsSchema + `
+			},
+			"required": ["repository_path"]
+		}`),
+	}, s.handleWriteBaseline)
+
+	// Register review_diff_since_last tool
+	s.mcpServer.AddTool(&mcp.Tool{
+		Name:        "review_diff_since_last",
+		Description: "Review git changes and report only what changed since the previous review of the same branch: new, regressed and fixed findings",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"repository_path": {"type": "string", "description": "Path to git repository"},
+				"source_type": {"type": "string", "enum": ["staged", "unstaged", "commit", "working_tree"], "default": "working_tree", "description": "Changes to review"},
+				"commit_sha": {"type": "string", "description": "Git commit SHA when source_type is commit"},
+				"provider": {"type": "string", "enum": ["anthropic", "openai", "google"], "description": "LLM provider to use"},
+				"review_depth": {"type": "string", "enum": ["quick", "thorough"], "default": "quick", "description": "Review depth"},` + gitOptionsSchema + `
+			},
+			"required": ["repository_path"]
+		}`),
+	}, s.handleReviewDiffSinceLast)
+
+	// Register list_reviews tool
+	s.mcpServer.AddTool(&mcp.Tool{
+		Name:        "list_reviews",
+		Description: "List past reviews from the history store, newest first, filtered by repository, branch and time range",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"repository_path": {"type": "string", "description": "Only reviews of this repository (any path inside it)"},
+				"branch": {"type": "string", "description": "Only reviews made on this branch"},
+				"since": {"type": "string", "description": "Only reviews at or after this time: RFC 3339, YYYY-MM-DD, or an age such as 36h or 7d"},
+				"until": {"type": "string", "description": "Only reviews before this time, in the same formats as since"},
+				"limit": {"type": "integer", "minimum": 1, "default": 50, "description": "Maximum number of reviews"}
+			}
+		}`),
+	}, s.handleListReviews)
+
+	// Register get_review tool
+	s.mcpServer.AddTool(&mcp.Tool{
+		Name:        "get_review",
+		Description: "Get a past review from the history store, including its findings",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"id": {"type": "string", "description": "Review ID from list_reviews or metadata.history_id"}
+			},
+			"required": ["id"]
+		}`),
+	}, s.handleGetReview)
+
+	// Register triage_finding tool
+	s.mcpServer.AddTool(&mcp.Tool{
+		Name:        "triage_finding",
+		Description: "Record a team decision about a finding of a stored review. False positives and won't-fix findings are suppressed in later reviews of the repository",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"review_id": {"type": "string", "description": "Review ID from metadata.history_id or list_reviews"},
+				"fingerprint": {"type": "string", "description": "Fingerprint of the finding"},
+				"state": {"type": "string", "enum": ["accepted", "false-positive", "wont-fix", "fixed"], "description": "Triage state"},
+				"note": {"type": "string", "maxLength": 2000, "description": "Optional reason shown with the finding in later reviews"}
+			},
+			"required": ["review_id", "fingerprint", "state"]
+		}`),
+	}, s.handleTriageFinding)
+
+	// Register triage_stats tool
+	s.mcpServer.AddTool(&mcp.Tool{
+		Name:        "triage_stats",
+		Description: "Aggregate triage feedback and false-positive rates per provider, model and category",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"repository_path": {"type": "string", "description": "Only reviews of this repository (any path inside it)"},
+				"since": {"type": "string", "description": "Only reviews at or after this time: RFC 3339, YYYY-MM-DD, or an age such as 36h or 7d"},
+				"until": {"type": "string", "description": "Only reviews before this time, in the same formats as since"}
+			}
+		}`),
+	}, s.handleTriageStats)
+
+	// Register suggest_fix tool
+	s.mcpServer.AddTool(&mcp.Tool{
+		Name:        "suggest_fix",
+		Description: "Ask the provider for a concrete fix of a stored finding, or of an issue at a file and line range, and return it as a unified diff checked with git apply --check against the working tree",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"repository_path": {"type": "string", "description": "Path to git repository"},
+				"review_id": {"type": "string", "description": "Review containing the finding, from metadata.history_id"},
+				"fingerprint": {"type": "string", "description": "Fingerprint of the finding to fix"},
+				"file_path": {"type": "string", "description": "File relative to the repository root, when not fixing a stored finding"},
+				"start_line": {"type": "integer", "minimum": 1, "description": "First line of the issue"},
+				"end_line": {"type": "integer", "minimum": 1, "description": "Last line of the issue (default start_line)"},
+				"issue": {"type": "string", "description": "What to fix; with a stored finding, extra guidance for the fix"},
+				"provider": {"type": "string", "enum": ["anthropic", "openai", "google"], "description": "LLM provider to use"},
+				"apply": {"type": "boolean", "default": false, "description": "Write the patch to the working tree"}
+			},
+			"required": ["repository_path"]
+		}`),
+	}, s.handleSuggestFix)
+
+	// Register ask_about_review tool
+	s.mcpServer.AddTool(&mcp.Tool{
+		Name:        "ask_about_review",
+		Description: "Ask a follow-up question about a stored review. The provider and model that made the review answer with its code, findings and earlier questions as context. Requires the server to keep reviewed code (MCP_PR_HISTORY_CODE=true)",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"review_id": {"type": "string", "description": "Review ID from metadata.history_id or list_reviews"},
+				"question": {"type": "string", "maxLength": 4000, "description": "Question, e.g. why is finding 2 a bug?"}
+			},
+			"required": ["review_id", "question"]
+		}`),
+	}, s.handleAskAboutReview)
+
+	// Register list_providers tool
+	s.mcpServer.AddTool(&mcp.Tool{
+		Name:        "list_providers",
+		Description: "List configured LLM providers, their models, and which one is the default",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {}
+		}`),
+	}, s.handleListProviders)
+
+	// Register server_status tool
+	s.mcpServer.AddTool(&mcp.Tool{
+		Name:        "server_status",
+		Description: "Report server version, providers, effective limits, and masked credential status",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {}
+		}`),
+	}, s.handleServerStatus)
 }
 
 // Run runs the server on stdin/stdout
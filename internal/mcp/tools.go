@@ -3,8 +3,12 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/dshills/mcp-pr/internal/credentials"
+	"github.com/dshills/mcp-pr/internal/history"
 	"github.com/dshills/mcp-pr/internal/logging"
 	"github.com/dshills/mcp-pr/internal/review"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -16,29 +20,29 @@ func (s *Server) handleReviewCode(ctx context.Context, req *mcp.CallToolRequest)
 
 	// Parse arguments
 	var args struct {
-		Code        string   `json:"code"`
-		Language    string   `json:"language"`
-		Provider    string   `json:"provider,omitempty"`
-		ReviewDepth string   `json:"review_depth,omitempty"`
-		FocusAreas  []string `json:"focus_areas,omitempty"`
+		Code         string   `json:"code"`
+		Language     string   `json:"language,omitempty"`
+		Provider     string   `json:"provider,omitempty"`
+		ReviewDepth  string   `json:"review_depth,omitempty"`
+		FocusAreas   []string `json:"focus_areas,omitempty"`
+		Instructions string   `json:"instructions,omitempty"`
+		FailOn       string   `json:"fail_on,omitempty"`
 	}
 
 	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
 		return nil, fmt.Errorf("failed to parse arguments: %w", err)
 	}
 
-	if args.ReviewDepth == "" {
-		args.ReviewDepth = "quick"
-	}
-
 	// Build review request
 	reviewReq := review.Request{
-		SourceType:  "arbitrary",
-		Code:        args.Code,
-		Provider:    args.Provider,
-		Language:    args.Language,
-		ReviewDepth: args.ReviewDepth,
-		FocusAreas:  args.FocusAreas,
+		SourceType:   "arbitrary",
+		Code:         args.Code,
+		Provider:     args.Provider,
+		Language:     args.Language,
+		ReviewDepth:  args.ReviewDepth,
+		FocusAreas:   args.FocusAreas,
+		Instructions: args.Instructions,
+		FailOn:       args.FailOn,
 	}
 
 	// Perform review
@@ -52,7 +56,7 @@ func (s *Server) handleReviewCode(ctx context.Context, req *mcp.CallToolRequest)
 	}
 
 	// Format response as JSON content
-	jsonData, err := json.MarshalIndent(formatReviewResponse(resp), "", "  ")
+	jsonData, err := json.MarshalIndent(FormatReviewResponse(resp), "", "  ")
 	if err != nil {
 		return nil, fmt.Errorf("failed to format response: %w", err)
 	}
@@ -80,8 +84,10 @@ func (s *Server) handleReviewCommit(ctx context.Context, req *mcp.CallToolReques
 	var args struct {
 		RepositoryPath string `json:"repository_path"`
 		CommitSHA      string `json:"commit_sha"`
+		ReviewMessage  bool   `json:"review_message,omitempty"`
 		Provider       string `json:"provider,omitempty"`
 		ReviewDepth    string `json:"review_depth,omitempty"`
+		gitOptionsArgs
 	}
 
 	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
@@ -103,19 +109,17 @@ func (s *Server) handleReviewCommit(ctx context.Context, req *mcp.CallToolReques
 		}, nil
 	}
 
-	if args.ReviewDepth == "" {
-		args.ReviewDepth = "quick"
-	}
-
 	// Build review request
 	reviewReq := review.Request{
 		SourceType:     "commit",
 		RepositoryPath: args.RepositoryPath,
 		CommitSHA:      args.CommitSHA,
+		ReviewMessage:  args.ReviewMessage,
 		Provider:       args.Provider,
 		ReviewDepth:    args.ReviewDepth,
 		Language:       "diff",
 	}
+	args.applyTo(&reviewReq)
 
 	// Perform review (engine will populate Code from git)
 	resp, err := s.engine.Review(ctx, reviewReq)
@@ -128,7 +132,7 @@ func (s *Server) handleReviewCommit(ctx context.Context, req *mcp.CallToolReques
 	}
 
 	// Format response as JSON content
-	jsonData, err := json.MarshalIndent(formatReviewResponse(resp), "", "  ")
+	jsonData, err := json.MarshalIndent(FormatReviewResponse(resp), "", "  ")
 	if err != nil {
 		return nil, fmt.Errorf("failed to format response: %w", err)
 	}
@@ -138,11 +142,408 @@ func (s *Server) handleReviewCommit(ctx context.Context, req *mcp.CallToolReques
 	}, nil
 }
 
-// formatReviewResponse formats the review response for output
-func formatReviewResponse(resp *review.Response) map[string]interface{} {
-	// Convert findings to map format
-	findings := make([]map[string]interface{}, len(resp.Findings))
-	for i, f := range resp.Findings {
+// handleReviewCommits handles the review_commits tool request
+func (s *Server) handleReviewCommits(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logging.Info(ctx, "Handling review_commits request")
+
+	// Parse arguments
+	var args struct {
+		RepositoryPath string `json:"repository_path"`
+		Range          string `json:"range"`
+		IncludeMerges  bool   `json:"include_merges,omitempty"`
+		ReviewMessage  bool   `json:"review_message,omitempty"`
+		Concurrency    int    `json:"concurrency,omitempty"`
+		Provider       string `json:"provider,omitempty"`
+		ReviewDepth    string `json:"review_depth,omitempty"`
+		gitOptionsArgs
+	}
+
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		return nil, fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	// Validate required arguments
+	if args.RepositoryPath == "" || args.Range == "" {
+		return errorResult("repository_path and range are required"), nil
+	}
+
+	commitsReq := review.CommitsRequest{
+		Request: review.Request{
+			RepositoryPath: args.RepositoryPath,
+			ReviewMessage:  args.ReviewMessage,
+			Provider:       args.Provider,
+			ReviewDepth:    args.ReviewDepth,
+			Language:       "diff",
+		},
+		Range:         args.Range,
+		IncludeMerges: args.IncludeMerges,
+		Concurrency:   args.Concurrency,
+	}
+	args.applyTo(&commitsReq.Request)
+
+	resp, err := s.engine.ReviewCommits(ctx, commitsReq)
+	if err != nil {
+		logging.Error(ctx, "Commit range review failed", "error", err)
+		return errorResult(fmt.Sprintf("Review failed: %v", err)), nil
+	}
+
+	return jsonToolResult(FormatCommitsResponse(resp))
+}
+
+// FormatCommitsResponse formats a commit range review: the roll-up followed
+// by each commit, oldest first, with its review in the usual format
+func FormatCommitsResponse(resp *review.CommitsResponse) map[string]interface{} {
+	commits := make([]map[string]interface{}, 0, len(resp.Commits))
+	for _, c := range resp.Commits {
+		commit := map[string]interface{}{
+			"sha":     c.SHA,
+			"subject": c.Subject,
+			"merge":   c.Merge,
+		}
+		switch {
+		case c.Skipped != "":
+			commit["skipped"] = c.Skipped
+		case c.Error != "":
+			commit["error"] = c.Error
+		default:
+			commit["review"] = FormatReviewResponse(c.Response)
+		}
+		commits = append(commits, commit)
+	}
+
+	return map[string]interface{}{
+		"range":         resp.Range,
+		"verdict":       resp.Verdict,
+		"summary":       resp.Summary,
+		"counts":        resp.Counts,
+		"reviewed":      resp.Reviewed,
+		"skipped":       resp.Skipped,
+		"errors":        resp.Errors,
+		"input_tokens":  resp.InputTokens,
+		"output_tokens": resp.OutputTokens,
+		"duration_ms":   resp.Duration.Milliseconds(),
+		"commits":       commits,
+	}
+}
+
+// handleDraftCommitMessage handles the draft_commit_message tool request
+func (s *Server) handleDraftCommitMessage(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logging.Info(ctx, "Handling draft_commit_message request")
+
+	// Parse arguments
+	var args struct {
+		RepositoryPath     string   `json:"repository_path"`
+		Provider           string   `json:"provider,omitempty"`
+		Instructions       string   `json:"instructions,omitempty"`
+		IncludePaths       []string `json:"include_paths,omitempty"`
+		ExcludePaths       []string `json:"exclude_paths,omitempty"`
+		UseDefaultExcludes *bool    `json:"use_default_excludes,omitempty"`
+	}
+
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		return nil, fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	// Validate required arguments
+	if args.RepositoryPath == "" {
+		return errorResult("repository_path is required"), nil
+	}
+
+	draft, err := s.engine.DraftCommitMessage(ctx, review.Request{
+		RepositoryPath:     args.RepositoryPath,
+		Provider:           args.Provider,
+		Instructions:       args.Instructions,
+		IncludePaths:       args.IncludePaths,
+		ExcludePaths:       args.ExcludePaths,
+		UseDefaultExcludes: args.UseDefaultExcludes,
+	})
+	if err != nil {
+		logging.Error(ctx, "Commit message draft failed", "error", err)
+		return errorResult(fmt.Sprintf("Draft failed: %v", err)), nil
+	}
+
+	return jsonToolResult(draft)
+}
+
+// handleDraftPRDescription handles the draft_pr_description tool request
+func (s *Server) handleDraftPRDescription(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logging.Info(ctx, "Handling draft_pr_description request")
+
+	// Parse arguments
+	var args struct {
+		RepositoryPath     string   `json:"repository_path"`
+		Base               string   `json:"base"`
+		Head               string   `json:"head,omitempty"`
+		Provider           string   `json:"provider,omitempty"`
+		Instructions       string   `json:"instructions,omitempty"`
+		IncludePaths       []string `json:"include_paths,omitempty"`
+		ExcludePaths       []string `json:"exclude_paths,omitempty"`
+		UseDefaultExcludes *bool    `json:"use_default_excludes,omitempty"`
+	}
+
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		return nil, fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	// Validate required arguments
+	if args.RepositoryPath == "" || args.Base == "" {
+		return errorResult("repository_path and base are required"), nil
+	}
+
+	desc, err := s.engine.DraftPRDescription(ctx, review.Request{
+		RepositoryPath:     args.RepositoryPath,
+		BaseRef:            args.Base,
+		HeadRef:            args.Head,
+		Provider:           args.Provider,
+		Instructions:       args.Instructions,
+		IncludePaths:       args.IncludePaths,
+		ExcludePaths:       args.ExcludePaths,
+		UseDefaultExcludes: args.UseDefaultExcludes,
+	})
+	if err != nil {
+		logging.Error(ctx, "PR description draft failed", "error", err)
+		return errorResult(fmt.Sprintf("Draft failed: %v", err)), nil
+	}
+
+	return jsonToolResult(desc)
+}
+
+// handleReviewPatch handles the review_patch tool request
+func (s *Server) handleReviewPatch(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logging.Info(ctx, "Handling review_patch request")
+
+	// Parse arguments
+	var args struct {
+		Patch              string   `json:"patch,omitempty"`
+		PatchPath          string   `json:"patch_path,omitempty"`
+		Provider           string   `json:"provider,omitempty"`
+		ReviewDepth        string   `json:"review_depth,omitempty"`
+		IncludePaths       []string `json:"include_paths,omitempty"`
+		ExcludePaths       []string `json:"exclude_paths,omitempty"`
+		UseDefaultExcludes *bool    `json:"use_default_excludes,omitempty"`
+		Instructions       string   `json:"instructions,omitempty"`
+		FailOn             string   `json:"fail_on,omitempty"`
+	}
+
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		return nil, fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	// Validate required arguments
+	if args.Patch == "" && args.PatchPath == "" {
+		return errorResult("patch or patch_path is required"), nil
+	}
+
+	resp, err := s.engine.Review(ctx, review.Request{
+		SourceType:         "patch",
+		Code:               args.Patch,
+		PatchPath:          args.PatchPath,
+		Provider:           args.Provider,
+		ReviewDepth:        args.ReviewDepth,
+		Language:           "diff",
+		IncludePaths:       args.IncludePaths,
+		ExcludePaths:       args.ExcludePaths,
+		UseDefaultExcludes: args.UseDefaultExcludes,
+		Instructions:       args.Instructions,
+		FailOn:             args.FailOn,
+	})
+	if err != nil {
+		logging.Error(ctx, "Review failed", "error", err)
+		return errorResult(fmt.Sprintf("Review failed: %v", err)), nil
+	}
+
+	return jsonToolResult(FormatReviewResponse(resp))
+}
+
+// handleReviewFiles handles the review_files tool request
+func (s *Server) handleReviewFiles(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logging.Info(ctx, "Handling review_files request")
+
+	// Parse arguments
+	var args struct {
+		RepositoryPath     string   `json:"repository_path"`
+		Paths              []string `json:"paths"`
+		Concurrency        int      `json:"concurrency,omitempty"`
+		Provider           string   `json:"provider,omitempty"`
+		ReviewDepth        string   `json:"review_depth,omitempty"`
+		IncludePaths       []string `json:"include_paths,omitempty"`
+		ExcludePaths       []string `json:"exclude_paths,omitempty"`
+		UseDefaultExcludes *bool    `json:"use_default_excludes,omitempty"`
+		Instructions       string   `json:"instructions,omitempty"`
+		FailOn             string   `json:"fail_on,omitempty"`
+	}
+
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		return nil, fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	// Validate required arguments
+	if args.RepositoryPath == "" || len(args.Paths) == 0 {
+		return errorResult("repository_path and paths are required"), nil
+	}
+
+	resp, err := s.engine.ReviewFiles(ctx, review.FilesRequest{
+		Request: review.Request{
+			RepositoryPath:     args.RepositoryPath,
+			Paths:              args.Paths,
+			Provider:           args.Provider,
+			ReviewDepth:        args.ReviewDepth,
+			Language:           "diff",
+			IncludePaths:       args.IncludePaths,
+			ExcludePaths:       args.ExcludePaths,
+			UseDefaultExcludes: args.UseDefaultExcludes,
+			Instructions:       args.Instructions,
+			FailOn:             args.FailOn,
+		},
+		Concurrency: args.Concurrency,
+	})
+	if err != nil {
+		logging.Error(ctx, "File review failed", "error", err)
+		return errorResult(fmt.Sprintf("Review failed: %v", err)), nil
+	}
+
+	return jsonToolResult(FormatFilesResponse(resp))
+}
+
+// FormatFilesResponse formats a file review: the combined findings and
+// verdict of every chunk, with the files reviewed and skipped
+func FormatFilesResponse(resp *review.FilesResponse) map[string]interface{} {
+	chunkErrors := make([]map[string]interface{}, 0, len(resp.Errors))
+	for _, e := range resp.Errors {
+		chunkErrors = append(chunkErrors, map[string]interface{}{
+			"files": e.Files,
+			"error": e.Error,
+		})
+	}
+
+	result := map[string]interface{}{
+		"verdict":       resp.Verdict,
+		"summary":       resp.Summary,
+		"counts":        resp.Counts,
+		"findings":      formatFindings(resp.Findings),
+		"files":         resp.Files,
+		"chunks":        resp.Chunks,
+		"errors":        chunkErrors,
+		"input_tokens":  resp.InputTokens,
+		"output_tokens": resp.OutputTokens,
+		"duration_ms":   resp.Duration.Milliseconds(),
+	}
+	if len(resp.Skipped) > 0 {
+		result["skipped_files"] = resp.Skipped
+	}
+	if len(resp.TooLarge) > 0 {
+		result["too_large_files"] = resp.TooLarge
+	}
+	return result
+}
+
+// handleReviewWorkingTree handles the review_working_tree tool request
+func (s *Server) handleReviewWorkingTree(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logging.Info(ctx, "Handling review_working_tree request")
+
+	// Parse arguments
+	var args struct {
+		RepositoryPath   string `json:"repository_path"`
+		Provider         string `json:"provider,omitempty"`
+		ReviewDepth      string `json:"review_depth,omitempty"`
+		IncludeStaged    *bool  `json:"include_staged,omitempty"`
+		IncludeUnstaged  *bool  `json:"include_unstaged,omitempty"`
+		IncludeUntracked *bool  `json:"include_untracked,omitempty"`
+		gitOptionsArgs
+	}
+
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		return nil, fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	// Validate required arguments
+	if args.RepositoryPath == "" {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{&mcp.TextContent{Text: "repository_path is required"}},
+		}, nil
+	}
+
+	// Build review request (all categories are included unless explicitly disabled)
+	reviewReq := review.Request{
+		SourceType:       "working_tree",
+		RepositoryPath:   args.RepositoryPath,
+		Provider:         args.Provider,
+		ReviewDepth:      args.ReviewDepth,
+		Language:         "diff",
+		IncludeStaged:    boolOrDefault(args.IncludeStaged, true),
+		IncludeUnstaged:  boolOrDefault(args.IncludeUnstaged, true),
+		IncludeUntracked: boolOrDefault(args.IncludeUntracked, true),
+	}
+	args.applyTo(&reviewReq)
+
+	// Perform review (engine will populate Code from git)
+	resp, err := s.engine.Review(ctx, reviewReq)
+	if err != nil {
+		logging.Error(ctx, "Review failed", "error", err)
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Review failed: %v", err)}},
+		}, nil
+	}
+
+	return jsonToolResult(FormatReviewResponse(resp))
+}
+
+// gitOptionsArgs are the arguments shared by git review tools
+type gitOptionsArgs struct {
+	IncludePaths       []string `json:"include_paths,omitempty"`
+	ExcludePaths       []string `json:"exclude_paths,omitempty"`
+	UseDefaultExcludes *bool    `json:"use_default_excludes,omitempty"`
+	ContextLines       *int     `json:"context_lines,omitempty"`
+	Instructions       string   `json:"instructions,omitempty"`
+	FailOn             string   `json:"fail_on,omitempty"`
+}
+
+// applyTo copies the shared git options into a review request
+func (a gitOptionsArgs) applyTo(req *review.Request) {
+	req.IncludePaths = a.IncludePaths
+	req.ExcludePaths = a.ExcludePaths
+	req.UseDefaultExcludes = a.UseDefaultExcludes
+	req.ContextLines = a.ContextLines
+	req.Instructions = a.Instructions
+	req.FailOn = a.FailOn
+}
+
+// boolOrDefault returns the value of an optional boolean argument, or def when it is unset
+func boolOrDefault(value *bool, def bool) bool {
+	if value == nil {
+		return def
+	}
+	return *value
+}
+
+// FormatReviewResponse formats the review response for output; the CLI uses
+// the same format so every front end reports identical results
+func FormatReviewResponse(resp *review.Response) map[string]interface{} {
+	result := map[string]interface{}{
+		"findings":    formatFindings(resp.Findings),
+		"summary":     resp.Summary,
+		"provider":    resp.Provider,
+		"duration_ms": resp.Duration.Milliseconds(),
+		"verdict":     resp.Verdict,
+		"counts":      resp.Counts,
+	}
+	if len(resp.Fixed) > 0 {
+		result["fixed"] = formatFindings(resp.Fixed)
+	}
+
+	if resp.Metadata != nil {
+		result["metadata"] = formatMetadata(resp.Metadata)
+	}
+
+	return result
+}
+
+// formatFindings converts findings to map format
+func formatFindings(list []review.Finding) []map[string]interface{} {
+	findings := make([]map[string]interface{}, len(list))
+	for i, f := range list {
 		finding := map[string]interface{}{
 			"category":    f.Category,
 			"severity":    f.Severity,
@@ -162,41 +563,97 @@ func formatReviewResponse(resp *review.Response) map[string]interface{} {
 			finding["code_snippet"] = f.CodeSnippet
 		}
 
-		findings[i] = finding
-	}
-
-	result := map[string]interface{}{
-		"findings":    findings,
-		"summary":     resp.Summary,
-		"provider":    resp.Provider,
-		"duration_ms": resp.Duration.Milliseconds(),
-	}
-
-	if resp.Metadata != nil {
-		metadata := map[string]interface{}{
-			"source_type": resp.Metadata.SourceType,
+		if f.Source != "" {
+			finding["source"] = f.Source
 		}
 
-		if resp.Metadata.Model != "" {
-			metadata["model"] = resp.Metadata.Model
+		if f.Fingerprint != "" {
+			finding["fingerprint"] = f.Fingerprint
 		}
-		if resp.Metadata.FileCount > 0 {
-			metadata["file_count"] = resp.Metadata.FileCount
+
+		if f.Suppressed != "" {
+			finding["suppressed"] = f.Suppressed
 		}
-		if resp.Metadata.LineCount > 0 {
-			metadata["line_count"] = resp.Metadata.LineCount
+
+		if f.Status != "" {
+			finding["status"] = f.Status
 		}
-		if resp.Metadata.LinesAdded > 0 {
-			metadata["lines_added"] = resp.Metadata.LinesAdded
+
+		if f.Triage != "" {
+			finding["triage"] = f.Triage
 		}
-		if resp.Metadata.LinesRemoved > 0 {
-			metadata["lines_removed"] = resp.Metadata.LinesRemoved
+
+		if f.TriageNote != "" {
+			finding["triage_note"] = f.TriageNote
 		}
 
-		result["metadata"] = metadata
+		findings[i] = finding
 	}
+	return findings
+}
 
-	return result
+// formatMetadata converts review metadata to map format
+func formatMetadata(meta *review.Metadata) map[string]interface{} {
+	metadata := map[string]interface{}{
+		"source_type": meta.SourceType,
+	}
+
+	if meta.Model != "" {
+		metadata["model"] = meta.Model
+	}
+	if meta.FileCount > 0 {
+		metadata["file_count"] = meta.FileCount
+	}
+	if meta.LineCount > 0 {
+		metadata["line_count"] = meta.LineCount
+	}
+	if meta.LinesAdded > 0 {
+		metadata["lines_added"] = meta.LinesAdded
+	}
+	if meta.LinesRemoved > 0 {
+		metadata["lines_removed"] = meta.LinesRemoved
+	}
+	if len(meta.SkippedFiles) > 0 {
+		metadata["skipped_files"] = meta.SkippedFiles
+	}
+	if len(meta.Patches) > 0 {
+		metadata["patches"] = meta.Patches
+	}
+	if len(meta.Languages) > 0 {
+		metadata["languages"] = meta.Languages
+	}
+	if meta.RedactedSecrets > 0 {
+		metadata["redacted_secrets"] = meta.RedactedSecrets
+	}
+	if meta.Blocked {
+		metadata["blocked"] = true
+	}
+	if meta.BaselineFile != "" {
+		metadata["baseline_file"] = meta.BaselineFile
+	}
+	if meta.InputTokens > 0 {
+		metadata["input_tokens"] = meta.InputTokens
+	}
+	if meta.OutputTokens > 0 {
+		metadata["output_tokens"] = meta.OutputTokens
+	}
+	if meta.HistoryID != "" {
+		metadata["history_id"] = meta.HistoryID
+	}
+	if meta.PreviousReviewID != "" {
+		metadata["previous_review_id"] = meta.PreviousReviewID
+	}
+	if meta.Settings != nil {
+		metadata["settings"] = meta.Settings
+	}
+	if len(meta.Analyzers) > 0 {
+		metadata["analyzers"] = meta.Analyzers
+	}
+	if len(meta.UnavailableAnalyzers) > 0 {
+		metadata["unavailable_analyzers"] = meta.UnavailableAnalyzers
+	}
+
+	return metadata
 }
 
 // handleGitReview is a helper function for git-based review operations (staged, unstaged)
@@ -208,6 +665,7 @@ func (s *Server) handleGitReview(ctx context.Context, req *mcp.CallToolRequest,
 		RepositoryPath string `json:"repository_path"`
 		Provider       string `json:"provider,omitempty"`
 		ReviewDepth    string `json:"review_depth,omitempty"`
+		gitOptionsArgs
 	}
 
 	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
@@ -222,10 +680,6 @@ func (s *Server) handleGitReview(ctx context.Context, req *mcp.CallToolRequest,
 		}, nil
 	}
 
-	if args.ReviewDepth == "" {
-		args.ReviewDepth = "quick"
-	}
-
 	// Build review request
 	reviewReq := review.Request{
 		SourceType:     sourceType,
@@ -234,6 +688,7 @@ func (s *Server) handleGitReview(ctx context.Context, req *mcp.CallToolRequest,
 		ReviewDepth:    args.ReviewDepth,
 		Language:       "diff",
 	}
+	args.applyTo(&reviewReq)
 
 	// Perform review (engine will populate Code from git)
 	resp, err := s.engine.Review(ctx, reviewReq)
@@ -246,7 +701,516 @@ func (s *Server) handleGitReview(ctx context.Context, req *mcp.CallToolRequest,
 	}
 
 	// Format response as JSON content
-	jsonData, err := json.MarshalIndent(formatReviewResponse(resp), "", "  ")
+	jsonData, err := json.MarshalIndent(FormatReviewResponse(resp), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to format response: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(jsonData)}},
+	}, nil
+}
+
+// sourceReviewArgs are the arguments of tools that review any git source
+type sourceReviewArgs struct {
+	RepositoryPath string `json:"repository_path"`
+	SourceType     string `json:"source_type,omitempty"`
+	CommitSHA      string `json:"commit_sha,omitempty"`
+	Provider       string `json:"provider,omitempty"`
+	ReviewDepth    string `json:"review_depth,omitempty"`
+	gitOptionsArgs
+}
+
+// request builds the review request, defaulting to the working tree (which
+// includes every category of change)
+func (a sourceReviewArgs) request() review.Request {
+	if a.SourceType == "" {
+		a.SourceType = "working_tree"
+	}
+	reviewReq := review.Request{
+		SourceType:       a.SourceType,
+		RepositoryPath:   a.RepositoryPath,
+		CommitSHA:        a.CommitSHA,
+		Provider:         a.Provider,
+		ReviewDepth:      a.ReviewDepth,
+		Language:         "diff",
+		IncludeStaged:    true,
+		IncludeUnstaged:  true,
+		IncludeUntracked: true,
+	}
+	a.applyTo(&reviewReq)
+	return reviewReq
+}
+
+// handleWriteBaseline handles the write_baseline tool request
+func (s *Server) handleWriteBaseline(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logging.Info(ctx, "Handling write_baseline request")
+
+	// Parse arguments
+	var args sourceReviewArgs
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		return nil, fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	// Validate required arguments
+	if args.RepositoryPath == "" {
+		return errorResult("repository_path is required"), nil
+	}
+
+	resp, err := s.engine.WriteBaseline(ctx, args.request())
+	if err != nil {
+		logging.Error(ctx, "Baseline failed", "error", err)
+		return errorResult(fmt.Sprintf("Baseline failed: %v", err)), nil
+	}
+
+	return jsonToolResult(FormatReviewResponse(resp))
+}
+
+// handleReviewDiffSinceLast handles the review_diff_since_last tool request
+func (s *Server) handleReviewDiffSinceLast(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logging.Info(ctx, "Handling review_diff_since_last request")
+
+	// Parse arguments
+	var args sourceReviewArgs
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		return nil, fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	// Validate required arguments
+	if args.RepositoryPath == "" {
+		return errorResult("repository_path is required"), nil
+	}
+	if s.history == nil {
+		return errorResult(errHistoryDisabled), nil
+	}
+
+	resp, err := s.engine.Review(ctx, args.request())
+	if err != nil {
+		logging.Error(ctx, "Review failed", "error", err)
+		return errorResult(fmt.Sprintf("Review failed: %v", err)), nil
+	}
+
+	return jsonToolResult(FormatReviewChanges(resp))
+}
+
+// FormatReviewChanges formats only what changed since the previous review of
+// the branch: new and regressed findings that are not suppressed, and fixed
+// findings. Findings that are still present are only counted.
+func FormatReviewChanges(resp *review.Response) map[string]interface{} {
+	var added, regressed []review.Finding
+	for _, f := range resp.Findings {
+		if f.Suppressed != "" {
+			continue
+		}
+		// Findings without a status could not be compared and count as new
+		switch f.Status {
+		case review.StatusStillPresent:
+		case review.StatusRegressed:
+			regressed = append(regressed, f)
+		default:
+			added = append(added, f)
+		}
+	}
+
+	result := map[string]interface{}{
+		"new":           formatFindings(added),
+		"regressed":     formatFindings(regressed),
+		"fixed":         formatFindings(resp.Fixed),
+		"still_present": resp.Counts.ByStatus[review.StatusStillPresent],
+		"summary":       resp.Summary,
+		"provider":      resp.Provider,
+		"verdict":       resp.Verdict,
+		"counts":        resp.Counts,
+	}
+	if resp.Metadata != nil {
+		result["metadata"] = formatMetadata(resp.Metadata)
+	}
+
+	return result
+}
+
+// handleListReviews handles the list_reviews tool request
+func (s *Server) handleListReviews(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logging.Info(ctx, "Handling list_reviews request")
+
+	// Parse arguments
+	var args struct {
+		RepositoryPath string `json:"repository_path,omitempty"`
+		Branch         string `json:"branch,omitempty"`
+		Since          string `json:"since,omitempty"`
+		Until          string `json:"until,omitempty"`
+		Limit          int    `json:"limit,omitempty"`
+	}
+
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		return nil, fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	if s.history == nil {
+		return errorResult(errHistoryDisabled), nil
+	}
+
+	query := history.Query{
+		Repository: args.RepositoryPath,
+		Branch:     args.Branch,
+		Limit:      args.Limit,
+	}
+	now := time.Now()
+	var err error
+	if args.Since != "" {
+		if query.Since, err = history.ParseTime(args.Since, now); err != nil {
+			return errorResult(err.Error()), nil
+		}
+	}
+	if args.Until != "" {
+		if query.Until, err = history.ParseTime(args.Until, now); err != nil {
+			return errorResult(err.Error()), nil
+		}
+	}
+
+	records, err := s.history.List(query)
+	if err != nil {
+		logging.Error(ctx, "Failed to list reviews", "error", err)
+		return errorResult(fmt.Sprintf("Failed to list reviews: %v", err)), nil
+	}
+
+	summaries := make([]history.Record, len(records))
+	for i, rec := range records {
+		summaries[i] = rec.Summary()
+	}
+
+	return jsonToolResult(map[string]interface{}{
+		"reviews": summaries,
+		"count":   len(summaries),
+	})
+}
+
+// handleGetReview handles the get_review tool request
+func (s *Server) handleGetReview(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logging.Info(ctx, "Handling get_review request")
+
+	// Parse arguments
+	var args struct {
+		ID string `json:"id"`
+	}
+
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		return nil, fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	if args.ID == "" {
+		return errorResult("id is required"), nil
+	}
+	if s.history == nil {
+		return errorResult(errHistoryDisabled), nil
+	}
+
+	rec, err := s.history.Get(args.ID)
+	if err != nil {
+		return errorResult(err.Error()), nil
+	}
+
+	return jsonToolResult(FormatHistoryRecord(rec))
+}
+
+// handleTriageFinding handles the triage_finding tool request
+func (s *Server) handleTriageFinding(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logging.Info(ctx, "Handling triage_finding request")
+
+	// Parse arguments
+	var args struct {
+		ReviewID    string `json:"review_id"`
+		Fingerprint string `json:"fingerprint"`
+		State       string `json:"state"`
+		Note        string `json:"note,omitempty"`
+	}
+
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		return nil, fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	if args.ReviewID == "" || args.Fingerprint == "" || args.State == "" {
+		return errorResult("review_id, fingerprint and state are required"), nil
+	}
+	if s.history == nil {
+		return errorResult(errHistoryDisabled), nil
+	}
+
+	triage, err := s.history.SetTriage(args.ReviewID, args.Fingerprint, args.State, args.Note)
+	if err != nil {
+		return errorResult(fmt.Sprintf("Triage failed: %v", err)), nil
+	}
+
+	return jsonToolResult(triage)
+}
+
+// handleTriageStats handles the triage_stats tool request
+func (s *Server) handleTriageStats(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logging.Info(ctx, "Handling triage_stats request")
+
+	// Parse arguments
+	var args struct {
+		RepositoryPath string `json:"repository_path,omitempty"`
+		Since          string `json:"since,omitempty"`
+		Until          string `json:"until,omitempty"`
+	}
+
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		return nil, fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	if s.history == nil {
+		return errorResult(errHistoryDisabled), nil
+	}
+
+	query := history.Query{Repository: args.RepositoryPath}
+	now := time.Now()
+	var err error
+	if args.Since != "" {
+		if query.Since, err = history.ParseTime(args.Since, now); err != nil {
+			return errorResult(err.Error()), nil
+		}
+	}
+	if args.Until != "" {
+		if query.Until, err = history.ParseTime(args.Until, now); err != nil {
+			return errorResult(err.Error()), nil
+		}
+	}
+
+	stats, err := s.history.Stats(query)
+	if err != nil {
+		logging.Error(ctx, "Failed to aggregate triage", "error", err)
+		return errorResult(fmt.Sprintf("Failed to aggregate triage: %v", err)), nil
+	}
+
+	return jsonToolResult(map[string]interface{}{
+		"groups": stats,
+		"count":  len(stats),
+	})
+}
+
+// handleSuggestFix handles the suggest_fix tool request
+func (s *Server) handleSuggestFix(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logging.Info(ctx, "Handling suggest_fix request")
+
+	// Parse arguments
+	var args struct {
+		RepositoryPath string `json:"repository_path"`
+		ReviewID       string `json:"review_id,omitempty"`
+		Fingerprint    string `json:"fingerprint,omitempty"`
+		FilePath       string `json:"file_path,omitempty"`
+		StartLine      int    `json:"start_line,omitempty"`
+		EndLine        int    `json:"end_line,omitempty"`
+		Issue          string `json:"issue,omitempty"`
+		Provider       string `json:"provider,omitempty"`
+		Apply          bool   `json:"apply,omitempty"`
+	}
+
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		return nil, fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	if args.RepositoryPath == "" {
+		return errorResult("repository_path is required"), nil
+	}
+
+	fixReq := review.FixRequest{
+		RepositoryPath: args.RepositoryPath,
+		FilePath:       args.FilePath,
+		StartLine:      args.StartLine,
+		EndLine:        args.EndLine,
+		Issue:          args.Issue,
+		Provider:       args.Provider,
+		Apply:          args.Apply,
+	}
+
+	// A stored finding supplies the location and issue
+	if args.ReviewID != "" || args.Fingerprint != "" {
+		if args.ReviewID == "" || args.Fingerprint == "" {
+			return errorResult("review_id and fingerprint must be given together"), nil
+		}
+		if s.history == nil {
+			return errorResult(errHistoryDisabled), nil
+		}
+		rec, err := s.history.Get(args.ReviewID)
+		if err != nil {
+			return errorResult(err.Error()), nil
+		}
+		finding, ok := rec.Finding(args.Fingerprint)
+		if !ok {
+			return errorResult(fmt.Sprintf("%v: %s", history.ErrFindingNotFound, args.Fingerprint)), nil
+		}
+		if finding.FilePath == "" || finding.Line == nil {
+			return errorResult("the finding has no file and line; pass file_path and start_line instead"), nil
+		}
+		fixReq.FilePath = finding.FilePath
+		fixReq.StartLine = *finding.Line
+		fixReq.EndLine = 0
+		fixReq.Issue = finding.Description
+		fixReq.Suggestion = finding.Suggestion
+		if args.Issue != "" {
+			fixReq.Suggestion = args.Issue
+		}
+	}
+
+	fix, err := s.engine.SuggestFix(ctx, fixReq)
+	if err != nil {
+		logging.Error(ctx, "Fix failed", "error", err)
+		return errorResult(fmt.Sprintf("Fix failed: %v", err)), nil
+	}
+
+	return jsonToolResult(fix)
+}
+
+// handleAskAboutReview handles the ask_about_review tool request
+func (s *Server) handleAskAboutReview(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logging.Info(ctx, "Handling ask_about_review request")
+
+	// Parse arguments
+	var args struct {
+		ReviewID string `json:"review_id"`
+		Question string `json:"question"`
+	}
+
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		return nil, fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	if args.ReviewID == "" || args.Question == "" {
+		return errorResult("review_id and question are required"), nil
+	}
+	if s.history == nil {
+		return errorResult(errHistoryDisabled), nil
+	}
+
+	thread, err := s.history.Thread(args.ReviewID)
+	if errors.Is(err, history.ErrNoThread) {
+		return errorResult(err.Error() + "; reviewed code is only kept when MCP_PR_HISTORY_CODE=true"), nil
+	}
+	if err != nil {
+		return errorResult(err.Error()), nil
+	}
+
+	gen, err := s.engine.Ask(ctx, thread, args.Question)
+	if err != nil {
+		logging.Error(ctx, "Question failed", "error", err)
+		return errorResult(fmt.Sprintf("Question failed: %v", err)), nil
+	}
+	if err := s.history.SaveThread(thread); err != nil {
+		logging.Warn(ctx, "Failed to save conversation", "error", err.Error())
+	}
+
+	return jsonToolResult(map[string]interface{}{
+		"answer":        gen.Text,
+		"review_id":     thread.ReviewID,
+		"turn":          len(thread.Messages) / 2,
+		"provider":      gen.Provider,
+		"model":         gen.Model,
+		"input_tokens":  gen.InputTokens,
+		"output_tokens": gen.OutputTokens,
+	})
+}
+
+// errHistoryDisabled explains why history tools fail when the store is off
+const errHistoryDisabled = "Review history is disabled (MCP_PR_HISTORY_DIR=none)"
+
+// errorResult returns a tool error with the given message
+func errorResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		IsError: true,
+		Content: []mcp.Content{&mcp.TextContent{Text: text}},
+	}
+}
+
+// FormatHistoryRecord formats a stored review, with its response in the
+// same format as the review tools
+func FormatHistoryRecord(rec *history.Record) map[string]interface{} {
+	result := map[string]interface{}{
+		"summary": rec.Summary(),
+	}
+	if rec.Response != nil {
+		result["review"] = FormatReviewResponse(rec.Response)
+	}
+	return result
+}
+
+// handleListProviders handles the list_providers tool request
+func (s *Server) handleListProviders(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logging.Info(ctx, "Handling list_providers request")
+
+	result := map[string]interface{}{
+		"providers":        s.engine.DescribeProviders(),
+		"default_provider": s.engine.DefaultProvider(),
+	}
+
+	return jsonToolResult(result)
+}
+
+// handleServerStatus handles the server_status tool request
+func (s *Server) handleServerStatus(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logging.Info(ctx, "Handling server_status request")
+
+	limits := map[string]interface{}{
+		"max_diff_size_bytes": s.engine.MaxDiffSize(),
+	}
+
+	credentialStatus := map[string]interface{}{}
+	configuration := map[string]interface{}{}
+
+	if s.config != nil {
+		limits["review_timeout"] = s.config.ReviewTimeout.String()
+		limits["context_lines"] = s.config.ContextLines
+		limits["context_token_budget"] = s.config.ContextTokenBudget
+		limits["go_context"] = s.config.GoContext
+		limits["analyzers"] = s.config.Analyzers
+		limits["analyzer_timeout"] = s.config.AnalyzerTimeout.String()
+		limits["secrets_action"] = s.config.SecretsAction
+		limits["rules_file"] = s.config.RulesFile
+		limits["instructions_file"] = s.config.InstructionsFile
+		limits["fail_on"] = s.config.FailOn
+		if s.history != nil {
+			limits["history_dir"] = s.history.Dir()
+			limits["history_code"] = s.history.KeepsCode()
+		} else {
+			limits["history_dir"] = "none"
+		}
+		limits["provider_timeouts"] = map[string]string{
+			"anthropic": s.config.AnthropicTimeout.String(),
+			"openai":    s.config.OpenAITimeout.String(),
+			"google":    s.config.GoogleTimeout.String(),
+		}
+
+		configuration["file"] = s.config.ConfigFile
+		configuration["sources"] = s.config.Sources
+
+		keys := map[string]string{
+			"anthropic": s.config.AnthropicAPIKey,
+			"openai":    s.config.OpenAIAPIKey,
+			"google":    s.config.GoogleAPIKey,
+		}
+		for name, key := range keys {
+			credentialStatus[name] = map[string]interface{}{
+				"configured": key != "",
+				"key":        credentials.MaskKey(key),
+			}
+		}
+	}
+
+	result := map[string]interface{}{
+		"version":          Version,
+		"default_provider": s.engine.DefaultProvider(),
+		"providers":        s.engine.DescribeProviders(),
+		"limits":           limits,
+		"credentials":      credentialStatus,
+		"configuration":    configuration,
+	}
+
+	return jsonToolResult(result)
+}
+
+// jsonToolResult formats a value as indented JSON tool content
+func jsonToolResult(v interface{}) (*mcp.CallToolResult, error) {
+	jsonData, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
 		return nil, fmt.Errorf("failed to format response: %w", err)
 	}
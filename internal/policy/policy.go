@@ -0,0 +1,139 @@
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the policy file looked up at the repository root
+const FileName = ".mcp-pr.yaml"
+
+// MaxInstructions bounds each source of custom instructions added to the prompt
+const MaxInstructions = 4000
+
+// Allowed values, matching the review tool schemas
+var (
+	validProviders  = []string{"anthropic", "openai", "google"}
+	validDepths     = []string{"quick", "thorough"}
+	validCategories = []string{"bug", "security", "performance", "style", "best-practice"}
+	validSeverities = []string{"critical", "high", "medium", "low", "info"}
+)
+
+// Policy holds per-repository review defaults. Unset fields leave the
+// server configuration in effect; tool arguments override both.
+type Policy struct {
+	Provider           string   `yaml:"provider"`
+	Model              string   `yaml:"model"` // Applies only when Provider is the provider used
+	ReviewDepth        string   `yaml:"review_depth"`
+	FocusAreas         []string `yaml:"focus_areas"`
+	IncludePaths       []string `yaml:"include_paths"`
+	ExcludePaths       []string `yaml:"exclude_paths"`
+	UseDefaultExcludes *bool    `yaml:"use_default_excludes"`
+	Instructions       string   `yaml:"instructions"`      // Extra guidance added to the review prompt
+	InstructionsFile   string   `yaml:"instructions_file"` // Repository-relative file of extra guidance, such as CONTRIBUTING.md
+	MinSeverity        string   `yaml:"min_severity"`      // Drop findings below this severity
+	FailOn             string   `yaml:"fail_on"`           // Severity at or above which reviews fail, or "none"
+	MaxDiffSize        int      `yaml:"max_diff_size"`
+}
+
+// Load reads the policy file at the root of a repository. It returns nil
+// without error when the repository has no policy file.
+func Load(root string) (*Policy, string, error) {
+	path := filepath.Join(root, FileName)
+
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is fixed relative to the repository root
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read %s: %w", FileName, err)
+	}
+
+	p, err := Parse(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid %s: %w", FileName, err)
+	}
+	return p, path, nil
+}
+
+// Parse decodes and validates a policy; unknown keys are rejected
+func Parse(data []byte) (*Policy, error) {
+	var p Policy
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&p); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Validate checks field values
+func (p *Policy) Validate() error {
+	if p.Provider != "" && !contains(validProviders, p.Provider) {
+		return fmt.Errorf("provider must be one of %v", validProviders)
+	}
+	if p.Model != "" && p.Provider == "" {
+		return errors.New("model requires provider")
+	}
+	if p.ReviewDepth != "" && !contains(validDepths, p.ReviewDepth) {
+		return fmt.Errorf("review_depth must be one of %v", validDepths)
+	}
+	for _, area := range p.FocusAreas {
+		if !contains(validCategories, area) {
+			return fmt.Errorf("focus_areas: %q is not one of %v", area, validCategories)
+		}
+	}
+	if p.MinSeverity != "" && !contains(validSeverities, p.MinSeverity) {
+		return fmt.Errorf("min_severity must be one of %v", validSeverities)
+	}
+	if p.FailOn != "" && p.FailOn != "none" && !contains(validSeverities, p.FailOn) {
+		return fmt.Errorf("fail_on must be one of %v or \"none\"", validSeverities)
+	}
+	if p.MaxDiffSize < 0 {
+		return errors.New("max_diff_size must not be negative")
+	}
+	if len(p.Instructions) > MaxInstructions {
+		return fmt.Errorf("instructions must be at most %d characters", MaxInstructions)
+	}
+	if p.InstructionsFile != "" && !filepath.IsLocal(p.InstructionsFile) {
+		return errors.New("instructions_file must be a path inside the repository")
+	}
+	return nil
+}
+
+// SeverityRank orders severities from most (0) to least severe; unknown values rank last
+func SeverityRank(severity string) int {
+	for i, s := range validSeverities {
+		if s == severity {
+			return i
+		}
+	}
+	return len(validSeverities)
+}
+
+// IsSeverity reports whether a value is a known severity
+func IsSeverity(severity string) bool {
+	return contains(validSeverities, severity)
+}
+
+// contains reports whether a slice contains a value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -11,6 +11,9 @@ import (
 	"github.com/dshills/mcp-pr/internal/review"
 )
 
+// anthropicModel is the Claude model used for reviews
+const anthropicModel = "claude-sonnet-4-5"
+
 // AnthropicProvider implements Provider for Anthropic Claude
 type AnthropicProvider struct {
 	client  *anthropic.Client
@@ -36,6 +39,7 @@ func (p *AnthropicProvider) Review(ctx context.Context, req review.Request) (*re
 
 	// Build prompt
 	prompt := buildReviewPrompt(req)
+	model := requestModel(req, anthropicModel)
 
 	// Create context with timeout
 	ctx, cancel := context.WithTimeout(ctx, p.timeout)
@@ -43,7 +47,7 @@ func (p *AnthropicProvider) Review(ctx context.Context, req review.Request) (*re
 
 	// Call Claude API
 	message, err := p.client.Messages.New(ctx, anthropic.MessageNewParams{
-		Model:     anthropic.ModelClaudeSonnet4_5, // Claude Sonnet 4.5
+		Model:     anthropic.Model(model),
 		MaxTokens: 4096,
 		Messages: []anthropic.MessageParam{
 			anthropic.NewUserMessage(anthropic.NewTextBlock(prompt)),
@@ -76,8 +80,10 @@ func (p *AnthropicProvider) Review(ctx context.Context, req review.Request) (*re
 		Provider: "anthropic",
 		Duration: duration,
 		Metadata: &review.Metadata{
-			SourceType: req.SourceType,
-			Model:      "claude-sonnet-4-5",
+			SourceType:   req.SourceType,
+			Model:        model,
+			InputTokens:  int(message.Usage.InputTokens),
+			OutputTokens: int(message.Usage.OutputTokens),
 		},
 	}, nil
 }
@@ -87,6 +93,11 @@ func (p *AnthropicProvider) Name() string {
 	return "anthropic"
 }
 
+// Model returns the model used for reviews
+func (p *AnthropicProvider) Model() string {
+	return anthropicModel
+}
+
 // IsAvailable checks if provider is configured
 func (p *AnthropicProvider) IsAvailable() bool {
 	return p.client != nil
@@ -109,7 +120,7 @@ Respond in JSON format with an array of findings:
   ],
   "summary": "Overall assessment"
 }
-
+` + buildPromptSections(req) + `
 Code to review:
 ` + "```" + req.Language + "\n" + req.Code + "\n```" + `
 
@@ -132,3 +143,50 @@ func parseReviewResponse(responseText string) ([]review.Finding, string) {
 
 	return resp.Findings, resp.Summary
 }
+
+// Generate answers a free-form prompt using Claude
+func (p *AnthropicProvider) Generate(ctx context.Context, req review.GenerateRequest) (*review.Generation, error) {
+	model := generateModel(req, anthropicModel)
+
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	params := anthropic.MessageNewParams{
+		Model:     anthropic.Model(model),
+		MaxTokens: int64(generateMaxTokens(req)),
+	}
+	for _, msg := range req.Messages {
+		if msg.Role == review.RoleAssistant {
+			params.Messages = append(params.Messages, anthropic.NewAssistantMessage(anthropic.NewTextBlock(msg.Content)))
+		} else {
+			params.Messages = append(params.Messages, anthropic.NewUserMessage(anthropic.NewTextBlock(msg.Content)))
+		}
+	}
+	params.Messages = append(params.Messages, anthropic.NewUserMessage(anthropic.NewTextBlock(req.Prompt)))
+	if req.System != "" {
+		params.System = []anthropic.TextBlockParam{{Text: req.System}}
+	}
+
+	message, err := p.client.Messages.New(ctx, params)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("anthropic API call timed out after %v", p.timeout)
+		}
+		return nil, fmt.Errorf("anthropic API error: %w", err)
+	}
+
+	var text string
+	for _, block := range message.Content {
+		if block.Type == "text" {
+			text += block.Text
+		}
+	}
+
+	return &review.Generation{
+		Text:         text,
+		Model:        model,
+		InputTokens:  int(message.Usage.InputTokens),
+		OutputTokens: int(message.Usage.OutputTokens),
+	}, nil
+}
@@ -11,6 +11,9 @@ import (
 	"google.golang.org/api/option"
 )
 
+// googleModel is the Gemini model used for reviews
+const googleModel = "gemini-2.5-flash"
+
 // GoogleProvider implements Provider for Google Gemini
 type GoogleProvider struct {
 	client  *genai.Client
@@ -39,16 +42,14 @@ func (p *GoogleProvider) Review(ctx context.Context, req review.Request) (*revie
 
 	// Build prompt
 	prompt := buildGoogleReviewPrompt(req)
+	model := requestModel(req, googleModel)
 
 	// Create context with timeout
 	ctx, cancel := context.WithTimeout(ctx, p.timeout)
 	defer cancel()
 
-	// Get Gemini model
-	model := p.client.GenerativeModel("gemini-2.5-flash")
-
 	// Call Gemini API
-	resp, err := model.GenerateContent(ctx, genai.Text(prompt))
+	resp, err := p.client.GenerativeModel(model).GenerateContent(ctx, genai.Text(prompt))
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
 			return nil, fmt.Errorf("google API call timed out after %v", p.timeout)
@@ -71,15 +72,21 @@ func (p *GoogleProvider) Review(ctx context.Context, req review.Request) (*revie
 
 	duration := time.Since(start)
 
+	metadata := &review.Metadata{
+		SourceType: req.SourceType,
+		Model:      model,
+	}
+	if resp.UsageMetadata != nil {
+		metadata.InputTokens = int(resp.UsageMetadata.PromptTokenCount)
+		metadata.OutputTokens = int(resp.UsageMetadata.CandidatesTokenCount)
+	}
+
 	return &review.Response{
 		Findings: findings,
 		Summary:  summary,
 		Provider: "google",
 		Duration: duration,
-		Metadata: &review.Metadata{
-			SourceType: req.SourceType,
-			Model:      "gemini-2.5-flash",
-		},
+		Metadata: metadata,
 	}, nil
 }
 
@@ -88,11 +95,64 @@ func (p *GoogleProvider) Name() string {
 	return "google"
 }
 
+// Model returns the model used for reviews
+func (p *GoogleProvider) Model() string {
+	return googleModel
+}
+
 // IsAvailable checks if provider is configured
 func (p *GoogleProvider) IsAvailable() bool {
 	return p.client != nil
 }
 
+// Generate answers a free-form prompt using Gemini
+func (p *GoogleProvider) Generate(ctx context.Context, req review.GenerateRequest) (*review.Generation, error) {
+	model := generateModel(req, googleModel)
+
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	generativeModel := p.client.GenerativeModel(model)
+	generativeModel.SetMaxOutputTokens(int32(generateMaxTokens(req))) //nolint:gosec // G115: token limits are small
+	if req.System != "" {
+		generativeModel.SystemInstruction = genai.NewUserContent(genai.Text(req.System))
+	}
+
+	chat := generativeModel.StartChat()
+	for _, msg := range req.Messages {
+		role := "user"
+		if msg.Role == review.RoleAssistant {
+			role = "model"
+		}
+		chat.History = append(chat.History, &genai.Content{Role: role, Parts: []genai.Part{genai.Text(msg.Content)}})
+	}
+
+	resp, err := chat.SendMessage(ctx, genai.Text(req.Prompt))
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("google API call timed out after %v", p.timeout)
+		}
+		return nil, fmt.Errorf("google API error: %w", err)
+	}
+
+	var text string
+	if len(resp.Candidates) > 0 && resp.Candidates[0].Content != nil {
+		for _, part := range resp.Candidates[0].Content.Parts {
+			if textPart, ok := part.(genai.Text); ok {
+				text += string(textPart)
+			}
+		}
+	}
+
+	gen := &review.Generation{Text: text, Model: model}
+	if resp.UsageMetadata != nil {
+		gen.InputTokens = int(resp.UsageMetadata.PromptTokenCount)
+		gen.OutputTokens = int(resp.UsageMetadata.CandidatesTokenCount)
+	}
+	return gen, nil
+}
+
 // Close closes the client connection
 func (p *GoogleProvider) Close() error {
 	if p.client != nil {
@@ -118,7 +178,7 @@ Respond in JSON format with an array of findings:
   ],
   "summary": "Overall assessment"
 }
-
+` + buildPromptSections(req) + `
 Code to review:
 ` + "```" + req.Language + "\n" + req.Code + "\n```" + `
 
@@ -10,6 +10,9 @@ import (
 	"github.com/openai/openai-go/option"
 )
 
+// openaiModel is the GPT model used for reviews
+const openaiModel = "gpt-5-mini"
+
 // OpenAIProvider implements Provider for OpenAI GPT
 type OpenAIProvider struct {
 	client  *openai.Client
@@ -36,6 +39,7 @@ func (p *OpenAIProvider) Review(ctx context.Context, req review.Request) (*revie
 	// Build system and user messages
 	systemPrompt := buildSystemPrompt()
 	userPrompt := buildUserPrompt(req)
+	model := requestModel(req, openaiModel)
 
 	// Create context with timeout
 	ctx, cancel := context.WithTimeout(ctx, p.timeout)
@@ -47,7 +51,7 @@ func (p *OpenAIProvider) Review(ctx context.Context, req review.Request) (*revie
 			openai.SystemMessage(systemPrompt),
 			openai.UserMessage(userPrompt),
 		},
-		Model: "gpt-5-mini", // GPT-5 Mini
+		Model: model,
 	})
 
 	if err != nil {
@@ -74,8 +78,10 @@ func (p *OpenAIProvider) Review(ctx context.Context, req review.Request) (*revie
 		Provider: "openai",
 		Duration: duration,
 		Metadata: &review.Metadata{
-			SourceType: req.SourceType,
-			Model:      "gpt-5-mini",
+			SourceType:   req.SourceType,
+			Model:        model,
+			InputTokens:  int(chatCompletion.Usage.PromptTokens),
+			OutputTokens: int(chatCompletion.Usage.CompletionTokens),
 		},
 	}, nil
 }
@@ -85,11 +91,62 @@ func (p *OpenAIProvider) Name() string {
 	return "openai"
 }
 
+// Model returns the model used for reviews
+func (p *OpenAIProvider) Model() string {
+	return openaiModel
+}
+
 // IsAvailable checks if provider is configured
 func (p *OpenAIProvider) IsAvailable() bool {
 	return p.client != nil
 }
 
+// Generate answers a free-form prompt using GPT
+func (p *OpenAIProvider) Generate(ctx context.Context, req review.GenerateRequest) (*review.Generation, error) {
+	model := generateModel(req, openaiModel)
+
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	var messages []openai.ChatCompletionMessageParamUnion
+	if req.System != "" {
+		messages = append(messages, openai.SystemMessage(req.System))
+	}
+	for _, msg := range req.Messages {
+		if msg.Role == review.RoleAssistant {
+			messages = append(messages, openai.AssistantMessage(msg.Content))
+		} else {
+			messages = append(messages, openai.UserMessage(msg.Content))
+		}
+	}
+	messages = append(messages, openai.UserMessage(req.Prompt))
+
+	chatCompletion, err := p.client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Messages:            messages,
+		Model:               model,
+		MaxCompletionTokens: openai.Int(int64(generateMaxTokens(req))),
+	})
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("openai API call timed out after %v", p.timeout)
+		}
+		return nil, fmt.Errorf("openai API error: %w", err)
+	}
+
+	var text string
+	if len(chatCompletion.Choices) > 0 {
+		text = chatCompletion.Choices[0].Message.Content
+	}
+
+	return &review.Generation{
+		Text:         text,
+		Model:        model,
+		InputTokens:  int(chatCompletion.Usage.PromptTokens),
+		OutputTokens: int(chatCompletion.Usage.CompletionTokens),
+	}, nil
+}
+
 // buildSystemPrompt creates the system message for GPT
 func buildSystemPrompt() string {
 	return `You are a code review assistant. Analyze code and identify issues.
@@ -111,7 +168,8 @@ Respond in JSON format with an array of findings:
 
 // buildUserPrompt creates the user message with code
 func buildUserPrompt(req review.Request) string {
-	prompt := `Code to review:
+	prompt := buildPromptSections(req) + `
+Code to review:
 ` + "```" + req.Language + "\n" + req.Code + "\n```" + `
 
 Review depth: ` + req.ReviewDepth
@@ -0,0 +1,58 @@
+package providers
+
+import (
+	"strings"
+
+	"github.com/dshills/mcp-pr/internal/review"
+)
+
+// buildPromptSections renders focus areas and the engine-supplied prompt sections, or "" if there are none
+func buildPromptSections(req review.Request) string {
+	if len(req.Sections) == 0 && len(req.FocusAreas) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	if len(req.FocusAreas) > 0 {
+		builder.WriteString("\nFocus on these categories: " + strings.Join(req.FocusAreas, ", ") + "\n")
+	}
+	for _, section := range req.Sections {
+		builder.WriteString("\n## " + section.Title + "\n")
+		if section.ReadOnly {
+			builder.WriteString("(Read-only context: use it to understand the changes, but only report findings on the code under review.)\n")
+		}
+		builder.WriteString(section.Content)
+		if !strings.HasSuffix(section.Content, "\n") {
+			builder.WriteString("\n")
+		}
+	}
+
+	return builder.String()
+}
+
+// requestModel returns the model requested for a review, or the provider default
+func requestModel(req review.Request, defaultModel string) string {
+	if req.Model != "" {
+		return req.Model
+	}
+	return defaultModel
+}
+
+// defaultMaxTokens bounds generated answers when the request sets no limit
+const defaultMaxTokens = 4096
+
+// generateModel returns the model requested for a generation, or the provider default
+func generateModel(req review.GenerateRequest, defaultModel string) string {
+	if req.Model != "" {
+		return req.Model
+	}
+	return defaultModel
+}
+
+// generateMaxTokens returns the output limit for a generation
+func generateMaxTokens(req review.GenerateRequest) int {
+	if req.MaxTokens > 0 {
+		return req.MaxTokens
+	}
+	return defaultMaxTokens
+}
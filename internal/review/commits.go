@@ -0,0 +1,208 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dshills/mcp-pr/internal/git"
+	"github.com/dshills/mcp-pr/internal/logging"
+)
+
+// Commit range limits
+const (
+	DefaultCommitConcurrency = 3  // Commits reviewed at once when a request does not choose
+	MaxCommitConcurrency     = 8  // Upper bound on concurrent commit reviews
+	MaxRangeCommits          = 50 // Commits a single range review may include
+)
+
+// Commit range errors
+var (
+	ErrMissingRange     = errors.New("range is required for commit range reviews")
+	ErrTooManyCommits   = fmt.Errorf("range contains more than %d commits; review a smaller range", MaxRangeCommits)
+	ErrEmptyCommitRange = errors.New("range contains no commits")
+	ErrOnlyMergeCommits = errors.New("range contains only merge commits; include merges to review them")
+)
+
+// Reasons a commit in a range was not reviewed
+const (
+	SkippedMerge = "merge" // Merge commits are skipped unless IncludeMerges is set
+)
+
+// CommitsRequest reviews each commit of a range separately
+type CommitsRequest struct {
+	Request              // Settings shared by every commit review: repository, provider, depth, filters
+	Range         string // Revision range, e.g. main..HEAD
+	IncludeMerges bool   // Review merge commits against their first parent instead of skipping them
+	Concurrency   int    // Commits reviewed at once (0 = DefaultCommitConcurrency)
+}
+
+// CommitResult is the outcome of one commit of a range
+type CommitResult struct {
+	SHA      string
+	Subject  string
+	Merge    bool
+	Skipped  string    // Reason the commit was not reviewed, e.g. SkippedMerge
+	Error    string    // Set when the review failed
+	Response *Response // Set when the review completed
+}
+
+// CommitsResponse holds per-commit results, oldest first, and a roll-up
+type CommitsResponse struct {
+	Range        string
+	Commits      []CommitResult
+	Verdict      string // VerdictFail when any reviewed commit failed or any review failed to run
+	Counts       Counts // Findings of all reviewed commits
+	Reviewed     int
+	Skipped      int
+	Errors       int
+	InputTokens  int
+	OutputTokens int
+	Summary      string
+	Duration     time.Duration
+}
+
+// ReviewCommits reviews every commit of a range on its own, with bounded
+// concurrency. A failed commit review is reported in its result and does not
+// stop the others.
+func (e *Engine) ReviewCommits(ctx context.Context, req CommitsRequest) (*CommitsResponse, error) {
+	start := time.Now()
+
+	if req.Range == "" {
+		return nil, ErrMissingRange
+	}
+	if req.RepositoryPath == "" {
+		return nil, ErrMissingRepository
+	}
+
+	commits, err := git.NewClient(req.RepositoryPath).RevListContext(ctx, req.Range)
+	if err != nil {
+		return nil, err
+	}
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrEmptyCommitRange, req.Range)
+	}
+	if len(commits) > MaxRangeCommits {
+		return nil, ErrTooManyCommits
+	}
+
+	concurrency := req.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultCommitConcurrency
+	}
+	concurrency = min(concurrency, MaxCommitConcurrency)
+
+	logging.Info(ctx, "Reviewing commit range",
+		"range", req.Range,
+		"commits", len(commits),
+		"concurrency", concurrency,
+	)
+
+	results := make([]CommitResult, len(commits))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, commit := range commits {
+		results[i] = CommitResult{SHA: commit.SHA, Subject: commit.Subject, Merge: commit.IsMerge()}
+		if commit.IsMerge() && !req.IncludeMerges {
+			results[i].Skipped = SkippedMerge
+			continue
+		}
+
+		wg.Add(1)
+		go func(result *CommitResult) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			commitReq := req.Request
+			commitReq.SourceType = "commit"
+			commitReq.CommitSHA = result.SHA
+			resp, err := e.Review(ctx, commitReq)
+			if err != nil {
+				logging.Warn(ctx, "Commit review failed", "commit", result.SHA, "error", err.Error())
+				result.Error = err.Error()
+				return
+			}
+			result.Response = resp
+		}(&results[i])
+	}
+	wg.Wait()
+
+	resp := rollUpCommits(req.Range, results)
+	if resp.Reviewed == 0 {
+		for _, result := range results {
+			if result.Error != "" {
+				return nil, fmt.Errorf("review failed for every commit: %s", result.Error)
+			}
+		}
+		return nil, fmt.Errorf("%w: %s", ErrOnlyMergeCommits, req.Range)
+	}
+	resp.Duration = time.Since(start)
+	return resp, nil
+}
+
+// rollUpCommits totals the findings, verdicts and token usage of a range
+func rollUpCommits(revRange string, results []CommitResult) *CommitsResponse {
+	resp := &CommitsResponse{
+		Range:   revRange,
+		Commits: results,
+		Verdict: VerdictPass,
+		Counts: Counts{
+			BySeverity: map[string]int{},
+			ByCategory: map[string]int{},
+		},
+	}
+
+	var failed []string
+	for _, result := range results {
+		switch {
+		case result.Skipped != "":
+			resp.Skipped++
+			continue
+		case result.Error != "":
+			resp.Errors++
+			continue
+		}
+
+		resp.Reviewed++
+		r := result.Response
+		if r.Verdict == VerdictFail {
+			resp.Verdict = VerdictFail
+			failed = append(failed, shortCommit(result.SHA))
+		}
+		addCounts(&resp.Counts, r.Counts)
+		if r.Metadata != nil {
+			resp.InputTokens += r.Metadata.InputTokens
+			resp.OutputTokens += r.Metadata.OutputTokens
+		}
+	}
+
+	var summary strings.Builder
+	fmt.Fprintf(&summary, "Reviewed %d of %d commits", resp.Reviewed, len(results))
+	if resp.Skipped > 0 {
+		fmt.Fprintf(&summary, ", skipped %d merge commits", resp.Skipped)
+	}
+	if resp.Errors > 0 {
+		// Commits that were not reviewed cannot pass
+		resp.Verdict = VerdictFail
+		fmt.Fprintf(&summary, ", %d reviews failed to run", resp.Errors)
+	}
+	summary.WriteString(". " + findingsSummary(resp.Counts))
+	if len(failed) > 0 {
+		fmt.Fprintf(&summary, " Failing commits: %s.", strings.Join(failed, ", "))
+	}
+	resp.Summary = summary.String()
+
+	return resp
+}
+
+// shortCommit abbreviates a commit SHA for summaries
+func shortCommit(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
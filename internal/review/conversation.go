@@ -0,0 +1,122 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MaxQuestion bounds a follow-up question about a review
+const MaxQuestion = 4000
+
+// Conversation errors
+var (
+	ErrEmptyQuestion   = errors.New("question is required")
+	ErrQuestionTooLong = fmt.Errorf("question must be at most %d characters", MaxQuestion)
+)
+
+// conversationPrompt introduces the stored review to the model
+const conversationPrompt = `You are the code reviewer who produced the review below. Answer follow-up questions about it.
+Findings are numbered in the order they were reported; users may refer to them by number.
+Explain your reasoning with reference to the code. If a finding is wrong or overstated, say so plainly.`
+
+// Thread is a follow-up conversation about a completed review. It keeps
+// the code as it was sent to the provider, with secrets redacted.
+type Thread struct {
+	ReviewID string    `json:"review_id"`
+	Provider string    `json:"provider"`
+	Model    string    `json:"model,omitempty"`
+	Context  string    `json:"context"`  // Reviewed code, prompt sections, findings and summary
+	Messages []Message `json:"messages"` // Questions and answers, oldest first
+}
+
+// NewThread starts a conversation about a completed review
+func NewThread(reviewID string, req Request, resp *Response) *Thread {
+	thread := &Thread{
+		ReviewID: reviewID,
+		Provider: resp.Provider,
+		Context:  reviewContext(req, resp),
+	}
+	if resp.Metadata != nil {
+		thread.Model = resp.Metadata.Model
+	}
+	return thread
+}
+
+// Ask continues a review conversation with the provider and model that made
+// the review, and appends the question and answer to the thread
+func (e *Engine) Ask(ctx context.Context, thread *Thread, question string) (*Generation, error) {
+	question = strings.TrimSpace(question)
+	if question == "" {
+		return nil, ErrEmptyQuestion
+	}
+	if len(question) > MaxQuestion {
+		return nil, ErrQuestionTooLong
+	}
+
+	gen, err := e.generate(ctx, thread.Provider, GenerateRequest{
+		System:   conversationPrompt + "\n\n" + thread.Context,
+		Messages: thread.Messages,
+		Prompt:   question,
+		Model:    thread.Model,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	thread.Messages = append(thread.Messages,
+		Message{Role: RoleUser, Content: question},
+		Message{Role: RoleAssistant, Content: gen.Text},
+	)
+	return gen, nil
+}
+
+// reviewContext renders the reviewed code, prompt sections and numbered findings
+func reviewContext(req Request, resp *Response) string {
+	var builder strings.Builder
+
+	builder.WriteString("## Code under review\n")
+	builder.WriteString("```" + req.Language + "\n" + req.Code)
+	if !strings.HasSuffix(req.Code, "\n") {
+		builder.WriteString("\n")
+	}
+	builder.WriteString("```\n")
+
+	for _, section := range req.Sections {
+		builder.WriteString("\n## " + section.Title + "\n")
+		builder.WriteString(section.Content)
+		if !strings.HasSuffix(section.Content, "\n") {
+			builder.WriteString("\n")
+		}
+	}
+
+	builder.WriteString("\n## Your findings\n")
+	if len(resp.Findings) == 0 {
+		builder.WriteString("None.\n")
+	}
+	for i, f := range resp.Findings {
+		var location string
+		switch {
+		case f.FilePath != "" && f.Line != nil:
+			location = fmt.Sprintf(" %s:%d", f.FilePath, *f.Line)
+		case f.FilePath != "":
+			location = " " + f.FilePath
+		case f.Line != nil:
+			location = fmt.Sprintf(" line %d", *f.Line)
+		}
+		fmt.Fprintf(&builder, "%d. [%s] %s%s: %s\n", i+1, f.Severity, f.Category, location, f.Description)
+		if f.Suggestion != "" {
+			fmt.Fprintf(&builder, "   Suggestion: %s\n", f.Suggestion)
+		}
+		if f.Source != "" {
+			fmt.Fprintf(&builder, "   Reported by: %s\n", f.Source)
+		}
+	}
+
+	if resp.Summary != "" {
+		builder.WriteString("\n## Your summary\n" + resp.Summary + "\n")
+	}
+
+	return builder.String()
+}
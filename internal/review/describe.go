@@ -0,0 +1,171 @@
+package review
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dshills/mcp-pr/internal/git"
+	"github.com/dshills/mcp-pr/internal/secrets"
+)
+
+// maxLogCommits bounds the commit subjects included in a PR description prompt
+const maxLogCommits = 100
+
+// ErrNoRangeChanges is returned when a range has nothing to describe
+var ErrNoRangeChanges = errors.New("no changes between base and head")
+
+// Changelog sections, as in Keep a Changelog
+var changelogSections = []string{"Added", "Changed", "Deprecated", "Removed", "Fixed", "Security"}
+
+// prDescriptionSystemPrompt instructs the model to describe a pull request
+var prDescriptionSystemPrompt = `You write pull request descriptions from a diff and its commit log.
+Respond with JSON only:
+{
+  "title": "imperative title of at most 72 characters",
+  "summary": "what the change does and why, in one or two short paragraphs",
+  "risk_areas": ["parts of the change reviewers should look at closely, and why"],
+  "testing_notes": ["how the change was or should be tested"],
+  "changelog": {"section": "` + strings.Join(changelogSections, "|") + `", "entry": "one line for users of the project"}
+}
+Base everything on the diff and commits; do not invent tests or behavior that are not there.
+Use an empty list when there are no risk areas or testing notes worth mentioning.`
+
+// ChangelogEntry is a line for a Keep a Changelog style changelog
+type ChangelogEntry struct {
+	Section string `json:"section"` // Added, Changed, Deprecated, Removed, Fixed or Security
+	Entry   string `json:"entry"`
+}
+
+// PRDescription is a pull request description drafted by a provider
+type PRDescription struct {
+	Title           string         `json:"title"`
+	Summary         string         `json:"summary"`
+	RiskAreas       []string       `json:"risk_areas"`
+	TestingNotes    []string       `json:"testing_notes"`
+	Changelog       ChangelogEntry `json:"changelog"`
+	Body            string         `json:"body"` // Markdown body assembled from the parts above
+	Base            string         `json:"base"`
+	Head            string         `json:"head"`
+	Commits         int            `json:"commits"`
+	Provider        string         `json:"provider"`
+	Model           string         `json:"model,omitempty"`
+	InputTokens     int            `json:"input_tokens,omitempty"`
+	OutputTokens    int            `json:"output_tokens,omitempty"`
+	RedactedSecrets int            `json:"redacted_secrets,omitempty"` // Secrets replaced before the provider call
+}
+
+// DraftPRDescription asks the provider for a pull request description of the
+// changes from BaseRef to HeadRef. The diff is the one a pull request shows,
+// from the merge base, with path filters and the repository policy applied
+// and secrets redacted.
+func (e *Engine) DraftPRDescription(ctx context.Context, req Request) (*PRDescription, error) {
+	req.SourceType = "range"
+	redacted, err := e.prepareDiff(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Files) == 0 {
+		return nil, ErrNoRangeChanges
+	}
+
+	commits, err := git.NewClient(req.RepositoryPath).RevListContext(ctx, req.BaseRef+".."+req.head())
+	if err != nil {
+		return nil, err
+	}
+
+	gen, err := e.generate(ctx, req.Provider, GenerateRequest{
+		System: prDescriptionSystemPrompt,
+		Prompt: prDescriptionPrompt(req, commits),
+		Model:  req.Model,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	desc := &PRDescription{}
+	if err := json.Unmarshal([]byte(extractJSON(gen.Text)), desc); err != nil {
+		return nil, fmt.Errorf("provider returned an unreadable description: %w", err)
+	}
+	if strings.TrimSpace(desc.Title) == "" {
+		return nil, errors.New("provider returned a description without a title")
+	}
+	if !validChangelogSection(desc.Changelog.Section) {
+		desc.Changelog.Section = "Changed"
+	}
+	if desc.RiskAreas == nil {
+		desc.RiskAreas = []string{}
+	}
+	if desc.TestingNotes == nil {
+		desc.TestingNotes = []string{}
+	}
+	desc.Changelog.Entry = strings.TrimPrefix(strings.TrimSpace(desc.Changelog.Entry), "- ")
+	desc.Body = prDescriptionBody(desc)
+	desc.Base = req.BaseRef
+	desc.Head = req.head()
+	desc.Commits = len(commits)
+	desc.Provider = gen.Provider
+	desc.Model = gen.Model
+	desc.InputTokens = gen.InputTokens
+	desc.OutputTokens = gen.OutputTokens
+	desc.RedactedSecrets = redacted
+	return desc, nil
+}
+
+// prDescriptionPrompt shows the model the commit log and the diff
+func prDescriptionPrompt(req Request, commits []git.Commit) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "Commits from %s to %s, oldest first:\n", req.BaseRef, req.head())
+	for i, c := range commits {
+		if i == maxLogCommits {
+			fmt.Fprintf(&builder, "- ... and %d more\n", len(commits)-maxLogCommits)
+			break
+		}
+		merge := ""
+		if c.IsMerge() {
+			merge = " (merge)"
+		}
+		subject, _ := secrets.Redact(c.Subject)
+		fmt.Fprintf(&builder, "- %s %s%s\n", shortCommit(c.SHA), subject, merge)
+	}
+	if instructions := strings.TrimSpace(req.Instructions); instructions != "" {
+		builder.WriteString("\nAdditional guidance: " + instructions + "\n")
+	}
+	builder.WriteString("\nDiff:\n```diff\n" + req.Code + "\n```\n")
+	return builder.String()
+}
+
+// prDescriptionBody renders a description as a markdown pull request body
+func prDescriptionBody(desc *PRDescription) string {
+	var builder strings.Builder
+	builder.WriteString("## Summary\n\n" + strings.TrimSpace(desc.Summary) + "\n")
+
+	writeList := func(title string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		builder.WriteString("\n## " + title + "\n\n")
+		for _, item := range items {
+			builder.WriteString("- " + item + "\n")
+		}
+	}
+	writeList("Risk areas", desc.RiskAreas)
+	writeList("Testing", desc.TestingNotes)
+
+	if desc.Changelog.Entry != "" {
+		fmt.Fprintf(&builder, "\n## Changelog\n\n### %s\n- %s\n", desc.Changelog.Section, desc.Changelog.Entry)
+	}
+	return builder.String()
+}
+
+// validChangelogSection reports whether a section is a Keep a Changelog section
+func validChangelogSection(section string) bool {
+	for _, s := range changelogSections {
+		if s == section {
+			return true
+		}
+	}
+	return false
+}
@@ -3,10 +3,18 @@ package review
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/dshills/mcp-pr/internal/analysis"
+	"github.com/dshills/mcp-pr/internal/enrich"
 	"github.com/dshills/mcp-pr/internal/git"
+	"github.com/dshills/mcp-pr/internal/gocontext"
 	"github.com/dshills/mcp-pr/internal/logging"
+	"github.com/dshills/mcp-pr/internal/policy"
+	"github.com/dshills/mcp-pr/internal/rules"
+	"github.com/dshills/mcp-pr/internal/secrets"
 )
 
 // Provider interface for LLM providers (avoid circular import)
@@ -16,6 +24,28 @@ type Provider interface {
 	IsAvailable() bool
 }
 
+// Recorder stores completed reviews (avoid circular import with the history store)
+type Recorder interface {
+	// Annotate compares findings with earlier reviews before the verdict is
+	// computed, setting lifecycle statuses, Fixed and triage states
+	Annotate(ctx context.Context, req Request, resp *Response) error
+	// Record stores the finished review and returns its ID
+	Record(ctx context.Context, req Request, resp *Response) (string, error)
+}
+
+// ModelReporter is implemented by providers that can report the model they use
+type ModelReporter interface {
+	Model() string
+}
+
+// ProviderInfo describes a configured provider
+type ProviderInfo struct {
+	Name      string `json:"name"`
+	Model     string `json:"model,omitempty"`
+	Available bool   `json:"available"`
+	Default   bool   `json:"default"`
+}
+
 // Engine orchestrates code review operations
 type Engine struct {
 	providers       map[string]Provider
@@ -23,17 +53,99 @@ type Engine struct {
 	maxRetries      int
 	retryDelay      time.Duration
 	maxDiffSize     int
+	maxDiffSource   string // SourceConfig or SourceDefault
+	maxDiffOrigin   string // Where the server limit was set, for error messages
+	sourceContext   enrich.Options
+	goContext       gocontext.Options
+	analyzers       []analysis.Analyzer
+	analyzerTimeout time.Duration
+	blockOnSecrets  bool
+	rules           *rules.Set
+	instructions    []customInstructions
+	failOn          string
+	recorder        Recorder
+}
+
+// Option configures optional engine behavior
+type Option func(*Engine)
+
+// WithSourceContext includes surrounding source from changed files in git-based reviews
+func WithSourceContext(opts enrich.Options) Option {
+	return func(e *Engine) {
+		e.sourceContext = opts
+	}
+}
+
+// WithGoContext includes type information for Go code touched by git-based reviews
+func WithGoContext(opts gocontext.Options) Option {
+	return func(e *Engine) {
+		e.goContext = opts
+	}
+}
+
+// WithAnalyzers runs local static analyzers on changed files in git-based reviews
+func WithAnalyzers(analyzers []analysis.Analyzer, timeout time.Duration) Option {
+	return func(e *Engine) {
+		e.analyzers = analyzers
+		e.analyzerTimeout = timeout
+	}
+}
+
+// WithBlockOnSecrets skips the provider call entirely when secrets are detected,
+// returning only the secret findings instead of sending redacted code
+func WithBlockOnSecrets() Option {
+	return func(e *Engine) {
+		e.blockOnSecrets = true
+	}
+}
+
+// WithRules evaluates deterministic rules against added lines in git-based reviews
+func WithRules(set *rules.Set) Option {
+	return func(e *Engine) {
+		e.rules = set
+	}
+}
+
+// WithFailOn sets the severity at or above which reviews fail when a request
+// does not choose one; "none" never fails
+func WithFailOn(severity string) Option {
+	return func(e *Engine) {
+		e.failOn = severity
+	}
+}
+
+// WithMaxDiffSizeOrigin records where the server's diff size limit came from:
+// source is SourceConfig or SourceDefault, and origin names the setting for
+// error messages, e.g. "MCP_PR_MAX_DIFF_SIZE" or a config file entry
+func WithMaxDiffSizeOrigin(source, origin string) Option {
+	return func(e *Engine) {
+		e.maxDiffSource = source
+		e.maxDiffOrigin = origin
+	}
+}
+
+// WithRecorder stores every completed review
+func WithRecorder(recorder Recorder) Option {
+	return func(e *Engine) {
+		e.recorder = recorder
+	}
 }
 
 // NewEngine creates a new review engine
-func NewEngine(providers map[string]Provider, defaultProvider string, maxDiffSize int) *Engine {
-	return &Engine{
+func NewEngine(providers map[string]Provider, defaultProvider string, maxDiffSize int, opts ...Option) *Engine {
+	e := &Engine{
 		providers:       providers,
 		defaultProvider: defaultProvider,
 		maxRetries:      1, // Reduced from 3 to 1 to avoid long delays
 		retryDelay:      time.Second,
 		maxDiffSize:     maxDiffSize,
+		maxDiffSource:   SourceConfig,
+		failOn:          DefaultFailOn,
 	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
 }
 
 // Review performs a code review using the specified or default provider
@@ -46,27 +158,32 @@ func (e *Engine) Review(ctx context.Context, req Request) (*Response, error) {
 		return nil, fmt.Errorf("invalid request: %w", err)
 	}
 
-	// Populate Code field from git if needed
-	if err := e.populateCodeFromGit(ctx, &req); err != nil {
-		logging.Error(ctx, "Failed to get git diff", "error", err)
-		return nil, fmt.Errorf("failed to get git diff: %w", err)
+	// Layer the repository policy and server configuration under the request
+	settings, err := e.resolveSettings(&req)
+	if err != nil {
+		logging.Error(ctx, "Invalid repository policy", "error", err)
+		return nil, err
+	}
+
+	// Populate Code field from git or a patch if needed
+	skippedFiles, err := e.populateCode(ctx, &req)
+	if err != nil {
+		logging.Error(ctx, "Failed to read changes", "error", err)
+		return nil, err
 	}
 
 	// Validate diff size
-	if len(req.Code) > e.maxDiffSize {
+	if len(req.Code) > settings.MaxDiffSize {
 		logging.Error(ctx, "Diff too large",
 			"size_bytes", len(req.Code),
-			"max_size_bytes", e.maxDiffSize,
+			"max_size_bytes", settings.MaxDiffSize,
 		)
-		return nil, fmt.Errorf("diff size (%d bytes) exceeds maximum allowed size (%d bytes). Consider reviewing smaller changes or increasing MCP_MAX_DIFF_SIZE",
-			len(req.Code), e.maxDiffSize)
+		return nil, fmt.Errorf("diff size (%d bytes) exceeds maximum allowed size (%d bytes) set by %s. Consider reviewing smaller changes or raising that limit",
+			len(req.Code), settings.MaxDiffSize, e.maxDiffSizeOrigin(settings))
 	}
 
 	// Select provider
 	providerName := req.Provider
-	if providerName == "" {
-		providerName = e.defaultProvider
-	}
 
 	provider, exists := e.providers[providerName]
 	if !exists {
@@ -79,17 +196,50 @@ func (e *Engine) Review(ctx context.Context, req Request) (*Response, error) {
 		return nil, fmt.Errorf("provider %s not available", providerName)
 	}
 
+	// Add supplementary prompt material; failures here never block the review
+	detectLanguages(ctx, &req)
+	e.enrichRequest(ctx, &req)
+	analyzed := e.runAnalyzers(ctx, &req)
+	messageFindings := e.reviewCommitMessage(ctx, &req)
+
+	// Redact secrets before anything leaves the machine
+	secretFindings := redactSecrets(ctx, &req)
+	localFindings := append(secretFindings, e.evaluateRules(ctx, req)...)
+	localFindings = append(localFindings, messageFindings...)
+	if len(secretFindings) > 0 && e.blockOnSecrets {
+		logging.Warn(ctx, "Secrets detected, review not sent to provider",
+			"provider", providerName,
+			"secrets", len(secretFindings),
+		)
+		resp := &Response{
+			Summary:  fmt.Sprintf("Review was not sent to %s because %d secret(s) were detected. Remove them and review again.", providerName, len(secretFindings)),
+			Provider: providerName,
+			Duration: time.Since(start),
+		}
+		annotateMetadata(resp, req, skippedFiles)
+		resp.Metadata.Blocked = true
+		mergeAnalysis(resp, analyzed)
+		resp.Findings = filterBySeverity(append(localFindings, resp.Findings...), settings.MinSeverity)
+		suppressKnown(ctx, req, resp)
+		e.annotate(ctx, req, resp)
+		applyVerdict(resp, settings.FailOn)
+		resp.Metadata.RedactedSecrets = len(secretFindings)
+		resp.Metadata.Settings = settings
+		e.record(ctx, req, resp)
+		return resp, nil
+	}
+
 	logging.Info(ctx, "Starting code review",
 		"provider", providerName,
 		"source_type", req.SourceType,
 		"language", req.Language,
 		"review_depth", req.ReviewDepth,
 		"code_size_bytes", len(req.Code),
+		"prompt_sections", len(req.Sections),
 	)
 
 	// Perform review with retry logic
 	var resp *Response
-	var err error
 
 	for attempt := 0; attempt <= e.maxRetries; attempt++ {
 		if attempt > 0 {
@@ -133,16 +283,67 @@ func (e *Engine) Review(ctx context.Context, req Request) (*Response, error) {
 		return nil, fmt.Errorf("review failed after %d attempts: %w", e.maxRetries+1, err)
 	}
 
+	annotateMetadata(resp, req, skippedFiles)
+	anchorFindings(req, resp)
+	mergeAnalysis(resp, analyzed)
+	resp.Findings = filterBySeverity(append(localFindings, resp.Findings...), settings.MinSeverity)
+	suppressKnown(ctx, req, resp)
+	e.annotate(ctx, req, resp)
+	applyVerdict(resp, settings.FailOn)
+	resp.Metadata.RedactedSecrets = len(secretFindings)
+	resp.Metadata.Settings = settings
+	e.record(ctx, req, resp)
+
 	duration := time.Since(start)
 	logging.Info(ctx, "Review completed",
 		"provider", providerName,
 		"findings_count", len(resp.Findings),
+		"verdict", resp.Verdict,
 		"duration_ms", duration.Milliseconds(),
 	)
 
 	return resp, nil
 }
 
+// maxDiffSizeOrigin names where the effective diff size limit was set
+func (e *Engine) maxDiffSizeOrigin(settings *Settings) string {
+	switch {
+	case settings.Sources["max_diff_size"] == SourcePolicy:
+		return "max_diff_size in " + policy.FileName
+	case e.maxDiffOrigin != "":
+		return e.maxDiffOrigin
+	case settings.Sources["max_diff_size"] == SourceDefault:
+		return "the built-in default"
+	default:
+		return "the server configuration"
+	}
+}
+
+// annotate compares findings with review history; failures are logged and never fail the review
+func (e *Engine) annotate(ctx context.Context, req Request, resp *Response) {
+	if e.recorder == nil {
+		return
+	}
+
+	if err := e.recorder.Annotate(ctx, req, resp); err != nil {
+		logging.Warn(ctx, "Failed to compare with review history", "error", err.Error())
+	}
+}
+
+// record stores a completed review; failures are logged and never fail the review
+func (e *Engine) record(ctx context.Context, req Request, resp *Response) {
+	if e.recorder == nil {
+		return
+	}
+
+	id, err := e.recorder.Record(ctx, req, resp)
+	if err != nil {
+		logging.Warn(ctx, "Failed to record review history", "error", err.Error())
+		return
+	}
+	resp.Metadata.HistoryID = id
+}
+
 // GetProvider returns a provider by name
 func (e *Engine) GetProvider(name string) (Provider, bool) {
 	provider, exists := e.providers[name]
@@ -157,19 +358,68 @@ func (e *Engine) ListProviders() []string {
 			names = append(names, name)
 		}
 	}
+	sort.Strings(names)
 	return names
 }
 
-// populateCodeFromGit retrieves git diff and populates the Code field
-func (e *Engine) populateCodeFromGit(ctx context.Context, req *Request) error {
+// DescribeProviders returns details of all configured providers, sorted by name
+func (e *Engine) DescribeProviders() []ProviderInfo {
+	infos := make([]ProviderInfo, 0, len(e.providers))
+	for name, provider := range e.providers {
+		info := ProviderInfo{
+			Name:      name,
+			Available: provider.IsAvailable(),
+			Default:   name == e.defaultProvider,
+		}
+		if reporter, ok := provider.(ModelReporter); ok {
+			info.Model = reporter.Model()
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
+
+// DefaultProvider returns the name of the provider used when none is requested
+func (e *Engine) DefaultProvider() string {
+	return e.defaultProvider
+}
+
+// MaxDiffSize returns the maximum code size in bytes accepted for review
+func (e *Engine) MaxDiffSize() int {
+	return e.maxDiffSize
+}
+
+// populateCode fills in the Code and Files fields of patch, file and
+// git-based requests. It returns the paths of files removed by the request's path filters.
+func (e *Engine) populateCode(ctx context.Context, req *Request) ([]string, error) {
+	if req.SourceType == "patch" {
+		return populatePatch(ctx, req)
+	}
+	if req.SourceType == "files" {
+		return populateFiles(ctx, req)
+	}
+
+	skipped, err := e.populateCodeFromGit(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get git diff: %w", err)
+	}
+	return skipped, nil
+}
+
+// populateCodeFromGit retrieves git diff and populates the Code and Files fields.
+// It returns the paths of files removed by the request's path filters.
+func (e *Engine) populateCodeFromGit(ctx context.Context, req *Request) ([]string, error) {
 	// Skip if not a git-based request
 	if req.SourceType == "arbitrary" {
-		return nil
+		return nil, nil
 	}
 
 	// Skip if Code is already populated
 	if req.Code != "" {
-		return nil
+		return nil, nil
 	}
 
 	logging.Info(ctx, "Fetching git diff",
@@ -191,19 +441,320 @@ func (e *Engine) populateCodeFromGit(ctx context.Context, req *Request) error {
 		diff, err = client.GetUnstagedDiffContext(ctx)
 	case "commit":
 		diff, err = client.GetCommitDiffContext(ctx, req.CommitSHA)
+	case "range":
+		diff, err = client.GetRangeDiffContext(ctx, req.BaseRef, req.head())
+	case "working_tree":
+		diff, err = client.GetWorkingTreeDiffContext(ctx, git.WorkingTreeOptions{
+			IncludeStaged:    req.IncludeStaged,
+			IncludeUnstaged:  req.IncludeUnstaged,
+			IncludeUntracked: req.IncludeUntracked,
+		})
 	default:
-		return fmt.Errorf("unsupported source type: %s", req.SourceType)
+		return nil, fmt.Errorf("unsupported source type: %s", req.SourceType)
 	}
 
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	files, err := git.Parse(diff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse git diff: %w", err)
+	}
+
+	// Apply path filters before the diff reaches a provider
+	var skipped []string
+	if filter := req.PathFilter(); !filter.IsEmpty() {
+		tree := reviewedTree(req)
+		filter.Source = func(filePath string) (string, error) {
+			return tree.ReadFile(ctx, filePath)
+		}
+		files, skipped = filter.Apply(files)
+		if len(skipped) > 0 {
+			logging.Info(ctx, "Path filters skipped files",
+				"skipped_count", len(skipped),
+				"kept_count", len(files),
+			)
+			diff = git.Preamble(diff) + git.Render(files)
+		}
 	}
 
 	logging.Info(ctx, "Git diff fetched",
 		"diff_size_bytes", len(diff),
+		"file_count", len(files),
 	)
 
 	// Populate the Code field with diff
 	req.Code = diff
-	return nil
+	req.Files = files
+	return skipped, nil
+}
+
+// enrichRequest adds supplementary prompt sections to git-based review requests
+func (e *Engine) enrichRequest(ctx context.Context, req *Request) {
+	if len(req.Files) == 0 || req.RepositoryPath == "" {
+		return
+	}
+
+	opts := e.sourceContext
+	if req.ContextLines != nil {
+		opts.WindowLines = *req.ContextLines
+	}
+
+	// File reviews already show whole files
+	if opts.Enabled() && req.SourceType != "files" {
+		result := enrich.Collect(ctx, req.Files, reviewedTree(req).ReadFile, opts)
+		if len(result.OmittedFiles) > 0 {
+			logging.Warn(ctx, "Source context omitted for some files",
+				"omitted_files", result.OmittedFiles,
+			)
+		}
+		if len(result.Snippets) > 0 {
+			req.Sections = append(req.Sections, PromptSection{
+				Title:    "Surrounding source context",
+				Content:  enrich.Format(result.Snippets),
+				ReadOnly: true,
+			})
+		}
+	}
+
+	if e.goContext.Enabled() {
+		result, err := gocontext.Collect(ctx, req.Files, reviewedTree(req), e.goContext)
+		if err != nil {
+			logging.Warn(ctx, "Go package context unavailable", "error", err.Error())
+		}
+		if !result.IsEmpty() {
+			req.Sections = append(req.Sections, PromptSection{
+				Title:    "Go package context",
+				Content:  gocontext.Format(result),
+				ReadOnly: true,
+			})
+		}
+	}
+}
+
+// runAnalyzers runs local analyzers on the changed files and adds their
+// diagnostics to the prompt. Analyzers inspect the working tree, so commit
+// and range reviews are skipped.
+func (e *Engine) runAnalyzers(ctx context.Context, req *Request) analysis.Result {
+	if len(e.analyzers) == 0 || len(req.Files) == 0 || req.RepositoryPath == "" || req.SourceType == "commit" || req.SourceType == "range" {
+		return analysis.Result{}
+	}
+
+	root, err := git.NewClient(req.RepositoryPath).GetRepositoryRoot()
+	if err != nil {
+		logging.Warn(ctx, "Skipping local analyzers", "error", err.Error())
+		return analysis.Result{}
+	}
+
+	result := analysis.Run(ctx, root, req.Files, e.analyzers, e.analyzerTimeout)
+	if len(result.Unavailable) > 0 {
+		logging.Warn(ctx, "Some local analyzers are unavailable",
+			"analyzers", result.Unavailable,
+		)
+	}
+
+	if len(result.Diagnostics) > 0 {
+		req.Sections = append(req.Sections, PromptSection{
+			Title:   "Static analysis diagnostics",
+			Content: analysis.Format(result.Diagnostics),
+		})
+	}
+
+	return result
+}
+
+// mergeAnalysis appends analyzer diagnostics to the response as findings
+func mergeAnalysis(resp *Response, result analysis.Result) {
+	for _, diag := range result.Diagnostics {
+		line := diag.Line
+		resp.Findings = append(resp.Findings, Finding{
+			Category:    diag.Category,
+			Severity:    diag.Severity,
+			Line:        &line,
+			FilePath:    diag.FilePath,
+			Description: diag.Message,
+			Suggestion:  fmt.Sprintf("Address the issue reported by %s.", diag.Source),
+			Source:      diag.Source,
+		})
+	}
+
+	resp.Metadata.Analyzers = result.Ran
+	resp.Metadata.UnavailableAnalyzers = result.Unavailable
+}
+
+// redactSecrets replaces secrets in the code and prompt sections with markers
+// and returns a critical security finding for each one detected in the code
+func redactSecrets(ctx context.Context, req *Request) []Finding {
+	var findings []Finding
+
+	if len(req.Files) > 0 {
+		// Text before the files, such as a commit message, is sent too
+		preamble, matches := secrets.Redact(git.Preamble(req.Code))
+		for _, match := range matches {
+			line := match.Line
+			findings = append(findings, secretFinding(match, "", &line, false))
+		}
+		for i := range req.Files {
+			findings = append(findings, redactFileSecrets(&req.Files[i])...)
+		}
+		if len(findings) > 0 {
+			req.Code = preamble + git.Render(req.Files)
+		}
+	} else {
+		code, matches := secrets.Redact(req.Code)
+		req.Code = code
+		for _, match := range matches {
+			line := match.Line
+			findings = append(findings, secretFinding(match, "", &line, false))
+		}
+	}
+
+	// Context sections are read from the same files and may contain the same secrets
+	for i := range req.Sections {
+		req.Sections[i].Content, _ = secrets.Redact(req.Sections[i].Content)
+	}
+
+	if len(findings) > 0 {
+		logging.Warn(ctx, "Redacted secrets from review request", "secrets", len(findings))
+	}
+
+	return findings
+}
+
+// redactFileSecrets redacts secrets in a file's hunks in place
+func redactFileSecrets(file *git.FileDiff) []Finding {
+	var findings []Finding
+	var scanner secrets.Scanner
+
+	for h := range file.Hunks {
+		hunk := &file.Hunks[h]
+		newLine := hunk.NewStart
+
+		for i, text := range hunk.Lines {
+			if text == "" || text[0] == '\\' {
+				continue
+			}
+
+			prefix, content := text[:1], text[1:]
+			redacted, matches := scanner.Line(content)
+			hunk.Lines[i] = prefix + redacted
+
+			for _, match := range matches {
+				if prefix == "-" {
+					findings = append(findings, secretFinding(match, file.Path(), nil, true))
+					continue
+				}
+				line := newLine
+				findings = append(findings, secretFinding(match, file.Path(), &line, false))
+			}
+
+			if prefix != "-" {
+				newLine++
+			}
+		}
+	}
+
+	return findings
+}
+
+// secretFinding builds the finding reported for a detected secret
+func secretFinding(match secrets.Match, filePath string, line *int, removed bool) Finding {
+	description := fmt.Sprintf("Possible %s in the code. It was redacted before the code was sent for review.", match.Description)
+	if removed {
+		description = fmt.Sprintf("Possible %s in removed code; it remains in version control history. It was redacted before the code was sent for review.", match.Description)
+	}
+
+	finding := Finding{
+		Category:    "security",
+		Severity:    "critical",
+		Line:        line,
+		FilePath:    filePath,
+		Description: description,
+		Suggestion:  "Rotate the secret, remove it from the code and history, and load it from the environment or a secret manager instead.",
+		Source:      SecretSource,
+	}
+	if match.RuleID != "private-key" {
+		finding.CodeSnippet = secrets.Mask(match.Value)
+	}
+	return finding
+}
+
+// evaluateRules runs the configured rules against lines added by a git diff
+func (e *Engine) evaluateRules(ctx context.Context, req Request) []Finding {
+	if e.rules == nil || len(req.Files) == 0 {
+		return nil
+	}
+
+	var findings []Finding
+	for _, match := range e.rules.Evaluate(ctx, req.Files, reviewedSource(&req)) {
+		line := match.Line
+		findings = append(findings, Finding{
+			Category:    match.Category,
+			Severity:    match.Severity,
+			Line:        &line,
+			FilePath:    match.FilePath,
+			Description: fmt.Sprintf("%s (rule %s)", match.Message, match.RuleID),
+			Suggestion:  match.Suggestion,
+			CodeSnippet: match.Snippet,
+			Source:      "rule",
+		})
+	}
+
+	if len(findings) > 0 {
+		logging.Info(ctx, "Local rules matched", "findings", len(findings))
+	}
+	return findings
+}
+
+// reviewedTree returns the version of the repository matching the request's source type
+func reviewedTree(req *Request) git.Tree {
+	client := git.NewClient(req.RepositoryPath)
+
+	switch {
+	case req.SourceType == "commit":
+		return client.CommitTree(req.CommitSHA)
+	case req.SourceType == "range":
+		return client.CommitTree(req.head())
+	case req.SourceType == "staged" || (req.SourceType == "working_tree" && !req.IncludeUnstaged && !req.IncludeUntracked):
+		return client.IndexTree()
+	default:
+		return client.WorkingTree()
+	}
+}
+
+// annotateMetadata fills in diff statistics the provider cannot know about
+func annotateMetadata(resp *Response, req Request, skippedFiles []string) {
+	if resp.Metadata == nil {
+		resp.Metadata = &Metadata{SourceType: req.SourceType}
+	}
+
+	if len(req.Files) > 0 {
+		resp.Metadata.FileCount = len(req.Files)
+		for _, file := range req.Files {
+			resp.files = append(resp.files, file.Path())
+			if file.OldPath != "" && file.OldPath != file.Path() {
+				resp.files = append(resp.files, file.OldPath)
+			}
+		}
+		added, removed := 0, 0
+		for _, file := range req.Files {
+			for _, hunk := range file.Hunks {
+				for _, line := range hunk.Lines {
+					switch {
+					case strings.HasPrefix(line, "+"):
+						added++
+					case strings.HasPrefix(line, "-"):
+						removed++
+					}
+				}
+			}
+		}
+		resp.Metadata.LinesAdded = added
+		resp.Metadata.LinesRemoved = removed
+	}
+
+	resp.Metadata.SkippedFiles = skippedFiles
+	resp.Metadata.Patches = req.Patches
+	resp.Metadata.Languages = req.Languages
 }
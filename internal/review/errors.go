@@ -1,15 +1,25 @@
 package review
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dshills/mcp-pr/internal/policy"
+)
 
 // Request validation errors
 var (
 	ErrInvalidSourceType  = errors.New("invalid source type")
 	ErrEmptyCode          = errors.New("code cannot be empty for arbitrary reviews")
+	ErrEmptyPatch         = errors.New("patch text or patch file is required for patch reviews")
 	ErrMissingRepository  = errors.New("repository path is required for git-based reviews")
 	ErrMissingCommitSHA   = errors.New("commit SHA is required for commit reviews")
-	ErrMissingProvider    = errors.New("provider must be specified")
+	ErrMissingBaseRef     = errors.New("base revision is required for range reviews")
 	ErrInvalidReviewDepth = errors.New("review depth must be 'quick' or 'thorough'")
+
+	ErrNoWorkingTreeChanges = errors.New("working tree reviews must include at least one of staged, unstaged or untracked changes")
+	ErrInvalidFailOn        = errors.New("fail_on must be a severity (critical, high, medium, low, info) or 'none'")
+	ErrInstructionsTooLong  = fmt.Errorf("instructions must be at most %d characters", policy.MaxInstructions)
 )
 
 // Provider errors
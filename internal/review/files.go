@@ -0,0 +1,302 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dshills/mcp-pr/internal/git"
+	"github.com/dshills/mcp-pr/internal/logging"
+)
+
+// File review limits
+const (
+	DefaultChunkConcurrency = 3   // Chunks reviewed at once when a request does not choose
+	MaxChunkConcurrency     = 8   // Upper bound on concurrent chunk reviews
+	MaxReviewFiles          = 200 // Files a single file review may include
+)
+
+// File review errors
+var (
+	ErrMissingPaths  = errors.New("paths are required for file reviews")
+	ErrNoFilesMatch  = errors.New("no files to review match the paths and filters")
+	ErrTooManyFiles  = fmt.Errorf("paths select more than %d files; review fewer files at once", MaxReviewFiles)
+	ErrFilesTooLarge = errors.New("every selected file is larger than the maximum review size")
+)
+
+// filesGuidance tells the model that whole files, not changes, are under review
+const filesGuidance = `The code under review is a set of whole files, shown as new-file diffs so that every line is under review. Audit the code as it is rather than as a change.
+Set "file_path" on every finding to the path of the file, and give "line" as the line number within that file.`
+
+// FilesRequest reviews the current contents of files rather than changes
+type FilesRequest struct {
+	Request         // Settings shared by every chunk: repository, paths, provider, depth, filters
+	Concurrency int // Chunks reviewed at once (0 = DefaultChunkConcurrency)
+}
+
+// ChunkError records a chunk of files whose review failed
+type ChunkError struct {
+	Files []string
+	Error string
+}
+
+// FilesResponse combines the reviews of every chunk of a file review
+type FilesResponse struct {
+	Files        []string // Files reviewed, sorted
+	Skipped      []string // Files removed by path filters or not reviewable as text
+	TooLarge     []string // Files larger than the maximum review size on their own
+	Chunks       int
+	Errors       []ChunkError
+	Findings     []Finding // Findings of every chunk, in file order
+	Verdict      string    // VerdictFail when any chunk failed its review or failed to run
+	Counts       Counts
+	InputTokens  int
+	OutputTokens int
+	Summary      string
+	Duration     time.Duration
+}
+
+// ReviewFiles reviews the current contents of the files selected by Paths.
+// Files are grouped into chunks that each fit within the maximum review size
+// and reviewed with bounded concurrency; a file never spans two chunks. A
+// failed chunk is reported in Errors and does not stop the others.
+func (e *Engine) ReviewFiles(ctx context.Context, req FilesRequest) (*FilesResponse, error) {
+	start := time.Now()
+
+	// Select and read the files once, on a copy, so each chunk review
+	// resolves its settings from the original request
+	selection := req.Request
+	selection.SourceType = "files"
+	if err := selection.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	settings, err := e.resolveSettings(&selection)
+	if err != nil {
+		return nil, err
+	}
+	skipped, err := e.populateCode(ctx, &selection)
+	if err != nil {
+		return nil, err
+	}
+	if len(selection.Files) > MaxReviewFiles {
+		return nil, ErrTooManyFiles
+	}
+
+	resp := &FilesResponse{Skipped: skipped}
+	chunks := chunkFiles(selection.Files, settings.MaxDiffSize, &resp.TooLarge)
+	if len(chunks) == 0 {
+		return nil, ErrFilesTooLarge
+	}
+
+	concurrency := req.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultChunkConcurrency
+	}
+	concurrency = min(concurrency, MaxChunkConcurrency)
+
+	logging.Info(ctx, "Reviewing files",
+		"files", len(selection.Files)-len(resp.TooLarge),
+		"chunks", len(chunks),
+		"concurrency", concurrency,
+	)
+
+	responses := make([]*Response, len(chunks))
+	errs := make([]error, len(chunks))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func(i int, chunk []git.FileDiff) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			chunkReq := req.Request
+			chunkReq.SourceType = "files"
+			chunkReq.Files = chunk
+			chunkReq.Code = git.Render(chunk)
+			responses[i], errs[i] = e.Review(ctx, chunkReq)
+			if errs[i] != nil {
+				logging.Warn(ctx, "Chunk review failed", "chunk", i+1, "error", errs[i].Error())
+			}
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	reviewed := 0
+	for i, chunk := range chunks {
+		paths := filePaths(chunk)
+		resp.Files = append(resp.Files, paths...)
+		if errs[i] != nil {
+			resp.Errors = append(resp.Errors, ChunkError{Files: paths, Error: errs[i].Error()})
+			continue
+		}
+		reviewed++
+	}
+	if reviewed == 0 {
+		return nil, fmt.Errorf("review failed for every chunk: %w", errs[0])
+	}
+
+	rollUpFiles(resp, responses)
+	resp.Chunks = len(chunks)
+	resp.Duration = time.Since(start)
+	return resp, nil
+}
+
+// chunkFiles groups files, in order, into chunks whose rendered diff fits
+// within maxSize. Files too large on their own are appended to tooLarge.
+func chunkFiles(files []git.FileDiff, maxSize int, tooLarge *[]string) [][]git.FileDiff {
+	var chunks [][]git.FileDiff
+	var current []git.FileDiff
+	size := 0
+	for _, file := range files {
+		n := len(git.Render([]git.FileDiff{file}))
+		if n > maxSize {
+			*tooLarge = append(*tooLarge, file.Path())
+			continue
+		}
+		if len(current) > 0 && size+n > maxSize {
+			chunks = append(chunks, current)
+			current, size = nil, 0
+		}
+		current = append(current, file)
+		size += n
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+// rollUpFiles combines the findings, verdicts and token usage of the chunk reviews
+func rollUpFiles(resp *FilesResponse, responses []*Response) {
+	resp.Verdict = VerdictPass
+	resp.Findings = []Finding{}
+	resp.Counts = Counts{
+		BySeverity: map[string]int{},
+		ByCategory: map[string]int{},
+	}
+	for _, r := range responses {
+		if r == nil {
+			continue
+		}
+		if r.Verdict == VerdictFail {
+			resp.Verdict = VerdictFail
+		}
+		resp.Findings = append(resp.Findings, r.Findings...)
+		addCounts(&resp.Counts, r.Counts)
+		if r.Metadata != nil {
+			resp.InputTokens += r.Metadata.InputTokens
+			resp.OutputTokens += r.Metadata.OutputTokens
+		}
+	}
+
+	var summary strings.Builder
+	fmt.Fprintf(&summary, "Reviewed %d files in %d chunks", len(resp.Files), len(responses))
+	if len(resp.TooLarge) > 0 {
+		fmt.Fprintf(&summary, ", skipped %d files too large to review", len(resp.TooLarge))
+	}
+	if len(resp.Errors) > 0 {
+		// Files that were not reviewed cannot pass
+		resp.Verdict = VerdictFail
+		fmt.Fprintf(&summary, ", %d chunk reviews failed to run", len(resp.Errors))
+	}
+	summary.WriteString(". " + findingsSummary(resp.Counts))
+	resp.Summary = summary.String()
+}
+
+// populateFiles renders the files selected by the request's paths as
+// new-file diffs and tells the model they are audited as a whole. Requests
+// whose Code is already set, such as the chunks of ReviewFiles, keep it. It
+// returns the paths of files removed by path filters or skipped as binary.
+func populateFiles(ctx context.Context, req *Request) ([]string, error) {
+	var skipped []string
+	if req.Code == "" {
+		client := git.NewClient(req.RepositoryPath)
+		paths, err := client.ListFilesContext(ctx, req.Paths)
+		if err != nil {
+			return nil, err
+		}
+		if len(paths) == 0 {
+			return nil, ErrNoFilesMatch
+		}
+
+		diff, err := client.GetNewFileDiffContext(ctx, paths)
+		if err != nil {
+			return nil, err
+		}
+		files, err := git.Parse(diff)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse file contents: %w", err)
+		}
+
+		text := files[:0]
+		for _, file := range files {
+			if file.IsBinary || len(file.Hunks) == 0 {
+				skipped = append(skipped, file.Path())
+				continue
+			}
+			text = append(text, file)
+		}
+		files = text
+
+		if filter := req.PathFilter(); !filter.IsEmpty() {
+			var filtered []string
+			files, filtered = filter.Apply(files)
+			skipped = append(skipped, filtered...)
+		}
+		if len(files) == 0 {
+			return nil, ErrNoFilesMatch
+		}
+
+		logging.Info(ctx, "Files read for review",
+			"file_count", len(files),
+			"skipped_count", len(skipped),
+		)
+		req.Code = git.Render(files)
+		req.Files = files
+	}
+
+	req.Sections = append(req.Sections, PromptSection{
+		Title:   "Files under review",
+		Content: filesGuidance,
+	})
+	return skipped, nil
+}
+
+// filePaths returns the paths of file diffs
+func filePaths(files []git.FileDiff) []string {
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		paths = append(paths, file.Path())
+	}
+	return paths
+}
+
+// addCounts adds the finding counts of one review to a total
+func addCounts(total *Counts, counts Counts) {
+	for severity, n := range counts.BySeverity {
+		total.BySeverity[severity] += n
+	}
+	for category, n := range counts.ByCategory {
+		total.ByCategory[category] += n
+	}
+	total.Suppressed += counts.Suppressed
+}
+
+// findingsSummary describes finding counts by severity in one sentence
+func findingsSummary(counts Counts) string {
+	var bySeverity []string
+	for _, severity := range []string{"critical", "high", "medium", "low", "info"} {
+		if n := counts.BySeverity[severity]; n > 0 {
+			bySeverity = append(bySeverity, fmt.Sprintf("%d %s", n, severity))
+		}
+	}
+	if len(bySeverity) == 0 {
+		return "No findings."
+	}
+	return fmt.Sprintf("Findings: %s.", strings.Join(bySeverity, ", "))
+}
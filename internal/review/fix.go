@@ -0,0 +1,194 @@
+package review
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dshills/mcp-pr/internal/git"
+	"github.com/dshills/mcp-pr/internal/logging"
+	"github.com/dshills/mcp-pr/internal/secrets"
+)
+
+// fixContextLines is the number of lines around the flagged range shown to
+// the model; its replacement must stay within them
+const fixContextLines = 20
+
+// Fix request errors
+var (
+	ErrFixMissingFile  = errors.New("file path is required")
+	ErrFixInvalidRange = errors.New("start line must be at least 1 and end line at least the start line")
+	ErrFixMissingIssue = errors.New("issue description is required")
+	ErrFixSecrets      = errors.New("the code around the finding contains secrets and was not sent to the provider")
+)
+
+// FixRequest asks for a concrete fix of an issue in a file of the working tree
+type FixRequest struct {
+	RepositoryPath string // Path to git repository
+	FilePath       string // File relative to the repository root
+	StartLine      int    // First flagged line (1-based)
+	EndLine        int    // Last flagged line (0 = StartLine)
+	Issue          string // What is wrong, e.g. a finding description
+	Suggestion     string // Prose suggestion to follow (optional)
+	Provider       string // Provider name (empty = server default)
+	Model          string // Model override (empty = provider default)
+	Apply          bool   // Write the patch to the working tree
+}
+
+// Fix is a validated patch for an issue
+type Fix struct {
+	FilePath     string `json:"file_path"`
+	StartLine    int    `json:"start_line"` // First replaced line
+	EndLine      int    `json:"end_line"`   // Last replaced line
+	Patch        string `json:"patch"`      // Unified diff that applies to the working tree
+	Explanation  string `json:"explanation"`
+	Applied      bool   `json:"applied"`
+	Provider     string `json:"provider"`
+	Model        string `json:"model,omitempty"`
+	InputTokens  int    `json:"input_tokens,omitempty"`
+	OutputTokens int    `json:"output_tokens,omitempty"`
+}
+
+// fixAnswer is the JSON the model is asked to return
+type fixAnswer struct {
+	StartLine   int    `json:"start_line"`
+	EndLine     int    `json:"end_line"`
+	Replacement string `json:"replacement"`
+	Explanation string `json:"explanation"`
+}
+
+// fixSystemPrompt instructs the model to return a line replacement
+const fixSystemPrompt = `You fix code review findings. You are shown numbered lines of a file and an issue found in them.
+Replace the smallest range of lines that fixes the issue, keeping the surrounding style and indentation.
+Respond with JSON only:
+{
+  "start_line": <first replaced line>,
+  "end_line": <last replaced line>,
+  "replacement": "the new text of those lines, without line numbers",
+  "explanation": "one or two sentences on the change"
+}
+The range must be within the numbered lines. Use an empty replacement to delete lines.`
+
+// Validate checks if the fix request is valid
+func (r *FixRequest) Validate() error {
+	if r.RepositoryPath == "" {
+		return ErrMissingRepository
+	}
+	if r.FilePath == "" {
+		return ErrFixMissingFile
+	}
+	if r.EndLine == 0 {
+		r.EndLine = r.StartLine
+	}
+	if r.StartLine < 1 || r.EndLine < r.StartLine {
+		return ErrFixInvalidRange
+	}
+	if strings.TrimSpace(r.Issue) == "" {
+		return ErrFixMissingIssue
+	}
+	return nil
+}
+
+// SuggestFix asks the provider to fix an issue in a working tree file and
+// returns the fix as a unified diff checked with `git apply --check`. With
+// Apply set, the patch is also written to the working tree.
+func (e *Engine) SuggestFix(ctx context.Context, req FixRequest) (*Fix, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	client := git.NewClient(req.RepositoryPath)
+	content, err := client.ReadWorkingTreeFile(req.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	lines := splitSource(content)
+	if req.StartLine > len(lines) {
+		return nil, fmt.Errorf("line %d is past the end of %s (%d lines)", req.StartLine, req.FilePath, len(lines))
+	}
+	req.EndLine = min(req.EndLine, len(lines))
+
+	// Only a window around the finding is sent, and never with secrets in it
+	first := max(req.StartLine-fixContextLines, 1)
+	last := min(req.EndLine+fixContextLines, len(lines))
+	window := lines[first-1 : last]
+	if _, matches := secrets.Redact(strings.Join(window, "\n")); len(matches) > 0 {
+		return nil, ErrFixSecrets
+	}
+
+	gen, err := e.generate(ctx, req.Provider, GenerateRequest{
+		System: fixSystemPrompt,
+		Prompt: fixPrompt(req, window, first),
+		Model:  req.Model,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var answer fixAnswer
+	if err := json.Unmarshal([]byte(extractJSON(gen.Text)), &answer); err != nil {
+		return nil, fmt.Errorf("provider returned an unreadable fix: %w", err)
+	}
+	if answer.StartLine < first || answer.EndLine < answer.StartLine || answer.EndLine > last {
+		return nil, fmt.Errorf("provider replaced lines %d-%d, outside the lines %d-%d it was shown", answer.StartLine, answer.EndLine, first, last)
+	}
+
+	patch, err := git.ReplacementPatch(req.FilePath, content, answer.StartLine, answer.EndLine, answer.Replacement)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.ApplyCheckContext(ctx, patch); err != nil {
+		return nil, err
+	}
+
+	fix := &Fix{
+		FilePath:     req.FilePath,
+		StartLine:    answer.StartLine,
+		EndLine:      answer.EndLine,
+		Patch:        patch,
+		Explanation:  answer.Explanation,
+		Provider:     gen.Provider,
+		Model:        gen.Model,
+		InputTokens:  gen.InputTokens,
+		OutputTokens: gen.OutputTokens,
+	}
+	if req.Apply {
+		if err := client.ApplyContext(ctx, patch); err != nil {
+			return nil, err
+		}
+		fix.Applied = true
+		logging.Info(ctx, "Applied fix", "file", req.FilePath, "start_line", fix.StartLine, "end_line", fix.EndLine)
+	}
+
+	return fix, nil
+}
+
+// fixPrompt shows the model the issue and the numbered lines around it
+func fixPrompt(req FixRequest, window []string, first int) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "File: %s\n", req.FilePath)
+	if req.EndLine > req.StartLine {
+		fmt.Fprintf(&builder, "Flagged lines: %d-%d\n", req.StartLine, req.EndLine)
+	} else {
+		fmt.Fprintf(&builder, "Flagged line: %d\n", req.StartLine)
+	}
+	fmt.Fprintf(&builder, "Issue: %s\n", req.Issue)
+	if req.Suggestion != "" {
+		fmt.Fprintf(&builder, "Suggested fix: %s\n", req.Suggestion)
+	}
+	builder.WriteString("\n")
+	for i, line := range window {
+		fmt.Fprintf(&builder, "%d: %s\n", first+i, line)
+	}
+	return builder.String()
+}
+
+// splitSource splits file content into lines without a trailing empty line
+func splitSource(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
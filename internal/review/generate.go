@@ -0,0 +1,100 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dshills/mcp-pr/internal/logging"
+)
+
+// ErrGenerationUnsupported is returned when a provider cannot answer free-form prompts
+var ErrGenerationUnsupported = errors.New("provider does not support generation")
+
+// Generator is implemented by providers that can answer free-form prompts,
+// such as fix suggestions and drafted text
+type Generator interface {
+	Generate(ctx context.Context, req GenerateRequest) (*Generation, error)
+}
+
+// Message roles in a conversation
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message is one turn of a conversation with a provider
+type Message struct {
+	Role    string `json:"role"` // RoleUser or RoleAssistant
+	Content string `json:"content"`
+}
+
+// GenerateRequest is a free-form prompt for a provider
+type GenerateRequest struct {
+	System    string    // Instructions for the model
+	Messages  []Message // Earlier turns, oldest first, starting with a user message (optional)
+	Prompt    string    // The user message
+	Model     string    // Model override; empty uses the provider default
+	MaxTokens int       // Output limit; zero uses the provider default
+}
+
+// Generation is a provider's answer to a GenerateRequest
+type Generation struct {
+	Text         string
+	Provider     string
+	Model        string
+	InputTokens  int
+	OutputTokens int
+}
+
+// generate sends a prompt to the named provider, or the default provider,
+// with the same retry policy as reviews
+func (e *Engine) generate(ctx context.Context, providerName string, req GenerateRequest) (*Generation, error) {
+	if providerName == "" {
+		providerName = e.defaultProvider
+	}
+	provider, exists := e.providers[providerName]
+	if !exists {
+		return nil, fmt.Errorf("provider %s not found", providerName)
+	}
+	if !provider.IsAvailable() {
+		return nil, fmt.Errorf("provider %s not available", providerName)
+	}
+	generator, ok := provider.(Generator)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrGenerationUnsupported, providerName)
+	}
+
+	var gen *Generation
+	var err error
+	for attempt := 0; attempt <= e.maxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(e.retryDelay * time.Duration(attempt))
+		}
+		gen, err = generator.Generate(ctx, req)
+		if err == nil {
+			gen.Provider = providerName
+			return gen, nil
+		}
+		logging.Warn(ctx, "Generation attempt failed",
+			"attempt", attempt+1,
+			"max_attempts", e.maxRetries+1,
+			"provider", providerName,
+			"error", err,
+		)
+	}
+	return nil, fmt.Errorf("generation failed after %d attempts: %w", e.maxRetries+1, err)
+}
+
+// extractJSON returns the JSON object in a model answer, stripping any
+// surrounding prose or markdown code fence
+func extractJSON(text string) string {
+	start := strings.Index(text, "{")
+	end := strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return ""
+	}
+	return text[start : end+1]
+}
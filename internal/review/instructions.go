@@ -0,0 +1,112 @@
+package review
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dshills/mcp-pr/internal/policy"
+)
+
+// instructionsTitle is the prompt section holding all custom instructions
+const instructionsTitle = "Review instructions"
+
+// InstructionSource records one set of custom instructions added to the prompt
+type InstructionSource struct {
+	Source string `json:"source"`         // SourceConfig, SourcePolicy or SourceArgument
+	Name   string `json:"name,omitempty"` // File the instructions came from, if any
+	Length int    `json:"length"`         // Characters added to the prompt
+}
+
+// customInstructions is a set of instructions with its source
+type customInstructions struct {
+	InstructionSource
+	text string
+}
+
+// WithInstructions adds team-wide instructions, such as a style guide, to every review
+func WithInstructions(name, text string) Option {
+	return func(e *Engine) {
+		e.instructions = append(e.instructions, newInstructions(SourceConfig, name, text))
+	}
+}
+
+// ReadInstructionsFile reads a file of review instructions, enforcing the length limit
+func ReadInstructionsFile(path string) (string, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: path comes from configuration
+	if err != nil {
+		return "", fmt.Errorf("failed to read instructions file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	return readInstructions(f, path)
+}
+
+// readRepoInstructionsFile reads a policy's instructions file, refusing paths
+// that leave the repository root, including through symlinks
+func readRepoInstructionsFile(root, name string) (string, error) {
+	dir, err := os.OpenRoot(root)
+	if err != nil {
+		return "", fmt.Errorf("failed to read instructions file: %w", err)
+	}
+	defer func() { _ = dir.Close() }()
+
+	f, err := dir.Open(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to read instructions file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	return readInstructions(f, name)
+}
+
+// readInstructions reads instructions from an open file, which must be a regular file
+func readInstructions(f *os.File, name string) (string, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return "", fmt.Errorf("failed to read instructions file: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("instructions file %s is not a regular file", name)
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return "", fmt.Errorf("failed to read instructions file: %w", err)
+	}
+
+	text := strings.TrimSpace(string(data))
+	if len(text) > policy.MaxInstructions {
+		return "", fmt.Errorf("instructions file %s has %d characters; the limit is %d", name, len(text), policy.MaxInstructions)
+	}
+	return text, nil
+}
+
+// newInstructions trims instruction text and records its source
+func newInstructions(source, name, text string) customInstructions {
+	text = strings.TrimSpace(text)
+	return customInstructions{
+		InstructionSource: InstructionSource{Source: source, Name: name, Length: len(text)},
+		text:              text,
+	}
+}
+
+// instructionsSection renders every set of instructions in one prompt section.
+// Sets are ordered from broadest to most specific so later ones win conflicts.
+func instructionsSection(sets []customInstructions) PromptSection {
+	var builder strings.Builder
+	builder.WriteString("Apply these instructions in addition to the general review guidelines. When they conflict, later instructions take precedence.\n")
+
+	for _, set := range sets {
+		heading := map[string]string{
+			SourceConfig:   "Team",
+			SourcePolicy:   "Repository",
+			SourceArgument: "This review",
+		}[set.Source]
+		if set.Name != "" {
+			heading += " (" + set.Name + ")"
+		}
+		builder.WriteString("\n### " + heading + "\n")
+		builder.WriteString(set.text + "\n")
+	}
+
+	return PromptSection{Title: instructionsTitle, Content: builder.String()}
+}
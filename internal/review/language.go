@@ -0,0 +1,57 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dshills/mcp-pr/internal/lang"
+	"github.com/dshills/mcp-pr/internal/logging"
+)
+
+// detectLanguages fills in the language of a snippet reviewed without one
+// and, since diffs are shown to the model as a whole, adds the language of
+// each reviewed file to the prompt. The distinct languages are recorded in
+// req.Languages.
+func detectLanguages(ctx context.Context, req *Request) {
+	if len(req.Files) == 0 {
+		if req.Language == "" && req.SourceType == "arbitrary" {
+			req.Language = lang.Detect("", req.Code)
+			if req.Language != "" {
+				logging.Info(ctx, "Detected snippet language", "language", req.Language)
+			}
+		}
+		if req.Language != "" {
+			req.Languages = []string{req.Language}
+		}
+		return
+	}
+
+	seen := map[string]bool{}
+	var hints strings.Builder
+	for _, file := range req.Files {
+		if file.IsBinary || file.IsDeleted {
+			continue
+		}
+		language := lang.Detect(file.Path(), file.NewContent())
+		if language == "" {
+			continue
+		}
+		fmt.Fprintf(&hints, "- %s: %s\n", file.Path(), language)
+		if !seen[language] {
+			seen[language] = true
+			req.Languages = append(req.Languages, language)
+		}
+	}
+	if len(req.Languages) == 0 {
+		return
+	}
+
+	sort.Strings(req.Languages)
+	req.Sections = append(req.Sections, PromptSection{
+		Title:    "File languages",
+		Content:  "Review each file by the idioms and pitfalls of its language:\n" + hints.String(),
+		ReadOnly: true,
+	})
+}
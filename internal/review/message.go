@@ -0,0 +1,182 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dshills/mcp-pr/internal/commitmsg"
+	"github.com/dshills/mcp-pr/internal/git"
+	"github.com/dshills/mcp-pr/internal/logging"
+)
+
+// CommitMessageSource marks findings from the local commit message checks
+const CommitMessageSource = "commit-message"
+
+// recentSubjects is the number of earlier commit subjects shown as style examples
+const recentSubjects = 10
+
+// ErrNoStagedChanges is returned when there is nothing to draft a message for
+var ErrNoStagedChanges = errors.New("no staged changes to describe")
+
+// commitMessageGuidance tells the model how to review a commit message alongside the diff
+const commitMessageGuidance = `Review this commit message as well as the code. Check that it:
+- describes what the diff changes and why, without omitting significant changes or describing changes that are not in the diff
+- follows Conventional Commits: type(scope): description
+- marks a breaking change with "!" or a "BREAKING CHANGE:" footer when the diff breaks compatibility, e.g. removes or renames exported API, or changes behavior, configuration or data formats
+Report problems with the message as findings with category "style" and "file_path": "` + commitmsg.Path + `". A breaking change that is not marked is "high".`
+
+// reviewCommitMessage adds the message of a reviewed commit to the prompt and
+// returns the findings of the local message checks
+func (e *Engine) reviewCommitMessage(ctx context.Context, req *Request) []Finding {
+	if !req.ReviewMessage || req.SourceType != "commit" {
+		return nil
+	}
+
+	text, err := git.NewClient(req.RepositoryPath).CommitMessageContext(ctx, req.CommitSHA)
+	if err != nil {
+		logging.Warn(ctx, "Commit message unavailable", "error", err.Error())
+		return nil
+	}
+	msg := commitmsg.Parse(text)
+
+	var builder strings.Builder
+	builder.WriteString("```\n" + text + "\n```\n")
+	if msg.Conventional() {
+		fmt.Fprintf(&builder, "Format: Conventional Commits, type %q", msg.Type)
+		if msg.Scope != "" {
+			fmt.Fprintf(&builder, ", scope %q", msg.Scope)
+		}
+		builder.WriteString("\n")
+	} else {
+		builder.WriteString("Format: not Conventional Commits\n")
+	}
+	fmt.Fprintf(&builder, "Marked as breaking change: %t\n\n", msg.Breaking)
+	builder.WriteString(commitMessageGuidance)
+	req.Sections = append(req.Sections, PromptSection{
+		Title:   "Commit message",
+		Content: builder.String(),
+	})
+
+	var findings []Finding
+	for _, issue := range commitmsg.Check(msg) {
+		findings = append(findings, Finding{
+			Category:    "style",
+			Severity:    issue.Severity,
+			FilePath:    commitmsg.Path,
+			Description: issue.Message,
+			Suggestion:  issue.Suggestion,
+			CodeSnippet: msg.Subject,
+			Source:      CommitMessageSource,
+		})
+	}
+	return findings
+}
+
+// commitMessageSystemPrompt instructs the model to write a commit message
+var commitMessageSystemPrompt = `You write git commit messages for staged changes.
+Follow Conventional Commits: a subject "type(scope): description" of at most 72 characters, in the imperative mood, without a trailing period.
+Types: ` + strings.Join(commitmsg.Types, ", ") + `. The scope is optional; use the affected package or area.
+After a blank line, add a body wrapped at 72 characters explaining what changed and why, unless the change is trivial.
+If the change breaks compatibility, add "!" after the type or scope and a "BREAKING CHANGE: <what breaks and how to migrate>" footer.
+Respond with the commit message only, without a code fence or commentary.`
+
+// CommitMessageDraft is a commit message written by a provider for staged changes
+type CommitMessageDraft struct {
+	Message         string   `json:"message"`
+	Issues          []string `json:"issues,omitempty"` // Problems the local message checks still find
+	Provider        string   `json:"provider"`
+	Model           string   `json:"model,omitempty"`
+	InputTokens     int      `json:"input_tokens,omitempty"`
+	OutputTokens    int      `json:"output_tokens,omitempty"`
+	RedactedSecrets int      `json:"redacted_secrets,omitempty"` // Secrets replaced before the provider call
+}
+
+// DraftCommitMessage asks the provider for a commit message describing the
+// staged changes. Path filters, the repository policy's provider and model,
+// and team, repository and request instructions apply as for reviews, and
+// secrets are redacted from the diff before it is sent.
+func (e *Engine) DraftCommitMessage(ctx context.Context, req Request) (*CommitMessageDraft, error) {
+	req.SourceType = "staged"
+	redacted, err := e.prepareDiff(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Files) == 0 {
+		return nil, ErrNoStagedChanges
+	}
+
+	var prompt strings.Builder
+	if subjects, err := git.NewClient(req.RepositoryPath).RecentSubjectsContext(ctx, recentSubjects); err == nil && len(subjects) > 0 {
+		prompt.WriteString("Recent commit subjects in this repository, for style:\n")
+		for _, subject := range subjects {
+			prompt.WriteString("- " + subject + "\n")
+		}
+		prompt.WriteString("\n")
+	}
+	// resolveSettings gathered every set of instructions into one section
+	for _, section := range req.Sections {
+		if section.Title == instructionsTitle {
+			prompt.WriteString(section.Content + "\n")
+		}
+	}
+	prompt.WriteString("Staged changes:\n```diff\n" + req.Code + "\n```\n")
+
+	gen, err := e.generate(ctx, req.Provider, GenerateRequest{
+		System: commitMessageSystemPrompt,
+		Prompt: prompt.String(),
+		Model:  req.Model,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	message := strings.TrimSpace(stripCodeFence(gen.Text))
+	draft := &CommitMessageDraft{
+		Message:         message,
+		Provider:        gen.Provider,
+		Model:           gen.Model,
+		InputTokens:     gen.InputTokens,
+		OutputTokens:    gen.OutputTokens,
+		RedactedSecrets: redacted,
+	}
+	for _, issue := range commitmsg.Check(commitmsg.Parse(message)) {
+		draft.Issues = append(draft.Issues, issue.Message)
+	}
+	return draft, nil
+}
+
+// prepareDiff validates a git request, applies the repository policy, reads
+// and filters the diff, checks its size and redacts secrets, returning the
+// number of secrets redacted. It is the common start of tools that send a
+// diff to a provider without reviewing it.
+func (e *Engine) prepareDiff(ctx context.Context, req *Request) (int, error) {
+	if err := req.Validate(); err != nil {
+		return 0, fmt.Errorf("invalid request: %w", err)
+	}
+	settings, err := e.resolveSettings(req)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := e.populateCode(ctx, req); err != nil {
+		return 0, err
+	}
+	if len(req.Code) > settings.MaxDiffSize {
+		return 0, fmt.Errorf("diff size (%d bytes) exceeds maximum allowed size (%d bytes)", len(req.Code), settings.MaxDiffSize)
+	}
+	return len(redactSecrets(ctx, req)), nil
+}
+
+// stripCodeFence removes a markdown code fence around a model answer
+func stripCodeFence(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "```") {
+		return text
+	}
+	text = strings.TrimPrefix(text, "```")
+	if i := strings.Index(text, "\n"); i >= 0 {
+		text = text[i+1:] // Drop the language tag
+	}
+	return strings.TrimSuffix(strings.TrimSpace(text), "```")
+}
@@ -0,0 +1,172 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dshills/mcp-pr/internal/git"
+	"github.com/dshills/mcp-pr/internal/logging"
+)
+
+// maxPatchFileSize bounds the patch files read for patch reviews
+const maxPatchFileSize = 32 << 20
+
+// patchExtensions are the file types accepted as patch files
+var patchExtensions = []string{".patch", ".diff", ".mbox"}
+
+// Patch review errors
+var (
+	ErrPatchFileType = fmt.Errorf("patch file must end in %s", strings.Join(patchExtensions, ", "))
+	ErrPatchNoFiles  = errors.New("patch contains no file changes")
+)
+
+// PatchHeader describes one message of a reviewed mbox
+type PatchHeader struct {
+	Author  string `json:"author,omitempty"`
+	Date    string `json:"date,omitempty"`
+	Subject string `json:"subject,omitempty"`
+}
+
+// populatePatch reads, parses and filters the diff of a patch review. Mbox
+// messages contribute their author, subject and message as read-only context.
+func populatePatch(ctx context.Context, req *Request) ([]string, error) {
+	text := req.Code
+	if text == "" {
+		var err error
+		if text, err = readPatchFile(req.PatchPath); err != nil {
+			return nil, err
+		}
+	}
+
+	diff := text
+	if git.IsMbox(text) {
+		var diffs, descriptions []string
+		for _, patch := range git.ParseMbox(text) {
+			diffs = append(diffs, patch.Diff)
+			if patch.Subject == "" && patch.Author == "" {
+				continue
+			}
+			req.Patches = append(req.Patches, PatchHeader{Author: patch.Author, Date: patch.Date, Subject: patch.Subject})
+			description := fmt.Sprintf("Author: %s\nSubject: %s\n", patch.Author, patch.Subject)
+			if patch.Message != "" {
+				description += "\n" + patch.Message + "\n"
+			}
+			descriptions = append(descriptions, description)
+		}
+		diff = strings.Join(diffs, "\n")
+		if len(descriptions) > 0 {
+			req.Sections = append(req.Sections, PromptSection{
+				Title:    "Patch description",
+				Content:  strings.Join(descriptions, "\n"),
+				ReadOnly: true,
+			})
+		}
+	}
+
+	files, err := git.Parse(diff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse patch: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, ErrPatchNoFiles
+	}
+
+	var skipped []string
+	if filter := req.PathFilter(); !filter.IsEmpty() {
+		files, skipped = filter.Apply(files)
+		if len(skipped) > 0 {
+			logging.Info(ctx, "Path filters skipped files",
+				"skipped_count", len(skipped),
+				"kept_count", len(files),
+			)
+		}
+	}
+
+	logging.Info(ctx, "Patch parsed",
+		"file_count", len(files),
+		"patches", len(req.Patches),
+	)
+
+	// Only the diff is reviewed; mail headers and signatures are dropped
+	req.Code = git.Render(files)
+	req.Files = files
+	return skipped, nil
+}
+
+// readPatchFile reads a patch file, refusing other file types and oversized files
+func readPatchFile(path string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	accepted := false
+	for _, e := range patchExtensions {
+		accepted = accepted || ext == e
+	}
+	if !accepted {
+		return "", ErrPatchFileType
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read patch: %w", err)
+	}
+	if info.Size() > maxPatchFileSize {
+		return "", fmt.Errorf("patch file is %d bytes, larger than the %d bytes allowed", info.Size(), maxPatchFileSize)
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is checked to be a patch file above
+	if err != nil {
+		return "", fmt.Errorf("failed to read patch: %w", err)
+	}
+	return string(data), nil
+}
+
+// reviewedSource reads files as they are after the reviewed changes. Patch
+// reviews have no repository, so files are reconstructed from the patch.
+func reviewedSource(req *Request) func(ctx context.Context, filePath string) (string, error) {
+	if req.SourceType != "patch" {
+		return reviewedTree(req).ReadFile
+	}
+
+	files := req.Files
+	return func(ctx context.Context, filePath string) (string, error) {
+		for _, file := range files {
+			if file.Path() == filePath && !file.IsDeleted {
+				return file.NewContent(), nil
+			}
+		}
+		return "", fmt.Errorf("%s is not in the patch", filePath)
+	}
+}
+
+// anchorFindings ties patch and file review findings to the reviewed files:
+// a path the model reported with a/ or b/ prefixes is matched to the
+// reviewed file, and findings without a path belong to the only file of a
+// single-file review
+func anchorFindings(req Request, resp *Response) {
+	if (req.SourceType != "patch" && req.SourceType != "files") || len(req.Files) == 0 {
+		return
+	}
+
+	paths := map[string]bool{}
+	for _, file := range req.Files {
+		paths[file.Path()] = true
+	}
+
+	for i := range resp.Findings {
+		f := &resp.Findings[i]
+		switch {
+		case f.FilePath == "" && len(req.Files) == 1:
+			f.FilePath = req.Files[0].Path()
+		case f.FilePath != "" && !paths[f.FilePath]:
+			for _, prefix := range []string{"a/", "b/", "/"} {
+				if trimmed := strings.TrimPrefix(f.FilePath, prefix); paths[trimmed] {
+					f.FilePath = trimmed
+					break
+				}
+			}
+		}
+	}
+}
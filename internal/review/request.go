@@ -1,15 +1,79 @@
 package review
 
+import (
+	"strings"
+
+	"github.com/dshills/mcp-pr/internal/git"
+	"github.com/dshills/mcp-pr/internal/policy"
+)
+
 // Request represents a code review request
 type Request struct {
-	SourceType     string   // "arbitrary", "staged", "unstaged", "commit"
-	Code           string   // Raw code text (for arbitrary) or diff content
-	Provider       string   // "anthropic", "openai", "google"
-	Language       string   // Programming language hint (optional)
-	ReviewDepth    string   // "quick" or "thorough"
+	SourceType     string   // "arbitrary", "staged", "unstaged", "commit", "working_tree", "range", "patch", "files"
+	Code           string   // Raw code text (for arbitrary), patch text (for patch) or diff content
+	Provider       string   // "anthropic", "openai", "google" (empty = policy or server default)
+	Model          string   // Provider model override (empty = provider default)
+	Language       string   // Programming language of a snippet (empty = detected)
+	ReviewDepth    string   // "quick" or "thorough" (empty = policy or "quick")
 	FocusAreas     []string // Filter to specific categories (empty = all)
+	Instructions   string   // Extra review guidance for this request (optional)
+	FailOn         string   // Severity at or above which the review fails, or "none" (empty = engine default)
 	RepositoryPath string   // Path to git repository (for git-based reviews)
 	CommitSHA      string   // Git commit SHA (for commit reviews)
+	ReviewMessage  bool     // Also review the commit message against the diff (for commit reviews)
+	BaseRef        string   // Base revision; changes since its merge base with HeadRef are reviewed (for range reviews)
+	HeadRef        string   // Head revision (for range reviews; empty = HEAD)
+	PatchPath      string   // .patch, .diff or mbox file to read when Code is empty (for patch reviews)
+	Paths          []string // Files, directories or globs relative to the repository (for file reviews)
+
+	// Working tree categories (for working_tree reviews)
+	IncludeStaged    bool // Include staged changes
+	IncludeUnstaged  bool // Include unstaged changes to tracked files
+	IncludeUntracked bool // Include untracked, non-ignored files
+
+	// Path filters (for git-based, patch and file reviews)
+	IncludePaths       []string // Glob patterns of files to review (empty = all)
+	ExcludePaths       []string // Glob patterns of files to skip
+	UseDefaultExcludes *bool    // Skip lockfiles, minified assets and generated code (nil = policy or true)
+
+	// Source context (for git-based reviews)
+	ContextLines *int // Lines of surrounding source per change (nil = engine default, 0 = disabled)
+
+	// Files is the parsed diff, populated by the engine for git-based and patch reviews
+	Files []git.FileDiff
+
+	// Patches describe the messages of an mbox, populated by the engine for patch reviews
+	Patches []PatchHeader
+
+	// Languages are the distinct languages of the reviewed code, detected by the engine
+	Languages []string
+
+	// Sections hold supplementary prompt material added by the engine
+	Sections []PromptSection
+}
+
+// PromptSection is supplementary material included in the review prompt
+type PromptSection struct {
+	Title    string // Section heading
+	Content  string // Section body
+	ReadOnly bool   // Material is context only and must not be reviewed itself
+}
+
+// head returns the head revision of a range review
+func (r *Request) head() string {
+	if r.HeadRef == "" {
+		return "HEAD"
+	}
+	return r.HeadRef
+}
+
+// PathFilter returns the path filter described by the request
+func (r *Request) PathFilter() git.PathFilter {
+	return git.PathFilter{
+		Include:            r.IncludePaths,
+		Exclude:            r.ExcludePaths,
+		UseDefaultExcludes: r.UseDefaultExcludes == nil || *r.UseDefaultExcludes,
+	}
 }
 
 // Validate checks if the request is valid
@@ -22,21 +86,41 @@ func (r *Request) Validate() error {
 		return ErrEmptyCode
 	}
 
-	if r.SourceType != "arbitrary" && r.RepositoryPath == "" {
+	if r.SourceType == "patch" && r.Code == "" && r.PatchPath == "" {
+		return ErrEmptyPatch
+	}
+
+	if r.SourceType != "arbitrary" && r.SourceType != "patch" && r.RepositoryPath == "" {
 		return ErrMissingRepository
 	}
 
+	if r.SourceType == "files" && r.Code == "" && len(r.Paths) == 0 {
+		return ErrMissingPaths
+	}
+
 	if r.SourceType == "commit" && r.CommitSHA == "" {
 		return ErrMissingCommitSHA
 	}
 
-	if r.Provider == "" {
-		return ErrMissingProvider
+	if r.SourceType == "range" && r.BaseRef == "" {
+		return ErrMissingBaseRef
+	}
+
+	if r.SourceType == "working_tree" && !r.IncludeStaged && !r.IncludeUnstaged && !r.IncludeUntracked {
+		return ErrNoWorkingTreeChanges
 	}
 
 	if r.ReviewDepth != "" && r.ReviewDepth != "quick" && r.ReviewDepth != "thorough" {
 		return ErrInvalidReviewDepth
 	}
 
+	if r.FailOn != "" && !ValidFailOn(r.FailOn) {
+		return ErrInvalidFailOn
+	}
+
+	if len(strings.TrimSpace(r.Instructions)) > policy.MaxInstructions {
+		return ErrInstructionsTooLong
+	}
+
 	return nil
 }
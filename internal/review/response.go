@@ -7,8 +7,18 @@ type Response struct {
 	Findings []Finding     `json:"findings"`
 	Summary  string        `json:"summary"`
 	Provider string        `json:"provider"`
-	Duration time.Duration `json:"duration_ms"` // Will be serialized as milliseconds
+	Duration time.Duration `json:"duration_ms"`     // Will be serialized as milliseconds
+	Verdict  string        `json:"verdict"`         // VerdictPass or VerdictFail against the fail_on threshold
+	Counts   Counts        `json:"counts"`          // Findings by severity and category
+	Fixed    []Finding     `json:"fixed,omitempty"` // Findings of the previous review of the branch that are gone
 	Metadata *Metadata     `json:"metadata,omitempty"`
+
+	files []string // Old and new paths of the reviewed files, for WriteBaseline
+}
+
+// ReviewedFiles returns the old and new paths of the files the review covered
+func (r *Response) ReviewedFiles() []string {
+	return r.files
 }
 
 // Finding represents a single code issue
@@ -20,6 +30,12 @@ type Finding struct {
 	Description string `json:"description"`            // Issue explanation
 	Suggestion  string `json:"suggestion"`             // Remediation advice
 	CodeSnippet string `json:"code_snippet,omitempty"` // Relevant code excerpt
+	Source      string `json:"source,omitempty"`       // Origin when not the LLM, e.g. an analyzer name
+	Fingerprint string `json:"fingerprint,omitempty"`  // Stable identity of the finding across reviews
+	Suppressed  string `json:"suppressed,omitempty"`   // SuppressedByBaseline, SuppressedByComment or SuppressedByTriage when suppressed
+	Status      string `json:"status,omitempty"`       // Lifecycle relative to the previous review of the branch, e.g. StatusNew
+	Triage      string `json:"triage,omitempty"`       // Triage state recorded by the team, e.g. "false-positive"
+	TriageNote  string `json:"triage_note,omitempty"`  // Note recorded with the triage state
 }
 
 // Metadata provides additional context about the review
@@ -30,4 +46,25 @@ type Metadata struct {
 	LinesAdded   int    `json:"lines_added,omitempty"`
 	LinesRemoved int    `json:"lines_removed,omitempty"`
 	Model        string `json:"model,omitempty"` // Specific LLM model used
+	InputTokens  int    `json:"input_tokens,omitempty"`
+	OutputTokens int    `json:"output_tokens,omitempty"`
+
+	SkippedFiles []string `json:"skipped_files,omitempty"` // Files removed by path filters
+
+	Patches []PatchHeader `json:"patches,omitempty"` // Messages of a reviewed mbox
+
+	Languages []string `json:"languages,omitempty"` // Languages of the reviewed code, given or detected
+
+	RedactedSecrets int  `json:"redacted_secrets,omitempty"` // Secrets replaced before the provider call
+	Blocked         bool `json:"blocked,omitempty"`          // Not sent to the provider because secrets were detected
+
+	BaselineFile string `json:"baseline_file,omitempty"` // Baseline used to suppress known findings
+
+	HistoryID        string `json:"history_id,omitempty"`         // ID of the review in the history store
+	PreviousReviewID string `json:"previous_review_id,omitempty"` // Review of the same branch that statuses are relative to
+
+	Settings *Settings `json:"settings,omitempty"` // Effective settings and where they came from
+
+	Analyzers            []string `json:"analyzers,omitempty"`             // Local analyzers that ran
+	UnavailableAnalyzers []string `json:"unavailable_analyzers,omitempty"` // Analyzers that were missing or failed to run
 }
@@ -0,0 +1,194 @@
+package review
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dshills/mcp-pr/internal/git"
+	"github.com/dshills/mcp-pr/internal/policy"
+)
+
+// Setting sources, from highest to lowest precedence
+const (
+	SourceArgument = "argument" // Tool argument or request field
+	SourcePolicy   = "policy"   // Repository .mcp-pr.yaml
+	SourceConfig   = "config"   // Server configuration (flags, environment or config file)
+	SourceDefault  = "default"  // Built-in default
+)
+
+// Settings are the effective review settings after layering tool arguments,
+// the repository policy file and server configuration
+type Settings struct {
+	PolicyFile         string              `json:"policy_file,omitempty"`
+	Provider           string              `json:"provider"`
+	Model              string              `json:"model,omitempty"`
+	ReviewDepth        string              `json:"review_depth"`
+	FocusAreas         []string            `json:"focus_areas,omitempty"`
+	IncludePaths       []string            `json:"include_paths,omitempty"`
+	ExcludePaths       []string            `json:"exclude_paths,omitempty"`
+	UseDefaultExcludes bool                `json:"use_default_excludes"`
+	MinSeverity        string              `json:"min_severity,omitempty"`
+	FailOn             string              `json:"fail_on"`
+	MaxDiffSize        int                 `json:"max_diff_size"`
+	Instructions       []InstructionSource `json:"instructions,omitempty"` // Custom instructions added to the prompt
+	Sources            map[string]string   `json:"sources"`                // Setting name to the layer it came from
+}
+
+// resolveSettings loads the repository policy, fills unset request fields
+// from it and from the server configuration, and reports the result
+func (e *Engine) resolveSettings(req *Request) (*Settings, error) {
+	pol := &policy.Policy{}
+	settings := &Settings{Sources: map[string]string{}}
+
+	var root string
+	if req.RepositoryPath != "" {
+		// A path that is not a repository fails later with a clearer git error
+		if repoRoot, err := git.NewClient(req.RepositoryPath).GetRepositoryRoot(); err == nil {
+			root = repoRoot
+			loaded, path, err := policy.Load(root)
+			if err != nil {
+				return nil, err
+			}
+			if loaded != nil {
+				pol = loaded
+				settings.PolicyFile = path
+			}
+		}
+	}
+
+	switch {
+	case req.Provider != "":
+		settings.Sources["provider"] = SourceArgument
+	case pol.Provider != "":
+		req.Provider = pol.Provider
+		settings.Sources["provider"] = SourcePolicy
+	default:
+		req.Provider = e.defaultProvider
+		settings.Sources["provider"] = SourceConfig
+	}
+
+	switch {
+	case req.Model != "":
+		settings.Sources["model"] = SourceArgument
+	case pol.Model != "" && pol.Provider == req.Provider:
+		req.Model = pol.Model
+		settings.Sources["model"] = SourcePolicy
+	}
+
+	switch {
+	case req.ReviewDepth != "":
+		settings.Sources["review_depth"] = SourceArgument
+	case pol.ReviewDepth != "":
+		req.ReviewDepth = pol.ReviewDepth
+		settings.Sources["review_depth"] = SourcePolicy
+	default:
+		req.ReviewDepth = "quick"
+		settings.Sources["review_depth"] = SourceDefault
+	}
+
+	switch {
+	case len(req.FocusAreas) > 0:
+		settings.Sources["focus_areas"] = SourceArgument
+	case len(pol.FocusAreas) > 0:
+		req.FocusAreas = pol.FocusAreas
+		settings.Sources["focus_areas"] = SourcePolicy
+	}
+
+	switch {
+	case len(req.IncludePaths) > 0:
+		settings.Sources["include_paths"] = SourceArgument
+	case len(pol.IncludePaths) > 0:
+		req.IncludePaths = pol.IncludePaths
+		settings.Sources["include_paths"] = SourcePolicy
+	}
+
+	switch {
+	case len(req.ExcludePaths) > 0:
+		settings.Sources["exclude_paths"] = SourceArgument
+	case len(pol.ExcludePaths) > 0:
+		req.ExcludePaths = pol.ExcludePaths
+		settings.Sources["exclude_paths"] = SourcePolicy
+	}
+
+	switch {
+	case req.UseDefaultExcludes != nil:
+		settings.Sources["use_default_excludes"] = SourceArgument
+	case pol.UseDefaultExcludes != nil:
+		req.UseDefaultExcludes = pol.UseDefaultExcludes
+		settings.Sources["use_default_excludes"] = SourcePolicy
+	default:
+		settings.Sources["use_default_excludes"] = SourceDefault
+	}
+
+	// A repository may lower the server's limit but never raise it
+	settings.MaxDiffSize = e.maxDiffSize
+	settings.Sources["max_diff_size"] = e.maxDiffSource
+	if pol.MaxDiffSize > 0 && pol.MaxDiffSize < e.maxDiffSize {
+		settings.MaxDiffSize = pol.MaxDiffSize
+		settings.Sources["max_diff_size"] = SourcePolicy
+	}
+
+	switch {
+	case req.FailOn != "":
+		settings.FailOn = req.FailOn
+		settings.Sources["fail_on"] = SourceArgument
+	case pol.FailOn != "":
+		settings.FailOn = pol.FailOn
+		settings.Sources["fail_on"] = SourcePolicy
+	default:
+		settings.FailOn = e.failOn
+		settings.Sources["fail_on"] = SourceConfig
+	}
+
+	if pol.MinSeverity != "" {
+		settings.MinSeverity = pol.MinSeverity
+		settings.Sources["min_severity"] = SourcePolicy
+	}
+
+	instructions := append([]customInstructions(nil), e.instructions...)
+	if pol.Instructions != "" {
+		instructions = append(instructions, newInstructions(SourcePolicy, policy.FileName, pol.Instructions))
+	}
+	if pol.InstructionsFile != "" {
+		text, err := readRepoInstructionsFile(root, pol.InstructionsFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", policy.FileName, err)
+		}
+		instructions = append(instructions, newInstructions(SourcePolicy, pol.InstructionsFile, text))
+	}
+	if strings.TrimSpace(req.Instructions) != "" {
+		instructions = append(instructions, newInstructions(SourceArgument, "", req.Instructions))
+	}
+	if len(instructions) > 0 {
+		req.Sections = append(req.Sections, instructionsSection(instructions))
+		for _, set := range instructions {
+			settings.Instructions = append(settings.Instructions, set.InstructionSource)
+		}
+	}
+
+	settings.Provider = req.Provider
+	settings.Model = req.Model
+	settings.ReviewDepth = req.ReviewDepth
+	settings.FocusAreas = req.FocusAreas
+	settings.IncludePaths = req.IncludePaths
+	settings.ExcludePaths = req.ExcludePaths
+	settings.UseDefaultExcludes = req.PathFilter().UseDefaultExcludes
+
+	return settings, nil
+}
+
+// filterBySeverity drops findings less severe than the minimum
+func filterBySeverity(findings []Finding, minSeverity string) []Finding {
+	if minSeverity == "" {
+		return findings
+	}
+
+	limit := policy.SeverityRank(minSeverity)
+	kept := make([]Finding, 0, len(findings))
+	for _, f := range findings {
+		if policy.SeverityRank(f.Severity) <= limit {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
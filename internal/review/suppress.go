@@ -0,0 +1,191 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"github.com/dshills/mcp-pr/internal/baseline"
+	"github.com/dshills/mcp-pr/internal/git"
+	"github.com/dshills/mcp-pr/internal/logging"
+)
+
+// Reasons a finding is suppressed
+const (
+	SuppressedByBaseline = "baseline" // Fingerprint is in the repository baseline
+	SuppressedByComment  = "comment"  // An mcp-pr:ignore comment covers the line
+	SuppressedByTriage   = "triage"   // Triaged as a false positive or won't fix
+)
+
+// SecretSource marks findings from the secret scanner, which are never suppressed
+const SecretSource = "secret-scanner"
+
+// ErrBaselineRequiresRepository is returned when writing a baseline for a non-git review
+var ErrBaselineRequiresRepository = errors.New("baselines can only be written for git-based reviews")
+
+// suppressKnown fingerprints every finding and marks those covered by an
+// inline ignore comment or by the repository baseline
+func suppressKnown(ctx context.Context, req Request, resp *Response) {
+	var known *baseline.Baseline
+	if path := baselinePath(req); path != "" {
+		loaded, err := baseline.Load(path)
+		if err != nil {
+			logging.Warn(ctx, "Ignoring unreadable baseline", "error", err.Error())
+		} else if loaded != nil {
+			known = loaded
+			resp.Metadata.BaselineFile = path
+		}
+	}
+
+	lines := sourceLines(ctx, req)
+	for i := range resp.Findings {
+		f := &resp.Findings[i]
+
+		// Secrets are fingerprinted by their masked preview and always reported
+		if f.Source == SecretSource {
+			f.Fingerprint = baseline.Fingerprint(f.FilePath, f.Category, f.CodeSnippet)
+			continue
+		}
+
+		source := lines(f.FilePath)
+		f.Fingerprint = baseline.Fingerprint(f.FilePath, f.Category, findingSnippet(*f, source))
+
+		switch {
+		case ignoredInline(*f, source):
+			f.Suppressed = SuppressedByComment
+		case known.Contains(f.Fingerprint):
+			f.Suppressed = SuppressedByBaseline
+		}
+	}
+}
+
+// WriteBaseline reviews a git request and records its findings in the
+// repository baseline so later reviews suppress them. Entries for the
+// reviewed files are replaced; entries for other files are kept. Findings
+// already ignored by comments and secret findings are not recorded.
+func (e *Engine) WriteBaseline(ctx context.Context, req Request) (*Response, error) {
+	if req.SourceType == "arbitrary" {
+		return nil, ErrBaselineRequiresRepository
+	}
+
+	resp, err := e.Review(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	path := baselinePath(req)
+	if path == "" {
+		return nil, ErrBaselineRequiresRepository
+	}
+
+	existing, err := baseline.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	reviewed := map[string]bool{}
+	for _, filePath := range resp.files {
+		reviewed[filePath] = true
+	}
+
+	var entries []baseline.Entry
+	kept := 0
+	if existing != nil {
+		for _, entry := range existing.Entries {
+			if !reviewed[entry.FilePath] {
+				entries = append(entries, entry)
+				kept++
+			}
+		}
+	}
+	for i := range resp.Findings {
+		f := &resp.Findings[i]
+		if f.Source == SecretSource || f.Suppressed == SuppressedByComment {
+			continue
+		}
+		entries = append(entries, baseline.Entry{
+			Fingerprint: f.Fingerprint,
+			FilePath:    f.FilePath,
+			Category:    f.Category,
+			Severity:    f.Severity,
+			Description: f.Description,
+		})
+		f.Suppressed = SuppressedByBaseline
+	}
+
+	if err := baseline.New(entries).Save(path); err != nil {
+		return nil, err
+	}
+	logging.Info(ctx, "Baseline written", "path", path, "findings", len(entries)-kept, "kept", kept)
+
+	resp.Metadata.BaselineFile = path
+	applyVerdict(resp, resp.Metadata.Settings.FailOn)
+	return resp, nil
+}
+
+// baselinePath returns the baseline file for a git request, or "" for other reviews
+func baselinePath(req Request) string {
+	if req.RepositoryPath == "" {
+		return ""
+	}
+	root, err := git.NewClient(req.RepositoryPath).GetRepositoryRoot()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(root, baseline.FileName)
+}
+
+// sourceLines returns a cached lookup of the reviewed lines of a file. Git
+// reviews read the reviewed version of the repository and patch reviews the
+// lines shown in the patch; other reviews use the submitted code for
+// findings without a file path.
+func sourceLines(ctx context.Context, req Request) func(filePath string) []string {
+	cache := map[string][]string{}
+
+	return func(filePath string) []string {
+		if lines, ok := cache[filePath]; ok {
+			return lines
+		}
+
+		var content string
+		switch {
+		case len(req.Files) > 0 && filePath != "":
+			content, _ = reviewedSource(&req)(ctx, filePath)
+		case len(req.Files) == 0 && filePath == "":
+			content = req.Code
+		}
+
+		lines := strings.Split(content, "\n")
+		cache[filePath] = lines
+		return lines
+	}
+}
+
+// findingSnippet returns the code a finding is fingerprinted by: the
+// flagged source line when it is known, otherwise the reported snippet or,
+// failing that, the description
+func findingSnippet(f Finding, source []string) string {
+	if f.Line != nil && *f.Line >= 1 && *f.Line <= len(source) {
+		if line := strings.TrimSpace(source[*f.Line-1]); line != "" {
+			return line
+		}
+	}
+	if f.CodeSnippet != "" {
+		return f.CodeSnippet
+	}
+	return f.Description
+}
+
+// ignoredInline reports whether an ignore comment on the flagged line or the
+// line above covers the finding's category
+func ignoredInline(f Finding, source []string) bool {
+	if f.Line == nil {
+		return false
+	}
+	for _, n := range []int{*f.Line, *f.Line - 1} {
+		if n >= 1 && n <= len(source) && baseline.Ignores(source[n-1], f.Category) {
+			return true
+		}
+	}
+	return false
+}
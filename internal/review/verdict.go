@@ -0,0 +1,70 @@
+package review
+
+import "github.com/dshills/mcp-pr/internal/policy"
+
+// Review verdicts
+const (
+	VerdictPass = "pass"
+	VerdictFail = "fail"
+)
+
+// FailOnNone never fails a review
+const FailOnNone = "none"
+
+// DefaultFailOn is the severity threshold used when none is configured; the
+// verdict only gates reviews whose operator or repository opts in
+const DefaultFailOn = FailOnNone
+
+// Finding lifecycle statuses relative to the previous review of the same branch
+const (
+	StatusNew          = "new"           // Not reported by any earlier review of the branch
+	StatusStillPresent = "still-present" // Also reported by the previous review
+	StatusFixed        = "fixed"         // Reported by the previous review but not this one
+	StatusRegressed    = "regressed"     // Fixed in the previous review but reported again
+)
+
+// Counts tallies reported findings by severity and category; suppressed
+// findings are only counted in Suppressed
+type Counts struct {
+	BySeverity map[string]int `json:"by_severity"`
+	ByCategory map[string]int `json:"by_category"`
+	ByStatus   map[string]int `json:"by_status,omitempty"` // Set when the review is compared with history
+	Suppressed int            `json:"suppressed"`
+}
+
+// ValidFailOn reports whether a value is a severity or "none"
+func ValidFailOn(failOn string) bool {
+	return failOn == FailOnNone || policy.IsSeverity(failOn)
+}
+
+// applyVerdict counts the findings and fails the review when any unsuppressed
+// finding is at least as severe as the threshold
+func applyVerdict(resp *Response, failOn string) {
+	resp.Verdict = VerdictPass
+	resp.Counts = Counts{
+		BySeverity: map[string]int{},
+		ByCategory: map[string]int{},
+		ByStatus:   map[string]int{},
+	}
+
+	limit := policy.SeverityRank(failOn)
+	for _, f := range resp.Findings {
+		if f.Suppressed != "" {
+			resp.Counts.Suppressed++
+			continue
+		}
+		resp.Counts.BySeverity[f.Severity]++
+		resp.Counts.ByCategory[f.Category]++
+		if f.Status != "" {
+			resp.Counts.ByStatus[f.Status]++
+		}
+		if failOn != FailOnNone && policy.SeverityRank(f.Severity) <= limit {
+			resp.Verdict = VerdictFail
+		}
+	}
+	for _, f := range resp.Fixed {
+		if f.Suppressed == "" {
+			resp.Counts.ByStatus[StatusFixed]++
+		}
+	}
+}
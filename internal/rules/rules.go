@@ -0,0 +1,352 @@
+package rules
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/dshills/mcp-pr/internal/git"
+)
+
+// Valid finding categories and severities, matching review.Finding
+var (
+	validCategories = []string{"bug", "security", "performance", "style", "best-practice"}
+	validSeverities = []string{"critical", "high", "medium", "low", "info"}
+)
+
+// Source reads the post-change contents of a file
+type Source func(ctx context.Context, path string) (string, error)
+
+// Rule is a deterministic check evaluated against added lines.
+// Exactly one of Pattern or Call must be set.
+type Rule struct {
+	ID           string   `yaml:"id"`
+	Pattern      string   `yaml:"pattern"`       // Regular expression matched against each added line
+	Unless       string   `yaml:"unless"`        // Regular expression that suppresses a Pattern match on the same line
+	Call         string   `yaml:"call"`          // Go call to flag, e.g. "fmt.Println", "time.Sleep" or "panic"
+	Function     string   `yaml:"function"`      // Only flag calls inside functions whose name matches this expression
+	Paths        []string `yaml:"paths"`         // Globs the rule applies to (all files when empty)
+	ExcludePaths []string `yaml:"exclude_paths"` // Globs the rule never applies to
+	Category     string   `yaml:"category"`
+	Severity     string   `yaml:"severity"`
+	Message      string   `yaml:"message"`
+	Suggestion   string   `yaml:"suggestion"`
+
+	pattern   *regexp.Regexp
+	unless    *regexp.Regexp
+	function  *regexp.Regexp
+	qualifier string // Package part of Call ("" for builtins and local functions)
+	name      string // Function part of Call
+}
+
+// Set is a validated collection of rules
+type Set struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// Match is a rule violation on an added line
+type Match struct {
+	RuleID     string
+	FilePath   string
+	Line       int
+	Category   string
+	Severity   string
+	Message    string
+	Suggestion string
+	Snippet    string // The offending line, trimmed
+}
+
+// Load reads and validates a rule file
+func Load(path string) (*Set, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: rule file path comes from server configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rule file: %w", err)
+	}
+
+	set, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rule file %s: %w", path, err)
+	}
+	return set, nil
+}
+
+// Parse decodes and validates rules from YAML; unknown keys are rejected
+func Parse(data []byte) (*Set, error) {
+	var set Set
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&set); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	for i := range set.Rules {
+		rule := &set.Rules[i]
+		if err := rule.compile(); err != nil {
+			return nil, fmt.Errorf("rule %d (%s): %w", i+1, rule.ID, err)
+		}
+		if seen[rule.ID] {
+			return nil, fmt.Errorf("rule %d: duplicate id %q", i+1, rule.ID)
+		}
+		seen[rule.ID] = true
+	}
+
+	return &set, nil
+}
+
+// compile validates a rule and prepares its matchers
+func (r *Rule) compile() error {
+	if r.ID == "" {
+		return errors.New("id is required")
+	}
+	if r.Message == "" {
+		return errors.New("message is required")
+	}
+	if !contains(validCategories, r.Category) {
+		return fmt.Errorf("category must be one of %s", strings.Join(validCategories, ", "))
+	}
+	if !contains(validSeverities, r.Severity) {
+		return fmt.Errorf("severity must be one of %s", strings.Join(validSeverities, ", "))
+	}
+	if (r.Pattern == "") == (r.Call == "") {
+		return errors.New("exactly one of pattern or call is required")
+	}
+
+	var err error
+	if r.Pattern != "" {
+		if r.pattern, err = regexp.Compile(r.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+		if r.Function != "" {
+			return errors.New("function can only be used with call")
+		}
+	}
+	if r.Unless != "" {
+		if r.Pattern == "" {
+			return errors.New("unless can only be used with pattern")
+		}
+		if r.unless, err = regexp.Compile(r.Unless); err != nil {
+			return fmt.Errorf("invalid unless: %w", err)
+		}
+	}
+	if r.Function != "" {
+		if r.function, err = regexp.Compile(r.Function); err != nil {
+			return fmt.Errorf("invalid function: %w", err)
+		}
+	}
+	if r.Call != "" {
+		if idx := strings.LastIndex(r.Call, "."); idx >= 0 {
+			r.qualifier, r.name = r.Call[:idx], r.Call[idx+1:]
+		} else {
+			r.name = r.Call
+		}
+		if r.name == "" {
+			return fmt.Errorf("invalid call %q", r.Call)
+		}
+	}
+
+	return nil
+}
+
+// appliesTo reports whether a rule covers a file
+func (r *Rule) appliesTo(filePath string) bool {
+	if len(r.Paths) > 0 && !git.MatchAny(r.Paths, filePath) {
+		return false
+	}
+	if r.Call != "" && !strings.HasSuffix(filePath, ".go") {
+		return false
+	}
+	return !git.MatchAny(r.ExcludePaths, filePath)
+}
+
+// Evaluate checks added lines of each file against the rules. Source is used
+// to read whole Go files for call rules; it may be nil when there are none.
+func (s *Set) Evaluate(ctx context.Context, files []git.FileDiff, source Source) []Match {
+	if s == nil || len(s.Rules) == 0 {
+		return nil
+	}
+
+	var matches []Match
+
+	for _, file := range files {
+		if file.IsDeleted || file.IsBinary {
+			continue
+		}
+
+		filePath := file.Path()
+		added := file.AddedLines()
+		if len(added) == 0 {
+			continue
+		}
+
+		var calls []callSite
+		callsLoaded := false
+
+		for i := range s.Rules {
+			rule := &s.Rules[i]
+			if !rule.appliesTo(filePath) {
+				continue
+			}
+
+			if rule.pattern != nil {
+				for _, line := range added {
+					if !rule.pattern.MatchString(line.Text) || (rule.unless != nil && rule.unless.MatchString(line.Text)) {
+						continue
+					}
+					matches = append(matches, rule.match(filePath, line))
+				}
+				continue
+			}
+
+			if !callsLoaded {
+				calls = goCalls(ctx, filePath, added, source)
+				callsLoaded = true
+			}
+			for _, call := range calls {
+				if call.matches(rule) {
+					matches = append(matches, rule.match(filePath, call.line))
+				}
+			}
+		}
+	}
+
+	return matches
+}
+
+// match builds a Match for a rule at a line
+func (r *Rule) match(filePath string, line git.Line) Match {
+	return Match{
+		RuleID:     r.ID,
+		FilePath:   filePath,
+		Line:       line.Number,
+		Category:   r.Category,
+		Severity:   r.Severity,
+		Message:    r.Message,
+		Suggestion: r.Suggestion,
+		Snippet:    strings.TrimSpace(line.Text),
+	}
+}
+
+// callSite is a function call on an added line of a Go file
+type callSite struct {
+	line       git.Line
+	importPath string // Import path of the called package ("" for builtins and local functions)
+	localName  string // Package name as written in the file
+	name       string // Called function
+	function   string // Enclosing function name
+}
+
+// matches reports whether a call violates a rule
+func (c callSite) matches(r *Rule) bool {
+	if c.name != r.name {
+		return false
+	}
+	if r.qualifier == "" {
+		if c.importPath != "" {
+			return false
+		}
+	} else if r.qualifier != c.importPath && r.qualifier != c.localName {
+		return false
+	}
+	return r.function == nil || r.function.MatchString(c.function)
+}
+
+// goCalls parses a Go file and returns calls made on added lines
+func goCalls(ctx context.Context, filePath string, added []git.Line, source Source) []callSite {
+	if source == nil {
+		return nil
+	}
+
+	content, err := source(ctx, filePath)
+	if err != nil {
+		return nil
+	}
+
+	fset := token.NewFileSet()
+	// Partially parsed files still yield useful call sites, so parse errors are ignored
+	file, _ := parser.ParseFile(fset, filePath, content, parser.SkipObjectResolution)
+	if file == nil {
+		return nil
+	}
+
+	addedLines := map[int]git.Line{}
+	for _, line := range added {
+		addedLines[line.Number] = line
+	}
+
+	// Map local package names to import paths
+	imports := map[string]string{}
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		localName := importPath[strings.LastIndex(importPath, "/")+1:]
+		if spec.Name != nil {
+			localName = spec.Name.Name
+		}
+		imports[localName] = importPath
+	}
+
+	var calls []callSite
+	for _, decl := range file.Decls {
+		funcName := ""
+		if fn, ok := decl.(*ast.FuncDecl); ok {
+			funcName = fn.Name.Name
+		}
+
+		ast.Inspect(decl, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			line, ok := addedLines[fset.Position(call.Pos()).Line]
+			if !ok {
+				return true
+			}
+
+			site := callSite{line: line, function: funcName}
+			switch fun := call.Fun.(type) {
+			case *ast.Ident:
+				site.name = fun.Name
+			case *ast.SelectorExpr:
+				pkg, ok := fun.X.(*ast.Ident)
+				if !ok || imports[pkg.Name] == "" {
+					return true
+				}
+				site.localName = pkg.Name
+				site.importPath = imports[pkg.Name]
+				site.name = fun.Sel.Name
+			default:
+				return true
+			}
+
+			calls = append(calls, site)
+			return true
+		})
+	}
+
+	return calls
+}
+
+// contains reports whether a slice contains a value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,194 @@
+package secrets
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// Rule detects one kind of secret
+type Rule struct {
+	ID          string         // Stable identifier used in redaction markers, e.g. "aws-access-key-id"
+	Description string         // Human-readable name
+	Pattern     *regexp.Regexp // Capture group 1 holds the secret value
+	MinEntropy  float64        // Minimum Shannon entropy (bits per character) of the value; 0 disables the check
+}
+
+// Match is a detected secret
+type Match struct {
+	RuleID      string
+	Description string
+	Line        int    // 1-based line within the scanned text
+	Value       string // The secret itself; never log or return this unmasked
+}
+
+// privateKeyRuleID identifies PEM private key blocks, which span several lines
+const privateKeyRuleID = "private-key"
+
+var (
+	privateKeyBegin = regexp.MustCompile(`-----BEGIN ([A-Z0-9]+ )*PRIVATE KEY( BLOCK)?-----`)
+	privateKeyEnd   = regexp.MustCompile(`-----END ([A-Z0-9]+ )*PRIVATE KEY( BLOCK)?-----`)
+)
+
+// rules are checked in order; earlier rules win when patterns overlap
+var rules = []Rule{
+	{
+		ID:          "aws-access-key-id",
+		Description: "AWS access key ID",
+		Pattern:     regexp.MustCompile(`\b((?:AKIA|ASIA|AGPA|AIDA|AROA|ANPA|ANVA|AIPA)[0-9A-Z]{16})\b`),
+	},
+	{
+		ID:          "aws-secret-access-key",
+		Description: "AWS secret access key",
+		Pattern:     regexp.MustCompile(`(?i)aws.{0,20}?(?:secret|key).{0,20}?[=:\s]\s*["']?([A-Za-z0-9/+]{40})(?:[^A-Za-z0-9/+]|$)`),
+		MinEntropy:  4.0,
+	},
+	{
+		ID:          "github-token",
+		Description: "GitHub token",
+		Pattern:     regexp.MustCompile(`\b((?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36,255})\b`),
+		MinEntropy:  3.0,
+	},
+	{
+		ID:          "github-fine-grained-token",
+		Description: "GitHub fine-grained personal access token",
+		Pattern:     regexp.MustCompile(`\b(github_pat_[A-Za-z0-9_]{22,255})\b`),
+		MinEntropy:  3.0,
+	},
+	{
+		ID:          "anthropic-api-key",
+		Description: "Anthropic API key",
+		Pattern:     regexp.MustCompile(`\b(sk-ant-[A-Za-z0-9_\-]{20,})`),
+		MinEntropy:  3.0,
+	},
+	{
+		ID:          "openai-api-key",
+		Description: "OpenAI API key",
+		Pattern:     regexp.MustCompile(`\b(sk-(?:proj-|svcacct-)?[A-Za-z0-9_\-]{32,})`),
+		MinEntropy:  3.0,
+	},
+	{
+		ID:          "google-api-key",
+		Description: "Google API key",
+		Pattern:     regexp.MustCompile(`\b(AIza[0-9A-Za-z_\-]{35})`),
+		MinEntropy:  3.0,
+	},
+	{
+		ID:          "slack-token",
+		Description: "Slack token",
+		Pattern:     regexp.MustCompile(`\b(xox[abprs]-[0-9A-Za-z\-]{10,})`),
+		MinEntropy:  3.0,
+	},
+	{
+		ID:          "generic-secret",
+		Description: "hard-coded secret",
+		Pattern:     regexp.MustCompile(`(?i)(?:api[_-]?key|secret|token|passwd|password|credential)s?["']?\s*[:=]+\s*["']([^"'\s]{16,})["']`),
+		MinEntropy:  3.5,
+	},
+}
+
+// Scanner redacts secrets line by line, tracking private key blocks that span lines
+type Scanner struct {
+	inPrivateKey bool
+}
+
+// Line redacts secrets in a single line and returns the redacted text and matches.
+// The matches have Line set to 0; callers assign line numbers.
+func (s *Scanner) Line(text string) (string, []Match) {
+	if s.inPrivateKey {
+		if privateKeyEnd.MatchString(text) {
+			s.inPrivateKey = false
+			return text, nil
+		}
+		if strings.TrimSpace(text) == "" {
+			return text, nil
+		}
+		return marker(privateKeyRuleID), nil
+	}
+
+	var matches []Match
+
+	if privateKeyBegin.MatchString(text) {
+		s.inPrivateKey = !privateKeyEnd.MatchString(text)
+		matches = append(matches, Match{RuleID: privateKeyRuleID, Description: "private key", Value: text})
+		if !s.inPrivateKey {
+			// Single-line key: redact everything between the markers
+			return marker(privateKeyRuleID), matches
+		}
+		return text, matches
+	}
+
+	for _, rule := range rules {
+		text = rule.Pattern.ReplaceAllStringFunc(text, func(found string) string {
+			sub := rule.Pattern.FindStringSubmatchIndex(found)
+			if sub == nil || sub[2] < 0 {
+				return found
+			}
+			value := found[sub[2]:sub[3]]
+			if strings.Contains(value, "[REDACTED:") {
+				return found // Already redacted by an earlier, more specific rule
+			}
+			if rule.MinEntropy > 0 && Entropy(value) < rule.MinEntropy {
+				return found
+			}
+			matches = append(matches, Match{RuleID: rule.ID, Description: rule.Description, Value: value})
+			return found[:sub[2]] + marker(rule.ID) + found[sub[3]:]
+		})
+	}
+
+	return text, matches
+}
+
+// Redact scans multi-line text and returns it with secrets replaced by markers.
+// Line structure is preserved so diffs remain valid.
+func Redact(text string) (string, []Match) {
+	var scanner Scanner
+	var matches []Match
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		redacted, found := scanner.Line(line)
+		for _, match := range found {
+			match.Line = i + 1
+			matches = append(matches, match)
+		}
+		lines[i] = redacted
+	}
+
+	return strings.Join(lines, "\n"), matches
+}
+
+// Entropy returns the Shannon entropy of a string in bits per character
+func Entropy(value string) float64 {
+	if value == "" {
+		return 0
+	}
+
+	counts := map[rune]int{}
+	total := 0
+	for _, r := range value {
+		counts[r]++
+		total++
+	}
+
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// Mask returns a short non-reversible preview of a secret
+func Mask(value string) string {
+	if len(value) <= 8 {
+		return "****"
+	}
+	return fmt.Sprintf("%s...%s", value[:4], value[len(value)-2:])
+}
+
+// marker is the replacement text for a redacted secret
+func marker(ruleID string) string {
+	return "[REDACTED:" + ruleID + "]"
+}
@@ -1,6 +1,7 @@
 package integration
 
 import (
+	"context"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -241,3 +242,56 @@ func TestGitClientEmptyStagedArea(t *testing.T) {
 		t.Errorf("GetStagedDiff() = %v, want empty string for no staged changes", diff)
 	}
 }
+
+// TestGitClientWorkingTreeDiff tests combining staged, unstaged and untracked changes
+func TestGitClientWorkingTreeDiff(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	// Create initial commit
+	createAndStageFile(t, repoPath, "README.md", "# Test\n")
+	createAndStageFile(t, repoPath, ".gitignore", "ignored.log\n")
+	commitChanges(t, repoPath, "Initial commit")
+
+	// One change per category, plus an ignored file
+	createAndStageFile(t, repoPath, "staged.go", "package staged\n")
+	modifyFile(t, repoPath, "README.md", "# Test\n\nUnstaged change\n")
+	modifyFile(t, repoPath, "untracked.go", "package untracked\n")
+	modifyFile(t, repoPath, "ignored.log", "should not appear\n")
+
+	client := git.NewClient(repoPath)
+	ctx := context.Background()
+
+	diff, err := client.GetWorkingTreeDiffContext(ctx, git.WorkingTreeOptions{
+		IncludeStaged:    true,
+		IncludeUnstaged:  true,
+		IncludeUntracked: true,
+	})
+	if err != nil {
+		t.Fatalf("GetWorkingTreeDiffContext() error = %v", err)
+	}
+
+	for _, want := range []string{"staged.go", "Unstaged change", "untracked.go", "package untracked"} {
+		if !contains(diff, want) {
+			t.Errorf("Diff doesn't contain %q: %s", want, diff)
+		}
+	}
+
+	if contains(diff, "ignored.log") {
+		t.Errorf("Diff contains ignored file: %s", diff)
+	}
+
+	// Untracked only
+	diff, err = client.GetWorkingTreeDiffContext(ctx, git.WorkingTreeOptions{IncludeUntracked: true})
+	if err != nil {
+		t.Fatalf("GetWorkingTreeDiffContext() error = %v", err)
+	}
+
+	if !contains(diff, "untracked.go") {
+		t.Errorf("Diff doesn't contain untracked file: %s", diff)
+	}
+
+	if contains(diff, "staged.go") || contains(diff, "Unstaged change") {
+		t.Errorf("Untracked-only diff contains tracked changes: %s", diff)
+	}
+}
@@ -0,0 +1,132 @@
+package analysis_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/dshills/mcp-pr/internal/analysis"
+	"github.com/dshills/mcp-pr/internal/git"
+)
+
+// changedFiles is a diff adding lines 2-3 of pkg/a.go and line 1 of notes.txt
+var changedFiles = []git.FileDiff{
+	{
+		OldPath: "pkg/a.go",
+		NewPath: "pkg/a.go",
+		Hunks: []git.Hunk{{
+			OldStart: 1, OldLines: 1, NewStart: 1, NewLines: 3,
+			Lines: []string{" package pkg", "+var x = 1", "+var y = 2"},
+		}},
+	},
+	{
+		OldPath: "notes.txt",
+		NewPath: "notes.txt",
+		Hunks: []git.Hunk{{
+			OldStart: 1, OldLines: 0, NewStart: 1, NewLines: 1,
+			Lines: []string{"+todo"},
+		}},
+	},
+}
+
+// writeScript creates an executable analyzer that prints its arguments and fixed output
+func writeScript(t *testing.T, root, output string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not supported on Windows")
+	}
+
+	script := filepath.Join(t.TempDir(), "fake-analyzer")
+	content := "#!/bin/sh\necho \"args: $*\" >&2\ncat <<'EOF'\n" + output + "EOF\nexit 1\n"
+	if err := os.WriteFile(script, []byte(content), 0o755); err != nil { //nolint:gosec // G306: test script must be executable
+		t.Fatalf("Failed to write script: %v", err)
+	}
+	return script
+}
+
+// TestParseSpec tests parsing of analyzer configuration
+func TestParseSpec(t *testing.T) {
+	analyzers, err := analysis.ParseSpec("go-vet=go vet {packages}; lint = golint {files} ;")
+	if err != nil {
+		t.Fatalf("ParseSpec() error = %v", err)
+	}
+
+	if len(analyzers) != 2 {
+		t.Fatalf("got %d analyzers, want 2", len(analyzers))
+	}
+	if analyzers[0].Name != "go-vet" || !reflect.DeepEqual(analyzers[0].Command, []string{"go", "vet", "{packages}"}) {
+		t.Errorf("analyzers[0] = %+v", analyzers[0])
+	}
+	if analyzers[1].Name != "lint" || !reflect.DeepEqual(analyzers[1].Command, []string{"golint", "{files}"}) {
+		t.Errorf("analyzers[1] = %+v", analyzers[1])
+	}
+
+	for _, spec := range []string{"", "none"} {
+		if analyzers, err := analysis.ParseSpec(spec); err != nil || analyzers != nil {
+			t.Errorf("ParseSpec(%q) = %v, %v, want nil, nil", spec, analyzers, err)
+		}
+	}
+
+	for _, spec := range []string{"go vet", "=go vet", "vet="} {
+		if _, err := analysis.ParseSpec(spec); err == nil {
+			t.Errorf("ParseSpec(%q) expected error", spec)
+		}
+	}
+}
+
+// TestRun tests that diagnostics are parsed, normalized and filtered to added lines
+func TestRun(t *testing.T) {
+	root := t.TempDir()
+	output := "# example.com/pkg\n" +
+		"pkg/a.go:2:5: x declared and not used\n" +
+		"./pkg/a.go:3: y shadows a builtin\n" +
+		filepath.Join(root, "pkg", "a.go") + ":1:1: on an unchanged line\n" +
+		"pkg/other.go:2:1: in an unchanged file\n" +
+		"vet: exit status 1\n"
+	script := writeScript(t, root, output)
+
+	result := analysis.Run(context.Background(), root, changedFiles, []analysis.Analyzer{
+		{Name: "fake", Command: []string{script, "{packages}"}, Category: "bug", Severity: "medium"},
+	}, 10*time.Second)
+
+	if !reflect.DeepEqual(result.Ran, []string{"fake"}) || len(result.Unavailable) != 0 {
+		t.Errorf("Ran = %v, Unavailable = %v", result.Ran, result.Unavailable)
+	}
+
+	want := []analysis.Diagnostic{
+		{Source: "fake", FilePath: "pkg/a.go", Line: 2, Column: 5, Message: "x declared and not used", Category: "bug", Severity: "medium"},
+		{Source: "fake", FilePath: "pkg/a.go", Line: 3, Message: "y shadows a builtin", Category: "bug", Severity: "medium"},
+	}
+	if !reflect.DeepEqual(result.Diagnostics, want) {
+		t.Errorf("Diagnostics = %+v\nwant %+v", result.Diagnostics, want)
+	}
+}
+
+// TestRunMissingTool tests that missing analyzers are reported rather than failing
+func TestRunMissingTool(t *testing.T) {
+	result := analysis.Run(context.Background(), t.TempDir(), changedFiles, []analysis.Analyzer{
+		{Name: "missing", Command: []string{"mcp-pr-no-such-analyzer", "{files}"}},
+	}, time.Second)
+
+	if len(result.Ran) != 0 || !reflect.DeepEqual(result.Unavailable, []string{"missing"}) {
+		t.Errorf("Ran = %v, Unavailable = %v", result.Ran, result.Unavailable)
+	}
+}
+
+// TestRunSkipsGoAnalyzersWithoutGoFiles tests that {packages} analyzers only run for Go changes
+func TestRunSkipsGoAnalyzersWithoutGoFiles(t *testing.T) {
+	root := t.TempDir()
+	script := writeScript(t, root, "")
+
+	result := analysis.Run(context.Background(), root, changedFiles[1:], []analysis.Analyzer{
+		{Name: "fake", Command: []string{script, "{packages}"}},
+	}, 10*time.Second)
+
+	if len(result.Ran) != 0 || len(result.Unavailable) != 0 {
+		t.Errorf("Ran = %v, Unavailable = %v", result.Ran, result.Unavailable)
+	}
+}
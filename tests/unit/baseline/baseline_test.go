@@ -0,0 +1,101 @@
+package baseline_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dshills/mcp-pr/internal/baseline"
+)
+
+// TestFingerprint tests that fingerprints ignore whitespace but not file, category or code
+func TestFingerprint(t *testing.T) {
+	base := baseline.Fingerprint("main.go", "bug", "x := compute(a, b)")
+
+	if got := baseline.Fingerprint("main.go", "bug", "\tx  :=  compute(a, b)  "); got != base {
+		t.Errorf("whitespace changed the fingerprint: %s != %s", got, base)
+	}
+	for name, fp := range map[string]string{
+		"file":     baseline.Fingerprint("other.go", "bug", "x := compute(a, b)"),
+		"category": baseline.Fingerprint("main.go", "style", "x := compute(a, b)"),
+		"snippet":  baseline.Fingerprint("main.go", "bug", "x := compute(b, a)"),
+	} {
+		if fp == base {
+			t.Errorf("changing the %s kept the fingerprint", name)
+		}
+	}
+}
+
+// TestIgnores tests inline ignore comments
+func TestIgnores(t *testing.T) {
+	tests := []struct {
+		line     string
+		category string
+		want     bool
+	}{
+		{"x := 1 // mcp-pr:ignore style", "style", true},
+		{"x := 1 // mcp-pr:ignore style", "bug", false},
+		{"x = 1  # mcp-pr:ignore bug,performance", "performance", true},
+		{"x := 1 // mcp-pr:ignore", "security", true},
+		{"x := 1 // mcp-pr:ignore accepted by review", "security", true},
+		{"-- mcp-pr:ignore security reviewed in #42", "bug", false},
+		{"x := 1 // ignore style", "style", false},
+	}
+
+	for _, tt := range tests {
+		if got := baseline.Ignores(tt.line, tt.category); got != tt.want {
+			t.Errorf("Ignores(%q, %q) = %v, want %v", tt.line, tt.category, got, tt.want)
+		}
+	}
+}
+
+// TestSaveLoad tests that a saved baseline loads back with the same entries
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), baseline.FileName)
+
+	missing, err := baseline.Load(path)
+	if err != nil || missing != nil {
+		t.Fatalf("Load(missing) = %v, %v; want nil, nil", missing, err)
+	}
+	if missing.Contains("anything") {
+		t.Error("nil baseline contains a fingerprint")
+	}
+
+	b := baseline.New([]baseline.Entry{
+		{Fingerprint: "bbb", FilePath: "z.go", Category: "bug", Severity: "high"},
+		{Fingerprint: "aaa", FilePath: "a.go", Category: "style", Severity: "low"},
+		{Fingerprint: "aaa", FilePath: "a.go", Category: "style", Severity: "low"},
+	})
+	if err := b.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := baseline.Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(loaded.Entries) != 2 || loaded.Entries[0].FilePath != "a.go" {
+		t.Errorf("Entries = %+v", loaded.Entries)
+	}
+	if !loaded.Contains("aaa") || !loaded.Contains("bbb") || loaded.Contains("ccc") {
+		t.Error("Contains() does not match the saved fingerprints")
+	}
+}
+
+// TestLoadInvalid tests that malformed and unknown-version baselines are rejected
+func TestLoadInvalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"malformed":       "{",
+		"unknown version": `{"version": 9, "findings": []}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), baseline.FileName)
+			if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := baseline.Load(path); err == nil {
+				t.Error("Load() expected error")
+			}
+		})
+	}
+}
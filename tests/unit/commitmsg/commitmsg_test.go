@@ -0,0 +1,87 @@
+package commitmsg_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dshills/mcp-pr/internal/commitmsg"
+)
+
+// TestParse tests splitting messages into Conventional Commits parts
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		want     commitmsg.Message
+		breaking bool
+	}{
+		{
+			name: "type and scope",
+			text: "fix(parser): handle empty input\n\nEmpty files crashed the parser.\n",
+			want: commitmsg.Message{Type: "fix", Scope: "parser", Description: "handle empty input"},
+		},
+		{
+			name:     "bang marks breaking change",
+			text:     "feat!: drop v1 API",
+			want:     commitmsg.Message{Type: "feat", Description: "drop v1 API"},
+			breaking: true,
+		},
+		{
+			name:     "footer marks breaking change",
+			text:     "# template comment\nrefactor: rename Config\n\nBREAKING CHANGE: Config is now Settings\n",
+			want:     commitmsg.Message{Type: "refactor", Description: "rename Config", BreakingNote: "Config is now Settings"},
+			breaking: true,
+		},
+		{
+			name: "free-form subject",
+			text: "Fixed the thing",
+			want: commitmsg.Message{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := commitmsg.Parse(tt.text)
+			if got.Type != tt.want.Type || got.Scope != tt.want.Scope || got.Description != tt.want.Description ||
+				got.BreakingNote != tt.want.BreakingNote || got.Breaking != tt.breaking {
+				t.Errorf("Parse() = %+v", got)
+			}
+			if got.Conventional() != (tt.want.Type != "") {
+				t.Errorf("Conventional() = %v", got.Conventional())
+			}
+			if strings.HasPrefix(got.Subject, "#") {
+				t.Errorf("Subject = %q, comment lines must be ignored", got.Subject)
+			}
+		})
+	}
+}
+
+// TestCheck tests the problems reported for commit messages
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string // Expected issue messages, in order
+	}{
+		{"valid", "feat(api): add pagination\n\nLists return at most 100 items.", nil},
+		{"empty", "\n# only a comment\n", []string{"Commit message is empty"}},
+		{"not conventional", "Fixed the thing", []string{"Subject does not follow Conventional Commits"}},
+		{"unknown type", "feature: add pagination", []string{`Commit type "feature" is not a conventional type`}},
+		{"no blank line", "fix: a\nbody", []string{"Subject is not followed by a blank line"}},
+		{"long subject", "fix: " + strings.Repeat("x", 70), []string{"Subject line is 75 characters long"}},
+		{"unexplained breaking change", "feat!: drop v1 API", []string{"Breaking change is not explained"}},
+		{"merge", "Merge branch 'main' into feature", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, issue := range commitmsg.Check(commitmsg.Parse(tt.text)) {
+				got = append(got, issue.Message)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("Check() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
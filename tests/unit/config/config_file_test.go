@@ -0,0 +1,250 @@
+package config_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dshills/mcp-pr/internal/config"
+)
+
+// writeConfigFile writes a config file to a temporary directory
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return path
+}
+
+// TestConfigLoad_Precedence tests flags > env > file > defaults
+func TestConfigLoad_Precedence(t *testing.T) {
+	os.Clearenv()
+	path := writeConfigFile(t, `
+anthropic_api_key: file-key
+review_timeout: 30s
+max_diff_size: 1000
+log_level: debug
+`)
+	t.Setenv("MCP_PR_MAX_DIFF_SIZE", "2000")
+	t.Setenv("MCP_PR_LOG_LEVEL", "warn")
+
+	cfg, err := config.LoadWithOptions(config.Options{
+		ConfigFile: path,
+		Flags:      map[string]string{"log_level": "error"},
+	})
+	if err != nil {
+		t.Fatalf("LoadWithOptions() failed: %v", err)
+	}
+
+	if cfg.AnthropicAPIKey != "file-key" {
+		t.Errorf("Expected API key from file, got %q", cfg.AnthropicAPIKey)
+	}
+	if cfg.ReviewTimeout != 30*time.Second {
+		t.Errorf("Expected ReviewTimeout 30s from file, got %v", cfg.ReviewTimeout)
+	}
+	if cfg.MaxDiffSize != 2000 {
+		t.Errorf("Expected MaxDiffSize 2000 from env, got %d", cfg.MaxDiffSize)
+	}
+	if cfg.LogLevel != "error" {
+		t.Errorf("Expected LogLevel from flag, got %q", cfg.LogLevel)
+	}
+	if cfg.ContextLines != 20 {
+		t.Errorf("Expected default ContextLines 20, got %d", cfg.ContextLines)
+	}
+
+	if cfg.ConfigFile != path {
+		t.Errorf("Expected ConfigFile %q, got %q", path, cfg.ConfigFile)
+	}
+	wantSources := map[string]string{
+		"review_timeout": config.SourceFile,
+		"max_diff_size":  config.SourceEnv,
+		"log_level":      config.SourceFlag,
+		"context_lines":  config.SourceDefault,
+	}
+	for key, want := range wantSources {
+		if got := cfg.Sources[key]; got != want {
+			t.Errorf("Expected %s source %q, got %q", key, want, got)
+		}
+	}
+	wantOrigins := map[string]string{
+		"review_timeout": "review_timeout in " + path,
+		"max_diff_size":  "MCP_PR_MAX_DIFF_SIZE",
+		"log_level":      "--log-level",
+		"context_lines":  "the built-in default",
+	}
+	for key, want := range wantOrigins {
+		if got := cfg.Origin(key); got != want {
+			t.Errorf("Expected %s origin %q, got %q", key, want, got)
+		}
+	}
+}
+
+// TestConfigLoad_BindFlags tests that only flags set on the command line apply
+func TestConfigLoad_BindFlags(t *testing.T) {
+	os.Clearenv()
+	t.Setenv("ANTHROPIC_API_KEY", "test-key")
+	t.Setenv("MCP_PR_DEFAULT_PROVIDER", "openai")
+	path := writeConfigFile(t, "analyzer_timeout: 5s\n")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	opts := config.BindFlags(fs)
+	if err := fs.Parse([]string{"--config", path, "--review-timeout", "45s", "--go-context=false"}); err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+
+	cfg, err := config.LoadWithOptions(*opts)
+	if err != nil {
+		t.Fatalf("LoadWithOptions() failed: %v", err)
+	}
+	if cfg.ReviewTimeout != 45*time.Second {
+		t.Errorf("Expected ReviewTimeout 45s, got %v", cfg.ReviewTimeout)
+	}
+	if cfg.GoContext {
+		t.Error("Expected GoContext false from flag")
+	}
+	if cfg.AnalyzerTimeout != 5*time.Second {
+		t.Errorf("Expected AnalyzerTimeout 5s from file, got %v", cfg.AnalyzerTimeout)
+	}
+	if cfg.DefaultProvider != "openai" {
+		t.Errorf("Expected DefaultProvider from env, got %q", cfg.DefaultProvider)
+	}
+	if fs.Lookup("anthropic-api-key") != nil {
+		t.Error("API keys must not be exposed as flags")
+	}
+}
+
+// TestConfigLoad_XDGConfigFile tests discovery under $XDG_CONFIG_HOME
+func TestConfigLoad_XDGConfigFile(t *testing.T) {
+	os.Clearenv()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "mcp-pr"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	content := "google_api_key: xdg-key\ndefault_provider: google\n"
+	if err := os.WriteFile(filepath.Join(dir, "mcp-pr", "config.yaml"), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.GoogleAPIKey != "xdg-key" || cfg.DefaultProvider != "google" {
+		t.Errorf("Expected settings from XDG config file, got key %q provider %q", cfg.GoogleAPIKey, cfg.DefaultProvider)
+	}
+}
+
+// TestConfigLoad_IgnoresWorkingDirectory tests that a config file in the working directory is not read
+func TestConfigLoad_IgnoresWorkingDirectory(t *testing.T) {
+	os.Clearenv()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ANTHROPIC_API_KEY", "test-key")
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".mcp-pr"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	content := "analyzers: evil=sh -c exit\n"
+	if err := os.WriteFile(filepath.Join(dir, ".mcp-pr", "config.yaml"), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.ConfigFile != "" || cfg.Analyzers != "" {
+		t.Errorf("Expected no config file, got %q with analyzers %q", cfg.ConfigFile, cfg.Analyzers)
+	}
+}
+
+// TestConfigLoad_ValidationErrors tests that invalid values are reported, not replaced by defaults
+func TestConfigLoad_ValidationErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		envVars map[string]string
+		wantErr string
+	}{
+		{
+			name:    "unknown key in file",
+			file:    "anthropic_api_key: k\nreview_timout: 30s\n",
+			wantErr: `unknown setting "review_timout"`,
+		},
+		{
+			name:    "duplicate key in file",
+			file:    "anthropic_api_key: k\nlog_level: info\nlog_level: debug\n",
+			wantErr: `duplicate setting "log_level"`,
+		},
+		{
+			name:    "non-scalar value in file",
+			file:    "anthropic_api_key: k\nanalyzers: [go-vet]\n",
+			wantErr: "analyzers must be a single value",
+		},
+		{
+			name:    "invalid duration in file",
+			file:    "anthropic_api_key: k\nreview_timeout: 240\n",
+			wantErr: `review_timeout must be a positive duration`,
+		},
+		{
+			name:    "invalid duration in env",
+			envVars: map[string]string{"ANTHROPIC_API_KEY": "k", "MCP_PR_ANALYZER_TIMEOUT": "soon"},
+			wantErr: "(from MCP_PR_ANALYZER_TIMEOUT)",
+		},
+		{
+			name:    "invalid integer in env",
+			envVars: map[string]string{"ANTHROPIC_API_KEY": "k", "MCP_PR_MAX_DIFF_SIZE": "10kb"},
+			wantErr: "max_diff_size must be an integer",
+		},
+		{
+			name:    "invalid fail_on in env",
+			envVars: map[string]string{"ANTHROPIC_API_KEY": "k", "MCP_PR_FAIL_ON": "urgent"},
+			wantErr: `fail_on must be a severity or "none"`,
+		},
+		{
+			name:    "invalid bool in env",
+			envVars: map[string]string{"ANTHROPIC_API_KEY": "k", "MCP_PR_GO_CONTEXT": "maybe"},
+			wantErr: "go_context must be true or false",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			for key, value := range tt.envVars {
+				t.Setenv(key, value)
+			}
+			opts := config.Options{}
+			if tt.file != "" {
+				opts.ConfigFile = writeConfigFile(t, tt.file)
+			}
+
+			_, err := config.LoadWithOptions(opts)
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestConfigLoad_MissingExplicitFile tests that --config must point at an existing file
+func TestConfigLoad_MissingExplicitFile(t *testing.T) {
+	os.Clearenv()
+	t.Setenv("ANTHROPIC_API_KEY", "test-key")
+
+	_, err := config.LoadWithOptions(config.Options{ConfigFile: filepath.Join(t.TempDir(), "missing.yaml")})
+	if err == nil {
+		t.Fatal("Expected an error for a missing config file")
+	}
+}
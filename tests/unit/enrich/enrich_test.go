@@ -0,0 +1,134 @@
+package enrich_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dshills/mcp-pr/internal/enrich"
+	"github.com/dshills/mcp-pr/internal/git"
+)
+
+const goSource = `package calc
+
+import "errors"
+
+// Divide divides a by b
+func Divide(a, b int) (int, error) {
+	if b == 0 {
+		return 0, errors.New("divide by zero")
+	}
+	return a / b, nil
+}
+
+// Multiply multiplies a by b
+func Multiply(a, b int) int {
+	return a * b
+}
+`
+
+const pythonSource = `import os
+
+
+def load(path):
+    with open(path) as f:
+        data = f.read()
+    return data.strip()
+
+
+def unrelated():
+    return 1
+`
+
+// staticSource returns a Source backed by an in-memory map of files
+func staticSource(files map[string]string) enrich.Source {
+	return func(ctx context.Context, path string) (string, error) {
+		content, ok := files[path]
+		if !ok {
+			return "", errors.New("not found")
+		}
+		return content, nil
+	}
+}
+
+// modifiedFile builds a FileDiff with one added line at the given new-file line
+func modifiedFile(path string, line int, section string) git.FileDiff {
+	return git.FileDiff{
+		OldPath: path,
+		NewPath: path,
+		Hunks: []git.Hunk{{
+			OldStart: line - 1,
+			OldLines: 1,
+			NewStart: line - 1,
+			NewLines: 2,
+			Section:  section,
+			Lines:    []string{" context", "+added"},
+		}},
+	}
+}
+
+// TestCollectEnclosingFunction tests that the whole enclosing function is included
+func TestCollectEnclosingFunction(t *testing.T) {
+	source := staticSource(map[string]string{"calc.go": goSource, "load.py": pythonSource})
+	opts := enrich.Options{WindowLines: 2, TokenBudget: 1000}
+
+	// Line 8 is inside Divide (lines 6-11)
+	result := enrich.Collect(context.Background(), []git.FileDiff{modifiedFile("calc.go", 8, "")}, source, opts)
+	if len(result.Snippets) != 1 {
+		t.Fatalf("got %d snippets, want 1", len(result.Snippets))
+	}
+
+	snippet := result.Snippets[0]
+	if snippet.Kind != "function" || snippet.StartLine != 6 || snippet.EndLine != 11 {
+		t.Errorf("snippet = %s %d-%d, want function 6-11", snippet.Kind, snippet.StartLine, snippet.EndLine)
+	}
+	if strings.Contains(snippet.Content, "Multiply") {
+		t.Error("snippet should not include the next function")
+	}
+
+	// Indentation-based block for Python
+	result = enrich.Collect(context.Background(), []git.FileDiff{modifiedFile("load.py", 6, "def load(path):")}, source, opts)
+	if len(result.Snippets) != 1 {
+		t.Fatalf("got %d snippets, want 1", len(result.Snippets))
+	}
+	if snippet := result.Snippets[0]; snippet.StartLine != 4 || snippet.EndLine != 7 {
+		t.Errorf("python snippet = %d-%d, want 4-7", snippet.StartLine, snippet.EndLine)
+	}
+}
+
+// TestCollectWindowAndBudget tests the line window fallback and the token budget
+func TestCollectWindowAndBudget(t *testing.T) {
+	text := strings.Repeat("value = value + 1\n", 50)
+	source := staticSource(map[string]string{"a.txt": text, "b.txt": text})
+
+	files := []git.FileDiff{modifiedFile("a.txt", 20, ""), modifiedFile("b.txt", 20, ""), modifiedFile("missing.txt", 5, "")}
+
+	result := enrich.Collect(context.Background(), files, source, enrich.Options{WindowLines: 3, TokenBudget: 40})
+	if len(result.Snippets) != 1 {
+		t.Fatalf("got %d snippets, want 1 within budget", len(result.Snippets))
+	}
+
+	snippet := result.Snippets[0]
+	if snippet.Kind != "window" || snippet.StartLine != 17 || snippet.EndLine != 23 {
+		t.Errorf("snippet = %s %d-%d, want window 17-23", snippet.Kind, snippet.StartLine, snippet.EndLine)
+	}
+
+	if len(result.OmittedFiles) != 2 {
+		t.Errorf("OmittedFiles = %v, want b.txt and missing.txt", result.OmittedFiles)
+	}
+
+	formatted := enrich.Format(result.Snippets)
+	if !strings.Contains(formatted, "File: a.txt (lines 17-23, window)") || !strings.Contains(formatted, "    20 | ") {
+		t.Errorf("Format() = %q", formatted)
+	}
+}
+
+// TestCollectDisabled tests that zero options disable enrichment
+func TestCollectDisabled(t *testing.T) {
+	source := staticSource(map[string]string{"calc.go": goSource})
+	result := enrich.Collect(context.Background(), []git.FileDiff{modifiedFile("calc.go", 8, "")}, source, enrich.Options{TokenBudget: 1000})
+	if len(result.Snippets) != 0 {
+		t.Errorf("got %d snippets with WindowLines 0, want none", len(result.Snippets))
+	}
+}
@@ -0,0 +1,383 @@
+package git_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dshills/mcp-pr/internal/git"
+)
+
+// Tricky real-world diffs, shared by the table tests and the fuzz seed corpus
+const (
+	renameDiff = `diff --git a/old/name.go b/new/name.go
+similarity index 92%
+rename from old/name.go
+rename to new/name.go
+index 1111111..2222222 100644
+--- a/old/name.go
++++ b/new/name.go
+@@ -1,3 +1,3 @@ package name
+ package name
+-var x = 1
++var x = 2
+ // end
+`
+
+	copyDiff = `diff --git a/a.txt b/b.txt
+similarity index 100%
+copy from a.txt
+copy to b.txt
+`
+
+	modeDiff = `diff --git a/run.sh b/run.sh
+old mode 100644
+new mode 100755
+`
+
+	binaryDiff = `diff --git a/logo.png b/logo.png
+new file mode 100644
+index 0000000..3333333
+Binary files /dev/null and b/logo.png differ
+`
+
+	binaryPatchDiff = `diff --git a/img.bin b/img.bin
+index 4444444..5555555 100644
+GIT binary patch
+literal 12
+TcmZ?wbhEHbRA5k0U;qFAA3g#7
+
+literal 8
+PcmZ?wbhEHb00001
+
+diff --git a/after.txt b/after.txt
+--- a/after.txt
++++ b/after.txt
+@@ -1 +1 @@
+-before
++after
+`
+
+	quotedPathDiff = `diff --git "a/dir/caf\303\251 \"menu\".txt" "b/dir/caf\303\251 \"menu\".txt"
+--- "a/dir/caf\303\251 \"menu\".txt"
++++ "b/dir/caf\303\251 \"menu\".txt"
+@@ -1 +1 @@
+-old
++new
+`
+
+	spacePathDiff = "diff --git a/my dir/a b/file.txt b/my dir/a b/file.txt\n" +
+		"--- a/my dir/a b/file.txt\t\n" +
+		"+++ b/my dir/a b/file.txt\t\n" +
+		"@@ -1 +1 @@\n" +
+		"-x\n" +
+		"+y\n"
+
+	noNewlineDiff = `diff --git a/notes.txt b/notes.txt
+--- a/notes.txt
++++ b/notes.txt
+@@ -1,2 +1,2 @@
+ first
+-second
+\ No newline at end of file
++second line
+\ No newline at end of file
+`
+
+	newFileDiff = `diff --git a/new.go b/new.go
+new file mode 100644
+index 0000000..6666666
+--- /dev/null
++++ b/new.go
+@@ -0,0 +1,2 @@
++package dev
++// mentions /dev/null in content
+`
+
+	deletedFileDiff = `diff --git a/gone.go b/gone.go
+deleted file mode 100644
+index 7777777..0000000
+--- a/gone.go
++++ /dev/null
+@@ -1 +0,0 @@
+-package gone
+`
+
+	devNullNameDiff = `diff --git a/docs/dev/null.md b/docs/dev/null.md
+--- a/docs/dev/null.md
++++ b/docs/dev/null.md
+@@ -1 +1 @@
+-a
++b
+`
+
+	sqlCommentDiff = `diff --git a/schema.sql b/schema.sql
+--- a/schema.sql
++++ b/schema.sql
+@@ -1,3 +1,2 @@
+ CREATE TABLE t (id int);
+--- drop this comment
+-++ and this one
+ SELECT 1;
+`
+
+	plainUnifiedDiff = `--- a/one.txt	2024-01-01 00:00:00
++++ b/one.txt	2024-01-02 00:00:00
+@@ -1 +1 @@
+-1
++one
+--- a/two.txt
++++ b/two.txt
+@@ -1 +1 @@
+-2
++two
+`
+
+	commitHeaderDiff = `commit 0123456789abcdef0123456789abcdef01234567
+Author: Test User <test@example.com>
+Date:   Mon Jan 1 00:00:00 2024 +0000
+
+    Fix parser
+
+    --- not a diff header
+    +++ not either
+
+diff --git a/p.go b/p.go
+--- a/p.go
++++ b/p.go
+@@ -10,2 +10,3 @@ func parse() {
+ 	a()
++	b()
+ 	c()
+`
+)
+
+// TestParseRealWorldDiffs tests parsing of renames, copies, binaries, quoting and markers
+func TestParseRealWorldDiffs(t *testing.T) {
+	tests := []struct {
+		name  string
+		diff  string
+		check func(t *testing.T, files []git.FileDiff)
+	}{
+		{"rename", renameDiff, func(t *testing.T, files []git.FileDiff) {
+			f := onlyFile(t, files)
+			if !f.IsRename || f.OldPath != "old/name.go" || f.NewPath != "new/name.go" || f.Similarity != 92 {
+				t.Errorf("rename = %+v", f)
+			}
+			if f.Hunks[0].Section != "package name" {
+				t.Errorf("Section = %q, want %q", f.Hunks[0].Section, "package name")
+			}
+		}},
+		{"copy", copyDiff, func(t *testing.T, files []git.FileDiff) {
+			f := onlyFile(t, files)
+			if !f.IsCopy || f.OldPath != "a.txt" || f.NewPath != "b.txt" || f.Similarity != 100 {
+				t.Errorf("copy = %+v", f)
+			}
+		}},
+		{"mode change", modeDiff, func(t *testing.T, files []git.FileDiff) {
+			f := onlyFile(t, files)
+			if !f.ModeChanged() || f.OldMode != "100644" || f.NewMode != "100755" {
+				t.Errorf("mode = %+v", f)
+			}
+		}},
+		{"binary", binaryDiff, func(t *testing.T, files []git.FileDiff) {
+			f := onlyFile(t, files)
+			if !f.IsBinary || !f.IsNew || f.Path() != "logo.png" {
+				t.Errorf("binary = %+v", f)
+			}
+		}},
+		{"binary patch", binaryPatchDiff, func(t *testing.T, files []git.FileDiff) {
+			if len(files) != 2 {
+				t.Fatalf("got %d files, want 2", len(files))
+			}
+			if !files[0].IsBinary || len(files[0].Hunks) != 0 {
+				t.Errorf("binary patch = %+v", files[0])
+			}
+			if files[1].Path() != "after.txt" || len(files[1].Hunks) != 1 {
+				t.Errorf("file after binary patch = %+v", files[1])
+			}
+		}},
+		{"quoted path", quotedPathDiff, func(t *testing.T, files []git.FileDiff) {
+			f := onlyFile(t, files)
+			if want := "dir/café \"menu\".txt"; f.OldPath != want || f.NewPath != want {
+				t.Errorf("paths = %q, %q, want %q", f.OldPath, f.NewPath, want)
+			}
+		}},
+		{"path with spaces", spacePathDiff, func(t *testing.T, files []git.FileDiff) {
+			f := onlyFile(t, files)
+			if want := "my dir/a b/file.txt"; f.OldPath != want || f.NewPath != want {
+				t.Errorf("paths = %q, %q, want %q", f.OldPath, f.NewPath, want)
+			}
+		}},
+		{"no newline marker", noNewlineDiff, func(t *testing.T, files []git.FileDiff) {
+			f := onlyFile(t, files)
+			lines := f.Hunks[0].Lines
+			if len(lines) != 5 || lines[2] != `\ No newline at end of file` || lines[4] != `\ No newline at end of file` {
+				t.Errorf("lines = %q", lines)
+			}
+		}},
+		{"new file", newFileDiff, func(t *testing.T, files []git.FileDiff) {
+			f := onlyFile(t, files)
+			if !f.IsNew || f.IsDeleted || f.OldPath != "" || f.NewPath != "new.go" {
+				t.Errorf("new file = %+v", f)
+			}
+			if h := f.Hunks[0]; h.OldStart != 0 || h.OldLines != 0 || h.NewLines != 2 {
+				t.Errorf("hunk = %+v", h)
+			}
+		}},
+		{"deleted file", deletedFileDiff, func(t *testing.T, files []git.FileDiff) {
+			f := onlyFile(t, files)
+			if !f.IsDeleted || f.IsNew || f.NewPath != "" || f.Path() != "gone.go" {
+				t.Errorf("deleted file = %+v", f)
+			}
+		}},
+		{"dev/null in path", devNullNameDiff, func(t *testing.T, files []git.FileDiff) {
+			f := onlyFile(t, files)
+			if f.IsNew || f.IsDeleted {
+				t.Errorf("file under docs/dev/ marked new or deleted: %+v", f)
+			}
+		}},
+		{"content resembling headers", sqlCommentDiff, func(t *testing.T, files []git.FileDiff) {
+			f := onlyFile(t, files)
+			if f.OldPath != "schema.sql" || len(f.Hunks) != 1 || len(f.Hunks[0].Lines) != 4 {
+				t.Errorf("sql diff = %+v", f)
+			}
+		}},
+		{"plain unified diff", plainUnifiedDiff, func(t *testing.T, files []git.FileDiff) {
+			if len(files) != 2 || files[0].Path() != "one.txt" || files[1].Path() != "two.txt" {
+				t.Errorf("files = %+v", files)
+			}
+		}},
+		{"commit header", commitHeaderDiff, func(t *testing.T, files []git.FileDiff) {
+			f := onlyFile(t, files)
+			if f.Path() != "p.go" || f.Hunks[0].Section != "func parse() {" {
+				t.Errorf("commit diff = %+v", f)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := git.Parse(tt.diff)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			tt.check(t, files)
+
+			// Rendering must preserve the parsed structure
+			reparsed, err := git.Parse(git.Render(files))
+			if err != nil {
+				t.Fatalf("Parse(Render()) error = %v", err)
+			}
+			if !reflect.DeepEqual(files, reparsed) {
+				t.Errorf("Parse(Render()) mismatch\n got: %+v\nwant: %+v", reparsed, files)
+			}
+		})
+	}
+}
+
+// TestParseLongLines tests that lines beyond bufio.Scanner's 64KB limit are accepted
+func TestParseLongLines(t *testing.T) {
+	long := strings.Repeat("x", 256*1024)
+	diff := "diff --git a/app.min.js b/app.min.js\n--- a/app.min.js\n+++ b/app.min.js\n@@ -1 +1 @@\n-" + long + "\n+" + long + "y\n"
+
+	files, err := git.Parse(diff)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	f := onlyFile(t, files)
+	if len(f.Hunks) != 1 || len(f.Hunks[0].Lines) != 2 || len(f.Hunks[0].Lines[1]) != len(long)+2 {
+		t.Errorf("long line not preserved")
+	}
+}
+
+// TestParseLineEndings tests that CRLF line endings are dropped while a
+// carriage return inside an LF diff is kept as content
+func TestParseLineEndings(t *testing.T) {
+	lf := "diff --git a/f.txt b/f.txt\n--- a/f.txt\n+++ b/f.txt\n@@ -1 +1 @@\n-a\r\n+b\r\n"
+
+	f := onlyFile(t, mustParse(t, strings.ReplaceAll(strings.ReplaceAll(lf, "\r", ""), "\n", "\r\n")))
+	if got := f.Hunks[0].Lines; !reflect.DeepEqual(got, []string{"-a", "+b"}) {
+		t.Errorf("CRLF diff lines = %q, want line endings dropped", got)
+	}
+
+	f = onlyFile(t, mustParse(t, lf))
+	if got := f.Hunks[0].Lines; !reflect.DeepEqual(got, []string{"-a\r", "+b\r"}) {
+		t.Errorf("LF diff lines = %q, want carriage returns kept", got)
+	}
+}
+
+// mustParse parses a diff, failing the test on error
+func mustParse(t *testing.T, diff string) []git.FileDiff {
+	t.Helper()
+	files, err := git.Parse(diff)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return files
+}
+
+// FuzzParse checks that arbitrary input never panics and that rendering is stable
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{
+		renameDiff, copyDiff, modeDiff, binaryDiff, binaryPatchDiff, quotedPathDiff,
+		spacePathDiff, noNewlineDiff, newFileDiff, deletedFileDiff, devNullNameDiff,
+		sqlCommentDiff, plainUnifiedDiff, commitHeaderDiff,
+		"", "diff --git ", "@@ -1 +1 @@\n+x\n", "--- \n+++ \n@@ -0,0 +0,0 @@\n",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, diff string) {
+		files, err := git.Parse(diff)
+		if err != nil {
+			return
+		}
+
+		rendered := git.Render(files)
+		reparsed, err := git.Parse(rendered)
+		if err != nil {
+			t.Fatalf("Parse(Render()) error = %v", err)
+		}
+
+		if again := git.Render(reparsed); again != rendered {
+			t.Errorf("Render is not stable\nfirst:  %q\nsecond: %q", rendered, again)
+		}
+	})
+}
+
+// onlyFile asserts that exactly one file was parsed and returns it
+func onlyFile(t *testing.T, files []git.FileDiff) git.FileDiff {
+	t.Helper()
+	if len(files) != 1 {
+		t.Fatalf("got %d files, want 1: %+v", len(files), files)
+	}
+	return files[0]
+}
+
+// TestPreamble tests extracting the text before the first file of a diff
+func TestPreamble(t *testing.T) {
+	tests := []struct {
+		name string
+		diff string
+		want string
+	}{
+		{
+			name: "git show",
+			diff: "commit abc\nAuthor: Dev <dev@example.com>\n\n    Fix --- handling\n\ndiff --git a/f b/f\n--- a/f\n+++ b/f\n",
+			want: "commit abc\nAuthor: Dev <dev@example.com>\n\n    Fix --- handling\n\n",
+		},
+		{name: "plain unified diff", diff: "note\n--- a/f\n+++ b/f\n@@ -1 +1 @@\n", want: "note\n"},
+		{name: "starts with a file", diff: "diff --git a/f b/f\n", want: ""},
+		{name: "no files", diff: "just text\n", want: "just text\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := git.Preamble(tt.diff); got != tt.want {
+				t.Errorf("Preamble() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package git_test
+
+import (
+	"testing"
+
+	"github.com/dshills/mcp-pr/internal/git"
+)
+
+// TestMatchGlob tests glob matching against repository paths
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"go.sum", "go.sum", true},
+		{"go.sum", "tools/go.sum", true},
+		{"*.min.js", "web/static/app.min.js", true},
+		{"*.min.js", "web/static/app.js", false},
+		{"vendor/**", "vendor/github.com/x/y.go", true},
+		{"vendor/", "vendor/github.com/x/y.go", true},
+		{"vendor/**", "internal/vendor/y.go", false},
+		{"**/vendor/**", "internal/vendor/y.go", true},
+		{"internal/**/*.go", "internal/git/diff.go", true},
+		{"internal/**/*.go", "internal/diff.go", true},
+		{"internal/**/*.go", "cmd/main.go", false},
+		{"internal/*.go", "internal/git/diff.go", false},
+		{"./cmd/**", "cmd/main.go", true},
+		{"[", "anything", false},
+	}
+
+	for _, tt := range tests {
+		if got := git.MatchGlob(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("MatchGlob(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+// TestPathFilterApply tests include, exclude and default exclude handling
+func TestPathFilterApply(t *testing.T) {
+	files := []git.FileDiff{
+		{OldPath: "main.go", NewPath: "main.go"},
+		{OldPath: "go.sum", NewPath: "go.sum"},
+		{OldPath: "vendor/lib/lib.go", NewPath: "vendor/lib/lib.go"},
+		{OldPath: "api/api.pb.go", NewPath: "api/api.pb.go"},
+		{
+			OldPath: "gen/mocks.go",
+			NewPath: "gen/mocks.go",
+			IsNew:   true,
+			Hunks: []git.Hunk{{
+				NewStart: 1,
+				NewLines: 2,
+				Lines:    []string{"+// Code generated by mockgen. DO NOT EDIT.", "+package gen"},
+			}},
+		},
+	}
+
+	filter := git.PathFilter{
+		Exclude:            []string{"vendor/**"},
+		UseDefaultExcludes: true,
+	}
+
+	kept, skipped := filter.Apply(files)
+	if len(kept) != 1 || kept[0].NewPath != "main.go" {
+		t.Errorf("kept = %v, want only main.go", kept)
+	}
+	if len(skipped) != 4 {
+		t.Errorf("skipped = %v, want 4 files", skipped)
+	}
+
+	// Without default excludes only the explicit pattern applies
+	filter.UseDefaultExcludes = false
+	kept, _ = filter.Apply(files)
+	if len(kept) != 4 {
+		t.Errorf("kept %d files without default excludes, want 4", len(kept))
+	}
+
+	// Include patterns restrict the review to matching files
+	filter = git.PathFilter{Include: []string{"*.go"}, UseDefaultExcludes: true}
+	kept, _ = filter.Apply(files)
+	if len(kept) != 2 {
+		t.Errorf("kept %d files with include *.go, want 2 (main.go, vendor/lib/lib.go)", len(kept))
+	}
+}
+
+// TestPathFilterGeneratedSource tests that a change far from the header of a
+// generated file is still excluded when the filter can read the file
+func TestPathFilterGeneratedSource(t *testing.T) {
+	files := []git.FileDiff{
+		{OldPath: "gen/types.go", NewPath: "gen/types.go", Hunks: []git.Hunk{{NewStart: 40, NewLines: 1, Lines: []string{"-var A = 1", "+var A = 2"}}}},
+		{OldPath: "main.go", NewPath: "main.go", Hunks: []git.Hunk{{NewStart: 40, NewLines: 1, Lines: []string{"+var B = 2"}}}},
+	}
+	sources := map[string]string{
+		"gen/types.go": "// Copyright 2026 Example\n\n// Code generated by stringer. DO NOT EDIT.\n\npackage gen\n",
+		"main.go":      "package main\n\n// Code generated by stringer. DO NOT EDIT.\n",
+	}
+
+	filter := git.PathFilter{UseDefaultExcludes: true}
+	if kept, _ := filter.Apply(files); len(kept) != 2 {
+		t.Errorf("kept %d files without a source, want 2", len(kept))
+	}
+
+	filter.Source = func(filePath string) (string, error) {
+		return sources[filePath], nil
+	}
+	kept, skipped := filter.Apply(files)
+	if len(kept) != 1 || kept[0].NewPath != "main.go" {
+		t.Errorf("kept = %v, want only main.go (its marker comes after the package clause)", kept)
+	}
+	if len(skipped) != 1 || skipped[0] != "gen/types.go" {
+		t.Errorf("skipped = %v, want gen/types.go", skipped)
+	}
+}
+
+// TestIsGeneratedMidFile tests that only a header at the start of a file marks it generated
+func TestIsGeneratedMidFile(t *testing.T) {
+	marker := "// Code generated by stringer. DO NOT EDIT."
+	tests := []struct {
+		name string
+		hunk git.Hunk
+		want bool
+	}{
+		{name: "header", hunk: git.Hunk{NewStart: 1, NewLines: 2, Lines: []string{"+" + marker, "+package gen"}}, want: true},
+		{name: "header after license", hunk: git.Hunk{NewStart: 1, NewLines: 3, Lines: []string{" // Copyright 2026", "+" + marker, " package gen"}}, want: true},
+		{name: "marker after package clause", hunk: git.Hunk{NewStart: 1, NewLines: 2, Lines: []string{" package fixtures", "+" + marker}}},
+		{name: "marker mid-file", hunk: git.Hunk{NewStart: 30, NewLines: 1, Lines: []string{"+" + marker}}},
+		{name: "marker in a string", hunk: git.Hunk{NewStart: 12, NewLines: 1, Lines: []string{"+\tconst header = \"" + marker + "\""}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := git.FileDiff{OldPath: "f.go", NewPath: "f.go", Hunks: []git.Hunk{tt.hunk}}
+			if got := git.IsGenerated(file); got != tt.want {
+				t.Errorf("IsGenerated() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRenderRoundTrip tests that rendered diffs parse back to the same structure
+func TestRenderRoundTrip(t *testing.T) {
+	diff := `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -1,3 +1,4 @@
+ package main
++import "fmt"
+ func main() {
+-}
++	fmt.Println("hi") }
+`
+	files, err := git.Parse(diff)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	reparsed, err := git.Parse(git.Render(files))
+	if err != nil {
+		t.Fatalf("Parse(Render()) error = %v", err)
+	}
+
+	if len(reparsed) != 1 || len(reparsed[0].Hunks) != 1 {
+		t.Fatalf("Parse(Render()) = %+v, want 1 file with 1 hunk", reparsed)
+	}
+
+	if got, want := len(reparsed[0].Hunks[0].Lines), len(files[0].Hunks[0].Lines); got != want {
+		t.Errorf("Rendered hunk has %d lines, want %d", got, want)
+	}
+}
+
+// TestRenderAmbiguousPaths tests that paths containing " b/" survive a
+// render and parse, including when the header carries the only names
+func TestRenderAmbiguousPaths(t *testing.T) {
+	for _, file := range []git.FileDiff{
+		{OldPath: "x b/y", NewPath: "z", IsRename: true, Similarity: 100},
+		{OldPath: " b/", NewPath: "0"},
+		{OldPath: "a b/c.go", NewPath: "a b/c.go", OldMode: "100644", NewMode: "100755"},
+	} {
+		files, err := git.Parse(git.Render([]git.FileDiff{file}))
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		if len(files) != 1 || files[0].OldPath != file.OldPath || files[0].NewPath != file.NewPath {
+			t.Errorf("Parse(Render(%q -> %q)) = %+v", file.OldPath, file.NewPath, files)
+		}
+	}
+}
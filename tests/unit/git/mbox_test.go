@@ -0,0 +1,167 @@
+package git_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dshills/mcp-pr/internal/git"
+)
+
+const formatPatchMbox = `From 1111111111111111111111111111111111111111 Mon Sep 17 00:00:00 2001
+From: =?UTF-8?q?Ren=C3=A9e=20Dev?= <renee@example.com>
+Date: Tue, 6 Oct 2026 10:00:00 +0200
+Subject: [PATCH 1/2] Add greeting
+
+Say hello on start.
+---
+ main.go | 1 +
+ 1 file changed, 1 insertion(+)
+
+diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -1,2 +1,3 @@
+ package main
++// hello
+ func main() {}
+--
+2.45.0
+
+From 2222222222222222222222222222222222222222 Mon Sep 17 00:00:00 2001
+From: Sam Dev <sam@example.com>
+Date: Tue, 6 Oct 2026 11:00:00 +0200
+Subject: [PATCH 2/2] Remove notes
+
+---
+diff --git a/notes.txt b/notes.txt
+deleted file mode 100644
+--- a/notes.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-notes
+`
+
+// TestParseMbox tests splitting format-patch output into its messages
+func TestParseMbox(t *testing.T) {
+	if !git.IsMbox(formatPatchMbox) {
+		t.Fatal("IsMbox() = false for format-patch output")
+	}
+	if git.IsMbox("diff --git a/x b/x\n") {
+		t.Error("IsMbox() = true for a bare diff")
+	}
+
+	patches := git.ParseMbox(formatPatchMbox)
+	if len(patches) != 2 {
+		t.Fatalf("ParseMbox() returned %d patches, want 2", len(patches))
+	}
+
+	first := patches[0]
+	if first.Author != "Renée Dev <renee@example.com>" {
+		t.Errorf("Author = %q, want decoded header", first.Author)
+	}
+	if first.Subject != "Add greeting" {
+		t.Errorf("Subject = %q, want the [PATCH 1/2] tag stripped", first.Subject)
+	}
+	if first.Message != "Say hello on start." {
+		t.Errorf("Message = %q", first.Message)
+	}
+	if first.Date != "Tue, 6 Oct 2026 10:00:00 +0200" {
+		t.Errorf("Date = %q", first.Date)
+	}
+
+	var diffs []string
+	for _, p := range patches {
+		diffs = append(diffs, p.Diff)
+	}
+	files, err := git.Parse(strings.Join(diffs, "\n"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(files) != 2 || files[0].Path() != "main.go" || !files[1].IsDeleted {
+		t.Fatalf("Parse() of the patch diffs = %+v, want main.go and deleted notes.txt", files)
+	}
+	if patches[1].Message != "" {
+		t.Errorf("second Message = %q, want empty", patches[1].Message)
+	}
+}
+
+// clientMbox is an mbox saved by a mail client: envelope senders instead of
+// commits, and a body line starting with "From " escaped as ">From "
+const clientMbox = `From renee@example.com Tue Oct  6 10:00:00 2026
+From: Renee Dev <renee@example.com>
+Date: Tue, 6 Oct 2026 10:00:00 +0200
+Subject: [PATCH] Add greeting
+
+>From the design notes: say hello on start.
+From the command line it prints once.
+---
+diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -1,2 +1,3 @@
+ package main
++// hello
+ func main() {}
+
+From - Wed Oct  7 09:30:12 2026
+From: Sam Dev <sam@example.com>
+Subject: Re: [PATCH] Add greeting
+
+>>From a quoted reply.
+`
+
+// TestParseMboxMailClient tests splitting an mbox that is not format-patch output
+func TestParseMboxMailClient(t *testing.T) {
+	if !git.IsMbox(clientMbox) {
+		t.Fatal("IsMbox() = false for a mail client mbox")
+	}
+
+	patches := git.ParseMbox(clientMbox)
+	if len(patches) != 2 {
+		t.Fatalf("ParseMbox() returned %d patches, want 2", len(patches))
+	}
+
+	first := patches[0]
+	if first.Author != "Renee Dev <renee@example.com>" || first.Subject != "Add greeting" {
+		t.Errorf("first patch = %+v", first)
+	}
+	// An unescaped "From " line in the body does not start a new message
+	if first.Message != "From the design notes: say hello on start.\nFrom the command line it prints once." {
+		t.Errorf("Message = %q, want >From unescaped", first.Message)
+	}
+	if files, err := git.Parse(first.Diff); err != nil || len(files) != 1 || files[0].Path() != "main.go" {
+		t.Errorf("Parse() of the first diff = %+v, %v", files, err)
+	}
+
+	if second := patches[1]; second.Author != "Sam Dev <sam@example.com>" || second.Message != ">From a quoted reply." {
+		t.Errorf("second patch = %+v, want one > removed", second)
+	}
+}
+
+// TestNewContent tests rebuilding a file from the hunks of its diff
+func TestNewContent(t *testing.T) {
+	diff := `diff --git a/f.go b/f.go
+--- a/f.go
++++ b/f.go
+@@ -1,3 +1,3 @@
+ package f
+-var x = 1
++var x = 2
+
+@@ -9,2 +9,3 @@ func g() {
+ 	a()
++	b()
+ }
+`
+	files, err := git.Parse(diff)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	lines := strings.Split(onlyFile(t, files).NewContent(), "\n")
+	if lines[1] != "var x = 2" || lines[9] != "\tb()" || lines[10] != "}" {
+		t.Errorf("NewContent() lines = %q, want changes at their new line numbers", lines)
+	}
+	if lines[4] != "" {
+		t.Errorf("NewContent() line 5 = %q, want lines outside hunks empty", lines[4])
+	}
+}
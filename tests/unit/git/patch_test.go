@@ -0,0 +1,57 @@
+package git_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dshills/mcp-pr/internal/git"
+)
+
+// TestReplacementPatch tests the unified diff built for a line replacement
+func TestReplacementPatch(t *testing.T) {
+	content := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\n"
+
+	tests := []struct {
+		name        string
+		content     string
+		start, end  int
+		replacement string
+		want        string
+	}{
+		{
+			name: "middle", content: content, start: 5, end: 5, replacement: "FIVE\n",
+			want: "diff --git a/f.go b/f.go\n--- a/f.go\n+++ b/f.go\n@@ -2,7 +2,7 @@\n two\n three\n four\n-five\n+FIVE\n six\n seven\n eight\n",
+		},
+		{
+			name: "first lines replaced by more lines", content: content, start: 1, end: 2, replacement: "1\n2\n3",
+			want: "diff --git a/f.go b/f.go\n--- a/f.go\n+++ b/f.go\n@@ -1,5 +1,6 @@\n-one\n-two\n+1\n+2\n+3\n three\n four\n five\n",
+		},
+		{
+			name: "deletion", content: "a\nb\n", start: 1, end: 2, replacement: "",
+			want: "diff --git a/f.go b/f.go\n--- a/f.go\n+++ b/f.go\n@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+		{
+			name: "last line without newline", content: "a\nb", start: 2, end: 2, replacement: "c",
+			want: "diff --git a/f.go b/f.go\n--- a/f.go\n+++ b/f.go\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := git.ReplacementPatch("f.go", tt.content, tt.start, tt.end, tt.replacement)
+			if err != nil {
+				t.Fatalf("ReplacementPatch() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ReplacementPatch() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := git.ReplacementPatch("f.go", content, 2, 2, "two"); !errors.Is(err, git.ErrEmptyPatch) {
+		t.Errorf("unchanged replacement error = %v, want ErrEmptyPatch", err)
+	}
+	if _, err := git.ReplacementPatch("f.go", content, 8, 9, "x"); err == nil {
+		t.Error("out-of-range replacement expected error")
+	}
+}
@@ -0,0 +1,278 @@
+package gocontext_test
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/dshills/mcp-pr/internal/git"
+	"github.com/dshills/mcp-pr/internal/gocontext"
+)
+
+// memoryTree serves files from a map keyed by slash-separated path
+type memoryTree map[string]string
+
+func (t memoryTree) ReadFile(_ context.Context, filePath string) (string, error) {
+	content, ok := t[filePath]
+	if !ok {
+		return "", fmt.Errorf("%s not found", filePath)
+	}
+	return content, nil
+}
+
+func (t memoryTree) ListDir(_ context.Context, dir string) ([]string, error) {
+	var names []string
+	for filePath := range t {
+		if path.Dir(filePath) == dir {
+			names = append(names, path.Base(filePath))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+const storeSource = `package store
+
+import (
+	"fmt"
+	"github.com/example/missing"
+)
+
+// Store persists records
+type Store interface {
+	Save(r Record) error
+}
+
+// Record is a persisted item
+type Record struct {
+	ID   string
+	Meta missing.Meta
+}
+
+// MemoryStore keeps records in memory
+type MemoryStore struct {
+	records map[string]Record
+}
+
+// Save stores a record, replacing any existing one
+func (m *MemoryStore) Save(r Record) error {
+	m.records[r.ID] = r
+	return nil
+}
+
+// validate checks a record before saving
+func validate(r Record) error {
+	if r.ID == "" {
+		return fmt.Errorf("missing id")
+	}
+	return nil
+}
+`
+
+const serviceSource = `package store
+
+func Put(m *MemoryStore, id string) error {
+	r := Record{ID: id}
+	if err := validate(r); err != nil {
+		return err
+	}
+	return m.Save(r)
+}
+`
+
+// TestCollect tests that functions and types referenced from added lines are described
+func TestCollect(t *testing.T) {
+	tree := memoryTree{
+		"store/store.go":   storeSource,
+		"store/service.go": serviceSource,
+		"store/README.md":  "not go",
+	}
+
+	diff := `diff --git a/store/service.go b/store/service.go
+--- a/store/service.go
++++ b/store/service.go
+@@ -1,6 +1,9 @@
+ package store
+
+ func Put(m *MemoryStore, id string) error {
+ 	r := Record{ID: id}
++	if err := validate(r); err != nil {
++		return err
++	}
+ 	return m.Save(r)
+ }
+`
+	files, err := git.Parse(diff)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	result, err := gocontext.Collect(context.Background(), files, tree, gocontext.Options{TokenBudget: 4000})
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	if len(result.Functions) != 1 {
+		t.Fatalf("got %d functions, want 1: %+v", len(result.Functions), result.Functions)
+	}
+	fn := result.Functions[0]
+	if fn.Signature != "func validate(r Record) error" || fn.Position != "store/store.go:31" {
+		t.Errorf("function = %+v", fn)
+	}
+	if fn.Doc != "validate checks a record before saving" {
+		t.Errorf("Doc = %q", fn.Doc)
+	}
+
+	// Only Record is referenced on added lines; unchanged lines are not analyzed
+	if len(result.Types) != 1 || result.Types[0].Name != "Record" {
+		t.Fatalf("types = %+v", result.Types)
+	}
+	if !strings.Contains(result.Types[0].Definition, "Meta missing.Meta") {
+		t.Errorf("Definition = %q", result.Types[0].Definition)
+	}
+
+	formatted := gocontext.Format(result)
+	if !strings.Contains(formatted, "func validate(r Record) error  // store/store.go:31") {
+		t.Errorf("Format() = %q", formatted)
+	}
+}
+
+// TestCollectImplements tests that implemented interfaces are reported for used types
+func TestCollectImplements(t *testing.T) {
+	changed := strings.Replace(storeSource, "\tm.records[r.ID] = r\n", "\tm.records[r.ID] = r\n\t_ = m.records\n", 1)
+	tree := memoryTree{"store/store.go": changed}
+
+	diff := `diff --git a/store/store.go b/store/store.go
+--- a/store/store.go
++++ b/store/store.go
+@@ -26,2 +26,3 @@ func (m *MemoryStore) Save(r Record) error {
+ 	m.records[r.ID] = r
++	_ = m.records
+ 	return nil
+`
+	files, err := git.Parse(diff)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	result, err := gocontext.Collect(context.Background(), files, tree, gocontext.Options{TokenBudget: 4000})
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	var memoryStore *gocontext.Type
+	for i := range result.Types {
+		if result.Types[i].Name == "MemoryStore" {
+			memoryStore = &result.Types[i]
+		}
+	}
+	if memoryStore == nil {
+		t.Fatalf("MemoryStore not reported: %+v", result.Types)
+	}
+	if len(memoryStore.Implements) != 1 || memoryStore.Implements[0] != "Store (pointer receiver)" {
+		t.Errorf("Implements = %v", memoryStore.Implements)
+	}
+}
+
+// TestCollectCrossPackage tests that calls into other packages of the module are described
+func TestCollectCrossPackage(t *testing.T) {
+	handler := `package api
+
+import "github.com/example/app/store"
+
+func Create(m *store.MemoryStore, id string) error {
+	return store.Put(m, id)
+}
+`
+	tree := memoryTree{
+		"go.mod":           "module github.com/example/app\n\ngo 1.24\n",
+		"store/store.go":   storeSource,
+		"store/service.go": strings.Replace(serviceSource, "func Put", "// Put validates and saves a new record\nfunc Put", 1),
+		"api/handler.go":   handler,
+	}
+
+	diff := `diff --git a/api/handler.go b/api/handler.go
+--- a/api/handler.go
++++ b/api/handler.go
+@@ -5,3 +5,3 @@ import "github.com/example/app/store"
+ func Create(m *store.MemoryStore, id string) error {
+-	return nil
++	return store.Put(m, id)
+ }
+`
+	files, err := git.Parse(diff)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	result, err := gocontext.Collect(context.Background(), files, tree, gocontext.Options{TokenBudget: 4000})
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	if len(result.Functions) != 1 {
+		t.Fatalf("got %d functions, want 1: %+v", len(result.Functions), result.Functions)
+	}
+	fn := result.Functions[0]
+	if fn.Signature != "func store.Put(m *store.MemoryStore, id string) error" || fn.Position != "store/service.go:4" {
+		t.Errorf("function = %+v", fn)
+	}
+	if fn.Doc != "Put validates and saves a new record" {
+		t.Errorf("Doc = %q", fn.Doc)
+	}
+}
+
+// TestCollectBudget tests that entries beyond the token budget are dropped
+func TestCollectBudget(t *testing.T) {
+	tree := memoryTree{
+		"store/store.go":   storeSource,
+		"store/service.go": serviceSource,
+	}
+
+	files := []git.FileDiff{{
+		OldPath: "store/service.go",
+		NewPath: "store/service.go",
+		Hunks: []git.Hunk{{
+			OldStart: 1, OldLines: 0, NewStart: 3, NewLines: 7,
+			Lines: []string{
+				"+func Put(m *MemoryStore, id string) error {",
+				"+	r := Record{ID: id}",
+				"+	if err := validate(r); err != nil {",
+				"+		return err",
+				"+	}",
+				"+	return m.Save(r)",
+				"+}",
+			},
+		}},
+	}}
+
+	result, err := gocontext.Collect(context.Background(), files, tree, gocontext.Options{TokenBudget: 30})
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if !result.Truncated {
+		t.Errorf("expected truncation with a tiny budget: %+v", result)
+	}
+	if len(result.Functions) == 0 {
+		t.Errorf("expected signatures to be kept before types: %+v", result)
+	}
+}
+
+// TestCollectSkipsNonGo tests that non-Go and deleted files produce no context
+func TestCollectSkipsNonGo(t *testing.T) {
+	files := []git.FileDiff{
+		{NewPath: "README.md", Hunks: []git.Hunk{{NewStart: 1, Lines: []string{"+text"}}}},
+		{OldPath: "gone.go", IsDeleted: true, Hunks: []git.Hunk{{OldStart: 1, Lines: []string{"-package gone"}}}},
+	}
+
+	result, err := gocontext.Collect(context.Background(), files, memoryTree{}, gocontext.Options{TokenBudget: 4000})
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if !result.IsEmpty() {
+		t.Errorf("expected empty result, got %+v", result)
+	}
+}
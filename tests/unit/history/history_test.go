@@ -0,0 +1,377 @@
+package history_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dshills/mcp-pr/internal/history"
+	"github.com/dshills/mcp-pr/internal/review"
+)
+
+// newStore opens a store in a temporary directory
+func newStore(t *testing.T, opts ...history.Option) *history.Store {
+	t.Helper()
+	store, err := history.Open(filepath.Join(t.TempDir(), "history"), opts...)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	return store
+}
+
+// TestListFilters tests filtering by repository, branch and time, newest first
+func TestListFilters(t *testing.T) {
+	store := newStore(t)
+	repoA, repoB := t.TempDir(), t.TempDir()
+	base := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+
+	records := []history.Record{
+		{ID: "a1", Time: base, Repository: repoA, Branch: "main", Verdict: review.VerdictPass},
+		{ID: "a2", Time: base.Add(24 * time.Hour), Repository: repoA, Branch: "feature", Verdict: review.VerdictFail},
+		{ID: "a3", Time: base.Add(48 * time.Hour), Repository: repoA, Branch: "main", Verdict: review.VerdictPass},
+		{ID: "b1", Time: base.Add(72 * time.Hour), Repository: repoB, Branch: "main", Verdict: review.VerdictPass},
+	}
+	for _, rec := range records {
+		if err := store.Append(rec); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query history.Query
+		want  []string
+	}{
+		{"all", history.Query{}, []string{"b1", "a3", "a2", "a1"}},
+		{"repository", history.Query{Repository: repoA}, []string{"a3", "a2", "a1"}},
+		{"branch", history.Query{Repository: repoA, Branch: "main"}, []string{"a3", "a1"}},
+		{"since across months", history.Query{Since: base.Add(time.Hour)}, []string{"b1", "a3", "a2"}},
+		{"until", history.Query{Until: base.Add(48 * time.Hour)}, []string{"a2", "a1"}},
+		{"limit", history.Query{Limit: 2}, []string{"b1", "a3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := store.List(tt.query)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("List() returned %d records, want %d", len(got), len(tt.want))
+			}
+			for i, rec := range got {
+				if rec.ID != tt.want[i] {
+					t.Errorf("record %d = %s, want %s", i, rec.ID, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// TestRecordAndGet tests storing an engine response and reading it back
+func TestRecordAndGet(t *testing.T) {
+	store := newStore(t)
+
+	req := review.Request{SourceType: "arbitrary", Code: "package main", Language: "go", Provider: "anthropic"}
+	resp := &review.Response{
+		Provider: "anthropic",
+		Verdict:  review.VerdictFail,
+		Findings: []review.Finding{{Severity: "high", Category: "bug", Description: "nil dereference"}},
+		Metadata: &review.Metadata{Model: "test-model", InputTokens: 120, OutputTokens: 40},
+	}
+
+	id, err := store.Record(context.Background(), req, resp)
+	if err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	rec, err := store.Get(id)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if rec.Model != "test-model" || rec.InputTokens != 120 || rec.OutputTokens != 40 || rec.Verdict != review.VerdictFail {
+		t.Errorf("record = %+v", rec)
+	}
+	if rec.Request.CodeBytes != len(req.Code) || rec.Request.Language != "go" {
+		t.Errorf("request summary = %+v", rec.Request)
+	}
+	if rec.Response == nil || len(rec.Response.Findings) != 1 {
+		t.Errorf("response = %+v, want one finding", rec.Response)
+	}
+	if rec.Summary().Response != nil {
+		t.Error("Summary() kept the response")
+	}
+
+	if _, err := store.Get("missing"); !errors.Is(err, history.ErrNotFound) {
+		t.Errorf("Get(missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+// TestListSkipsCorruptLines tests that a damaged line does not hide other records
+func TestListSkipsCorruptLines(t *testing.T) {
+	store := newStore(t)
+	now := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+
+	if err := store.Append(history.Record{ID: "first", Time: now}); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(store.Dir(), "2026-05.jsonl")
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"id":"truncated","ti` + "\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Append(history.Record{ID: "second", Time: now.Add(time.Minute)}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.List(history.Query{})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(got) != 2 || got[0].ID != "second" || got[1].ID != "first" {
+		t.Errorf("List() = %+v, want second and first", got)
+	}
+}
+
+// TestParseTime tests the accepted time formats
+func TestParseTime(t *testing.T) {
+	now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]time.Time{
+		"2026-06-01T08:00:00Z": time.Date(2026, 6, 1, 8, 0, 0, 0, time.UTC),
+		"2026-06-01":           time.Date(2026, 6, 1, 0, 0, 0, 0, time.Local),
+		"7d":                   now.AddDate(0, 0, -7),
+		"36h":                  now.Add(-36 * time.Hour),
+	}
+	for value, want := range tests {
+		got, err := history.ParseTime(value, now)
+		if err != nil {
+			t.Errorf("ParseTime(%q) error = %v", value, err)
+			continue
+		}
+		if !got.Equal(want) {
+			t.Errorf("ParseTime(%q) = %v, want %v", value, got, want)
+		}
+	}
+
+	for _, value := range []string{"yesterday", "-3d", "2026-13-01"} {
+		if _, err := history.ParseTime(value, now); err == nil {
+			t.Errorf("ParseTime(%q) expected error", value)
+		}
+	}
+}
+
+// TestLabel tests lifecycle statuses relative to earlier reviews of a branch
+func TestLabel(t *testing.T) {
+	finding := func(fingerprint string) review.Finding {
+		return review.Finding{Severity: "high", Category: "bug", Fingerprint: fingerprint}
+	}
+	prior := []history.Record{
+		{ID: "previous", Response: &review.Response{Findings: []review.Finding{finding("kept"), finding("gone"), finding("gone")}}},
+		{ID: "older", Response: &review.Response{Findings: []review.Finding{finding("back")}}},
+	}
+	resp := &review.Response{
+		Findings: []review.Finding{finding("kept"), finding("back"), finding("fresh")},
+		Metadata: &review.Metadata{},
+	}
+
+	history.Label(resp, nil, prior)
+
+	want := []string{review.StatusStillPresent, review.StatusRegressed, review.StatusNew}
+	for i, f := range resp.Findings {
+		if f.Status != want[i] {
+			t.Errorf("Findings[%d].Status = %q, want %q", i, f.Status, want[i])
+		}
+	}
+	if len(resp.Fixed) != 1 || resp.Fixed[0].Fingerprint != "gone" || resp.Fixed[0].Status != review.StatusFixed {
+		t.Errorf("Fixed = %+v, want the gone finding once", resp.Fixed)
+	}
+	if resp.Metadata.PreviousReviewID != "previous" {
+		t.Errorf("PreviousReviewID = %q", resp.Metadata.PreviousReviewID)
+	}
+
+	// Without earlier reviews everything is new
+	resp = &review.Response{Findings: []review.Finding{finding("kept")}, Metadata: &review.Metadata{}}
+	history.Label(resp, nil, nil)
+	if resp.Findings[0].Status != review.StatusNew || resp.Fixed != nil || resp.Metadata.PreviousReviewID != "" {
+		t.Errorf("first review = %+v", resp)
+	}
+
+	// Each file is compared with the latest review that covered it, and
+	// files outside this review are never reported fixed
+	inFile := func(path, fingerprint string) review.Finding {
+		f := finding(fingerprint)
+		f.FilePath = path
+		return f
+	}
+	prior = []history.Record{
+		{ID: "b", Files: []string{"b.go"}, Response: &review.Response{Findings: []review.Finding{inFile("b.go", "b1")}}},
+		{ID: "a", Files: []string{"a.go"}, Response: &review.Response{Findings: []review.Finding{inFile("a.go", "a1"), inFile("a.go", "a2")}}},
+	}
+	resp = &review.Response{Findings: []review.Finding{inFile("a.go", "a1")}, Metadata: &review.Metadata{}}
+	history.Label(resp, []string{"a.go"}, prior)
+	if resp.Findings[0].Status != review.StatusStillPresent {
+		t.Errorf("a1 status = %q, want still present", resp.Findings[0].Status)
+	}
+	if len(resp.Fixed) != 1 || resp.Fixed[0].Fingerprint != "a2" {
+		t.Errorf("Fixed = %+v, want only a2", resp.Fixed)
+	}
+	if resp.Metadata.PreviousReviewID != "a" {
+		t.Errorf("PreviousReviewID = %q, want the review of a.go", resp.Metadata.PreviousReviewID)
+	}
+
+	// A blocked review never reports fixes
+	resp = &review.Response{Metadata: &review.Metadata{Blocked: true}}
+	history.Label(resp, []string{"a.go"}, prior)
+	if resp.Fixed != nil {
+		t.Errorf("blocked review Fixed = %+v", resp.Fixed)
+	}
+}
+
+// TestTriage tests recording triage states, applying them to later reviews and aggregating them
+func TestTriage(t *testing.T) {
+	store := newStore(t)
+	ctx := context.Background()
+	req := review.Request{SourceType: "arbitrary", Code: "package main", Language: "go"}
+
+	newResponse := func() *review.Response {
+		return &review.Response{
+			Provider: "anthropic",
+			Findings: []review.Finding{
+				{Severity: "high", Category: "bug", Fingerprint: "noise"},
+				{Severity: "high", Category: "bug", Fingerprint: "real"},
+				{Severity: "critical", Category: "security", Fingerprint: "secret", Source: review.SecretSource},
+			},
+			Metadata: &review.Metadata{Model: "test-model"},
+		}
+	}
+	id, err := store.Record(ctx, req, newResponse())
+	if err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	if _, err := store.SetTriage(id, "noise", "ignored", ""); !errors.Is(err, history.ErrInvalidTriageState) {
+		t.Errorf("SetTriage(bad state) error = %v", err)
+	}
+	if _, err := store.SetTriage(id, "unknown", history.TriageAccepted, ""); !errors.Is(err, history.ErrFindingNotFound) {
+		t.Errorf("SetTriage(unknown fingerprint) error = %v", err)
+	}
+	for fingerprint, state := range map[string]string{
+		"noise":  history.TriageFalsePositive,
+		"real":   history.TriageAccepted,
+		"secret": history.TriageWontFix,
+	} {
+		if _, err := store.SetTriage(id, fingerprint, state, "checked by "+state); err != nil {
+			t.Fatalf("SetTriage(%s) error = %v", fingerprint, err)
+		}
+	}
+
+	resp := newResponse()
+	if err := store.Annotate(ctx, req, resp); err != nil {
+		t.Fatalf("Annotate() error = %v", err)
+	}
+	want := []struct{ triage, suppressed string }{
+		{history.TriageFalsePositive, review.SuppressedByTriage},
+		{history.TriageAccepted, ""},
+		{history.TriageWontFix, ""}, // Secrets are never suppressed
+	}
+	for i, f := range resp.Findings {
+		if f.Triage != want[i].triage || f.Suppressed != want[i].suppressed || f.TriageNote == "" {
+			t.Errorf("Findings[%d] = %+v, want triage %q suppressed %q", i, f, want[i].triage, want[i].suppressed)
+		}
+	}
+
+	stats, err := store.Stats(history.Query{})
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	if len(stats) != 1 {
+		t.Fatalf("Stats() = %+v, want one group (secret findings are not attributed to the model)", stats)
+	}
+	got := stats[0]
+	if got.Provider != "anthropic" || got.Model != "test-model" || got.Category != "bug" || got.Findings != 2 || got.Triaged != 2 || got.FalsePositiveRate != 0.5 {
+		t.Errorf("Stats()[0] = %+v", got)
+	}
+}
+
+// TestThread tests that recording a review starts a conversation that can be saved
+func TestThread(t *testing.T) {
+	store := newStore(t, history.WithCode())
+
+	req := review.Request{SourceType: "arbitrary", Code: "package main", Language: "go"}
+	resp := &review.Response{
+		Provider: "anthropic",
+		Findings: []review.Finding{{Severity: "high", Category: "bug", Description: "nil dereference"}},
+		Metadata: &review.Metadata{Model: "test-model"},
+	}
+	id, err := store.Record(context.Background(), req, resp)
+	if err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	thread, err := store.Thread(id)
+	if err != nil {
+		t.Fatalf("Thread() error = %v", err)
+	}
+	if thread.ReviewID != id || thread.Provider != "anthropic" || thread.Model != "test-model" || len(thread.Messages) != 0 {
+		t.Errorf("thread = %+v", thread)
+	}
+	if !strings.Contains(thread.Context, "package main") || !strings.Contains(thread.Context, "1. [high] bug: nil dereference") {
+		t.Errorf("context = %q", thread.Context)
+	}
+
+	thread.Messages = append(thread.Messages, review.Message{Role: review.RoleUser, Content: "why?"})
+	if err := store.SaveThread(thread); err != nil {
+		t.Fatalf("SaveThread() error = %v", err)
+	}
+	if thread, err = store.Thread(id); err != nil || len(thread.Messages) != 1 {
+		t.Errorf("Thread() after save = %+v, %v", thread, err)
+	}
+
+	if _, err := store.Thread("missing"); !errors.Is(err, history.ErrNoThread) {
+		t.Errorf("Thread(missing) error = %v, want ErrNoThread", err)
+	}
+	if _, err := store.Thread("../triage"); !errors.Is(err, history.ErrNotFound) {
+		t.Errorf("Thread(../triage) error = %v, want ErrNotFound", err)
+	}
+}
+
+// TestRecordWithoutCode tests that reviewed code is not stored unless the store keeps it
+func TestRecordWithoutCode(t *testing.T) {
+	store := newStore(t)
+
+	req := review.Request{SourceType: "arbitrary", Code: "password := \"hunter2\"", Language: "go"}
+	resp := &review.Response{Provider: "anthropic", Summary: "Looks fine"}
+	id, err := store.Record(context.Background(), req, resp)
+	if err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	if _, err := store.Thread(id); !errors.Is(err, history.ErrNoThread) {
+		t.Errorf("Thread() error = %v, want ErrNoThread", err)
+	}
+	err = filepath.WalkDir(store.Dir(), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err == nil && strings.Contains(string(data), "hunter2") {
+			t.Errorf("%s contains the reviewed code", path)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WalkDir() error = %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package lang_test
+
+import (
+	"testing"
+
+	"github.com/dshills/mcp-pr/internal/lang"
+)
+
+// TestDetect tests language detection from file names, shebangs and content
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    string
+	}{
+		{name: "go extension", path: "internal/review/engine.go", want: "go"},
+		{name: "typescript extension", path: "web/src/App.TSX", want: "tsx"},
+		{name: "dockerfile name", path: "build/Dockerfile", want: "dockerfile"},
+		{name: "makefile name", path: "Makefile", want: "makefile"},
+		{name: "c header", path: "include/list.h", content: "struct list;\nint list_len(struct list *l);\n", want: "c"},
+		{name: "c++ header", path: "include/list.h", content: "#include <vector>\nnamespace list {\nstd::vector<int> items();\n}\n", want: "cpp"},
+		{name: "extension wins over content", path: "query.sql", content: "package main\n", want: "sql"},
+		{name: "env shebang", path: "scripts/deploy", content: "#!/usr/bin/env python3\nprint('hi')\n", want: "python"},
+		{name: "shell shebang", path: "bin/run", content: "#!/bin/bash -e\necho hi\n", want: "shell"},
+		{name: "env shebang with flags", content: "#!/usr/bin/env -S node --no-warnings\nrun()\n", want: "javascript"},
+		{
+			name:    "go snippet",
+			content: "func (s *Server) Start() error {\n\tln, err := net.Listen(\"tcp\", s.addr)\n\tif err != nil {\n\t\treturn err\n\t}\n\treturn nil\n}\n",
+			want:    "go",
+		},
+		{
+			name:    "python snippet",
+			content: "import os\n\nclass Cache:\n    def get(self, key):\n        return self.items.get(key)\n",
+			want:    "python",
+		},
+		{
+			name:    "typescript snippet",
+			content: "interface User {\n  id: number;\n}\n\nexport function name(user: User): string {\n  return `${user.id}`;\n}\n",
+			want:    "typescript",
+		},
+		{
+			name:    "javascript snippet",
+			content: "const fs = require('fs');\n\nfunction load(path) {\n  console.log(path);\n}\n",
+			want:    "javascript",
+		},
+		{
+			name:    "rust snippet",
+			content: "use std::collections::HashMap;\n\nfn main() {\n    let mut counts = HashMap::new();\n    println!(\"{:?}\", counts);\n}\n",
+			want:    "rust",
+		},
+		{
+			name:    "java snippet",
+			content: "public class Main {\n    @Override\n    public String toString() {\n        return \"main\";\n    }\n}\n",
+			want:    "java",
+		},
+		{
+			name:    "sql snippet",
+			content: "SELECT id, name\nFROM users\nWHERE email = $1\nORDER BY id;\n",
+			want:    "sql",
+		},
+		{name: "json snippet", content: `{"name": "mcp-pr", "private": true}`, want: "json"},
+		{name: "php snippet", content: "<?php\necho $name;\n", want: "php"},
+		{name: "prose", content: "Please review this change carefully.", want: ""},
+		{name: "empty", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lang.Detect(tt.path, tt.content); got != tt.want {
+				t.Errorf("Detect(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
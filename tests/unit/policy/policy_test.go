@@ -0,0 +1,99 @@
+package policy_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dshills/mcp-pr/internal/policy"
+)
+
+// TestParse tests decoding a complete policy file
+func TestParse(t *testing.T) {
+	p, err := policy.Parse([]byte(`
+provider: openai
+model: gpt-5
+review_depth: thorough
+focus_areas: [security, bug]
+include_paths: ["internal/**"]
+exclude_paths: ["internal/legacy/"]
+use_default_excludes: false
+instructions: Prefer table-driven tests.
+min_severity: medium
+fail_on: high
+max_diff_size: 50000
+`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if p.Provider != "openai" || p.Model != "gpt-5" || p.ReviewDepth != "thorough" || p.MinSeverity != "medium" || p.FailOn != "high" || p.MaxDiffSize != 50000 {
+		t.Errorf("policy = %+v", p)
+	}
+	if len(p.FocusAreas) != 2 || len(p.IncludePaths) != 1 || len(p.ExcludePaths) != 1 {
+		t.Errorf("lists = %+v", p)
+	}
+	if p.UseDefaultExcludes == nil || *p.UseDefaultExcludes {
+		t.Errorf("UseDefaultExcludes = %v, want false", p.UseDefaultExcludes)
+	}
+}
+
+// TestParseInvalid tests that unknown keys and invalid values are rejected
+func TestParseInvalid(t *testing.T) {
+	tests := map[string]string{
+		"unknown key":            "provider: openai\nreview_dept: quick\n",
+		"unknown provider":       "provider: llama\n",
+		"model without provider": "model: gpt-5\n",
+		"bad depth":              "review_depth: deep\n",
+		"bad focus area":         "focus_areas: [nits]\n",
+		"bad severity":           "min_severity: urgent\n",
+		"bad fail_on":            "fail_on: urgent\n",
+		"negative diff size":     "max_diff_size: -1\n",
+		"instructions outside":   "instructions_file: ../STYLE.md\n",
+		"absolute instructions":  "instructions_file: /etc/STYLE.md\n",
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := policy.Parse([]byte(data)); err == nil {
+				t.Error("Parse() expected error")
+			}
+		})
+	}
+}
+
+// TestLoad tests loading from a repository root, with and without a policy file
+func TestLoad(t *testing.T) {
+	root := t.TempDir()
+
+	p, path, err := policy.Load(root)
+	if err != nil || p != nil || path != "" {
+		t.Errorf("Load() without file = %v, %q, %v, want nil, \"\", nil", p, path, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, policy.FileName), []byte("review_depth: thorough\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	p, path, err = policy.Load(root)
+	if err != nil || p == nil || p.ReviewDepth != "thorough" || path != filepath.Join(root, policy.FileName) {
+		t.Errorf("Load() = %+v, %q, %v", p, path, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, policy.FileName), []byte("review_depth: [\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := policy.Load(root); err == nil {
+		t.Error("Load() expected error for malformed file")
+	}
+}
+
+// TestSeverityRank tests severity ordering
+func TestSeverityRank(t *testing.T) {
+	if !(policy.SeverityRank("critical") < policy.SeverityRank("high") && policy.SeverityRank("low") < policy.SeverityRank("info")) {
+		t.Error("severities out of order")
+	}
+	if policy.SeverityRank("unknown") <= policy.SeverityRank("info") {
+		t.Error("unknown severity should rank last")
+	}
+}
@@ -0,0 +1,136 @@
+package rules_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/dshills/mcp-pr/internal/git"
+	"github.com/dshills/mcp-pr/internal/rules"
+)
+
+// handlerSource is the post-change content of internal/mcp/handler.go
+const handlerSource = `package mcp
+
+import (
+	"fmt"
+	clock "time"
+)
+
+func handleThing() {
+	fmt.Println("debug")
+	clock.Sleep(clock.Second)
+	// TODO fix this
+	// TODO(PROJ-42) tracked
+}
+
+func helper() {
+	clock.Sleep(clock.Second)
+	println("builtin")
+}
+`
+
+// handlerDiff adds every line of handlerSource
+func handlerDiff(t *testing.T) []git.FileDiff {
+	t.Helper()
+
+	lines := strings.Split(strings.TrimSuffix(handlerSource, "\n"), "\n")
+	var body strings.Builder
+	for _, line := range lines {
+		body.WriteString("+" + line + "\n")
+	}
+
+	diff := fmt.Sprintf("diff --git a/internal/mcp/handler.go b/internal/mcp/handler.go\nnew file mode 100644\n--- /dev/null\n+++ b/internal/mcp/handler.go\n@@ -0,0 +1,%d @@\n%s", len(lines), body.String())
+	files, err := git.Parse(diff)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return files
+}
+
+// source serves handlerSource
+func source(_ context.Context, path string) (string, error) {
+	if path != "internal/mcp/handler.go" {
+		return "", fmt.Errorf("%s not found", path)
+	}
+	return handlerSource, nil
+}
+
+// TestEvaluate tests pattern and call rules against added lines
+func TestEvaluate(t *testing.T) {
+	set, err := rules.Load("../../../examples/rules.yaml")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	matches := set.Evaluate(context.Background(), handlerDiff(t), source)
+
+	got := map[string][]int{}
+	for _, m := range matches {
+		got[m.RuleID] = append(got[m.RuleID], m.Line)
+		if m.FilePath != "internal/mcp/handler.go" {
+			t.Errorf("FilePath = %q", m.FilePath)
+		}
+	}
+
+	want := map[string][]int{
+		"no-println-in-internal": {9},
+		"todo-needs-ticket":      {11},
+		"no-sleep-in-handlers":   {10}, // Aliased import; helper() is not a handler
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("matches = %v, want %v", got, want)
+	}
+}
+
+// TestEvaluateBuiltinAndPaths tests builtin calls and path exclusion
+func TestEvaluateBuiltinAndPaths(t *testing.T) {
+	set, err := rules.Parse([]byte(`
+rules:
+  - id: no-println-builtin
+    call: println
+    category: style
+    severity: low
+    message: builtin println
+  - id: excluded
+    pattern: Sleep
+    exclude_paths: ["internal/mcp/"]
+    category: style
+    severity: low
+    message: never matches here
+`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	matches := set.Evaluate(context.Background(), handlerDiff(t), source)
+	if len(matches) != 1 || matches[0].RuleID != "no-println-builtin" || matches[0].Line != 17 {
+		t.Errorf("matches = %+v", matches)
+	}
+	if matches[0].Snippet != `println("builtin")` {
+		t.Errorf("Snippet = %q", matches[0].Snippet)
+	}
+}
+
+// TestParseInvalid tests that malformed rules are rejected
+func TestParseInvalid(t *testing.T) {
+	base := "  - id: r\n    category: style\n    severity: low\n    message: m\n"
+	tests := map[string]string{
+		"unknown key":       "rules:\n" + base + "    patern: x\n",
+		"no matcher":        "rules:\n" + base,
+		"both matchers":     "rules:\n" + base + "    pattern: x\n    call: fmt.Println\n",
+		"bad regex":         "rules:\n" + base + "    pattern: '('\n",
+		"bad category":      "rules:\n  - id: r\n    category: nits\n    severity: low\n    message: m\n    pattern: x\n",
+		"function on regex": "rules:\n" + base + "    pattern: x\n    function: handle\n",
+		"duplicate id":      "rules:\n" + base + "    pattern: x\n" + base + "    pattern: y\n",
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := rules.Parse([]byte(data)); err == nil {
+				t.Error("Parse() expected error")
+			}
+		})
+	}
+}